@@ -566,7 +566,7 @@ var specs = []stmtSpec{
 	{
 		name:    "alter_type",
 		stmt:    "alter_type_stmt",
-		inline:  []string{"opt_add_val_placement"},
+		inline:  []string{"alter_type_add_value_cmd", "opt_add_val_placement"},
 		replace: map[string]string{"'SCONST'": "value"},
 		unlink:  []string{"value"},
 	},
@@ -682,7 +682,7 @@ func newCDCTester(ctx context.Context, t test.Test, c cluster.Cluster) cdcTester
 	}
 	tester.logger = changefeedLogger
 
-	startOpts, settings := makeCDCBenchOptions(c)
+	startOpts, settings := makeCDCBenchOptions(c, cdcBenchClusterOpts{})
 
 	// With a target_duration of 10s, we won't see slow span logs from changefeeds untils we are > 100s
 	// behind, which is well above the 60s targetSteadyLatency we have in some tests.
@@ -2806,6 +2806,13 @@ type changefeedInfo struct {
 	statementTime time.Time
 	highwaterTime time.Time
 	finishedTime  time.Time
+	// fractionCompleted is the fraction of the job's work that is completed,
+	// for jobs that report their progress as a fraction rather than a
+	// high-water.
+	fractionCompleted float32
+	// runningStatus is the human-readable status of the running job, e.g. the
+	// phase of the changefeed.
+	runningStatus string
 }
 
 func (c *changefeedInfo) GetHighWater() time.Time    { return c.highwaterTime }
@@ -2822,6 +2829,14 @@ func getChangefeedInfo(db *gosql.DB, jobID int) (*changefeedInfo, error) {
 	if err := db.QueryRow(jobutils.InternalSystemJobsBaseQuery, jobID).Scan(&status, &payloadBytes, &progressBytes); err != nil {
 		return nil, err
 	}
+	return parseChangefeedInfo(status, payloadBytes, progressBytes)
+}
+
+// parseChangefeedInfo decodes the status, payload and progress of a changefeed
+// job row.
+func parseChangefeedInfo(
+	status string, payloadBytes, progressBytes []byte,
+) (*changefeedInfo, error) {
 	var payload jobspb.Payload
 	if err := protoutil.Unmarshal(payloadBytes, &payload); err != nil {
 		return nil, err
@@ -2836,12 +2851,14 @@ func getChangefeedInfo(db *gosql.DB, jobID int) (*changefeedInfo, error) {
 		highwaterTime = highwater.GoTime()
 	}
 	return &changefeedInfo{
-		status:        status,
-		errMsg:        payload.Error,
-		startedTime:   time.UnixMicro(payload.StartedMicros),
-		statementTime: payload.GetChangefeed().StatementTime.GoTime(),
-		highwaterTime: highwaterTime,
-		finishedTime:  time.UnixMicro(payload.FinishedMicros),
+		status:            status,
+		errMsg:            payload.Error,
+		startedTime:       time.UnixMicro(payload.StartedMicros),
+		statementTime:     payload.GetChangefeed().StatementTime.GoTime(),
+		highwaterTime:     highwaterTime,
+		finishedTime:      time.UnixMicro(payload.FinishedMicros),
+		fractionCompleted: progress.GetFractionCompleted(),
+		runningStatus:     progress.RunningStatus,
 	}, nil
 }
 
@@ -14,27 +14,44 @@ import (
 	"bytes"
 	"context"
 	gosql "database/sql"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"math"
+	"net/url"
+	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
 
+	"github.com/IBM/sarama"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	cloudstorage "github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/cloud/gcp"
 	"github.com/cockroachdb/cockroach/pkg/cmd/roachtest/cluster"
 	"github.com/cockroachdb/cockroach/pkg/cmd/roachtest/option"
 	"github.com/cockroachdb/cockroach/pkg/cmd/roachtest/registry"
 	"github.com/cockroachdb/cockroach/pkg/cmd/roachtest/roachtestutil"
+	"github.com/cockroachdb/cockroach/pkg/cmd/roachtest/roachtestutil/clusterupgrade"
 	"github.com/cockroachdb/cockroach/pkg/cmd/roachtest/spec"
 	"github.com/cockroachdb/cockroach/pkg/cmd/roachtest/test"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/roachprod/install"
 	"github.com/cockroachdb/cockroach/pkg/roachprod/logger"
+	"github.com/cockroachdb/cockroach/pkg/testutils/release"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
 	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/workload/histogram"
 	"github.com/cockroachdb/errors"
 	humanize "github.com/dustin/go-humanize"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 )
 
@@ -61,41 +78,910 @@ const (
 	cdcBenchColdCatchupScan cdcBenchScanType = "catchup-cold"
 )
 
+// cdcBenchEmissionMode specifies the keys that the write workload of emission
+// benchmarks writes to.
+type cdcBenchEmissionMode string
+
+const (
+	// cdcBenchEmissionRandom writes to random keys, spreading the writes and the
+	// emitted rows across all ranges.
+	cdcBenchEmissionRandom cdcBenchEmissionMode = "random"
+
+	// cdcBenchEmissionSequential writes to sequential keys, concentrating the
+	// writes and the emitted rows on a single range at a time.
+	cdcBenchEmissionSequential cdcBenchEmissionMode = "sequential"
+)
+
+// cdcBenchSchedulerPool specifies the size of the rangefeed scheduler worker
+// pool, which processes events for all rangefeed processors on a store.
+type cdcBenchSchedulerPool string
+
+const (
+	// cdcBenchSchedulerPoolDefault uses the default pool size, which is 4
+	// workers per CPU up to a maximum of 64.
+	cdcBenchSchedulerPoolDefault cdcBenchSchedulerPool = "default"
+
+	// cdcBenchSchedulerPoolPerCPU uses 1 worker per CPU.
+	cdcBenchSchedulerPoolPerCPU cdcBenchSchedulerPool = "per-cpu"
+)
+
+// cdcBenchSchema specifies the schema of the table used by scan benchmarks.
+type cdcBenchSchema string
+
+const (
+	// cdcBenchSchemaKV uses the kv workload schema, i.e. an integer key and a
+	// small bytes value. This is the default.
+	cdcBenchSchemaKV cdcBenchSchema = "kv"
+
+	// cdcBenchSchemaJSONB adds a large JSONB column to the kv workload schema.
+	// JSONB values are expensive to encode, so this measures the encoding cost
+	// for JSONB-heavy tables that the kv schema underestimates.
+	cdcBenchSchemaJSONB cdcBenchSchema = "jsonb"
+)
+
+// cdcBenchSink specifies the sink that scan benchmarks emit to.
+type cdcBenchSink string
+
+const (
+	// cdcBenchSinkNull discards all emitted rows, measuring the changefeed
+	// without any sink overhead. This is the default.
+	cdcBenchSinkNull cdcBenchSink = "null"
+
+	// cdcBenchSinkKafka emits to a Kafka broker on the coordinator node over a
+	// plaintext connection.
+	cdcBenchSinkKafka cdcBenchSink = "kafka"
+
+	// cdcBenchSinkKafkaTLS emits to the same Kafka broker as cdcBenchSinkKafka,
+	// but over TLS. Comparing the two measures the overhead of encrypting
+	// changefeed traffic, which is often required for compliance.
+	cdcBenchSinkKafkaTLS cdcBenchSink = "kafka-tls"
+
+	// cdcBenchSinkKafkaCRDB emits to the same Kafka broker as cdcBenchSinkKafka,
+	// and consumes the messages from it into a second CockroachDB cluster, to
+	// measure the throughput of replicating a table between clusters through a
+	// message queue. The consumer runs on the test runner.
+	cdcBenchSinkKafkaCRDB cdcBenchSink = "kafka-crdb"
+
+	// cdcBenchSinkLocalFile emits files to the local disk of each node via a
+	// nodelocal cloud storage sink, as used in air-gapped deployments without
+	// network sinks. The files share the disk with the node's store.
+	cdcBenchSinkLocalFile cdcBenchSink = "local-file"
+
+	// cdcBenchSinkCloudStorage emits files to a GCS bucket, which is the most
+	// common production topology. It uses the credentials of the roachtest
+	// environment, so it only runs on GCE, and is skipped without them.
+	cdcBenchSinkCloudStorage cdcBenchSink = "cloud-storage"
+)
+
+// cdcBenchCompression specifies how file sinks compress the emitted files,
+// which trades CPU for bandwidth.
+type cdcBenchCompression string
+
+const (
+	// cdcBenchCompressionNone doesn't compress the emitted files.
+	cdcBenchCompressionNone cdcBenchCompression = "none"
+	// cdcBenchCompressionGzip compresses the emitted files with gzip.
+	cdcBenchCompressionGzip cdcBenchCompression = "gzip"
+)
+
+// cdcBenchFormatJSON, cdcBenchFormatAvro and cdcBenchFormatParquet are the
+// formats that scan benchmarks encode the emitted rows in. Each has a
+// different CPU cost. Avro requires a schema registry, and parquet requires a
+// file sink, as used by pipelines into data lakes.
+const (
+	cdcBenchFormatJSON    = "json"
+	cdcBenchFormatAvro    = "avro"
+	cdcBenchFormatParquet = "parquet"
+)
+
+// cdcBenchScanFormatSupported returns whether scan benchmarks run with the
+// given format, scan type and sink. Cold catchup scans don't encode any rows,
+// so they only run with JSON. The replication consumer of
+// cdcBenchSinkKafkaCRDB only decodes JSON, and parquet is only supported by
+// file sinks, i.e. cdcBenchSinkLocalFile and cdcBenchSinkCloudStorage.
+func cdcBenchScanFormatSupported(format string, scanType cdcBenchScanType, sink cdcBenchSink) bool {
+	switch {
+	case format == cdcBenchFormatJSON:
+		return true
+	case scanType == cdcBenchColdCatchupScan, sink == cdcBenchSinkKafkaCRDB:
+		return false
+	case format == cdcBenchFormatParquet:
+		return sink == cdcBenchSinkLocalFile || sink == cdcBenchSinkCloudStorage
+	default:
+		return true
+	}
+}
+
+// cdcBenchKafkaSinkConfig batches the messages emitted to Kafka, so that the
+// per-message overhead doesn't dominate.
+const cdcBenchKafkaSinkConfig = `{"Flush": {"Messages": 1000, "Frequency": "1s"}}`
+
+// cdcBenchReplicationDestNodes is the number of nodes of the destination
+// cluster that cdcBenchSinkKafkaCRDB replicates into. They are the last nodes
+// of the cluster.
+const cdcBenchReplicationDestNodes = 3
+
+// cdcBenchReplicationPartitions is the number of partitions of the topic that
+// cdcBenchSinkKafkaCRDB emits to. Each partition is consumed and applied to
+// the destination cluster by its own goroutine.
+const cdcBenchReplicationPartitions = 16
+
+// cdcBenchReplicationBatch is the number of rows that the consumer of
+// cdcBenchSinkKafkaCRDB applies to the destination cluster per statement.
+const cdcBenchReplicationBatch = 1000
+
+// cdcBenchReplicationIdleTimeout is how long the consumer of
+// cdcBenchSinkKafkaCRDB keeps waiting for messages once the changefeed has
+// completed, if it hasn't applied every row yet.
+const cdcBenchReplicationIdleTimeout = time.Minute
+
+// cdcBenchCloudStorageSinkBucket is the GCS bucket that cdcBenchSinkCloudStorage
+// writes to. It has a TTL that cleans up old data.
+const cdcBenchCloudStorageSinkBucket = "cockroach-tmp"
+
+// cdcBenchLocalFileSinkDir is the directory under each node's external IO
+// directory that cdcBenchSinkLocalFile writes to.
+const cdcBenchLocalFileSinkDir = "cdc-bench"
+
+// cdcBenchJSONBColumnDef adds a JSONB column to the kv workload table. It is a
+// computed column, so that both the import and insert data loaders populate
+// it without knowing about it, and it is hidden so that they don't try to
+// write it. Each value is a ~400 byte nested document derived from the key.
+const cdcBenchJSONBColumnDef = `ALTER TABLE kv.kv ADD COLUMN j JSONB NOT VISIBLE AS ((
+	'{"id": ' || k::STRING ||
+	', "name": "row-' || k::STRING || '"' ||
+	', "active": ' || (k % 2 = 0)::STRING ||
+	', "score": ' || (k % 1000)::STRING || '.5' ||
+	', "tags": ["alpha", "beta", "gamma", "delta", "epsilon"]' ||
+	', "address": {"street": "' || k::STRING || ' Main Street", "city": "New York", ' ||
+	'"zip": "' || (k % 100000)::STRING || '", "geo": {"lat": 40.7128, "lon": -74.0060}}' ||
+	', "history": [{"event": "created", "seq": ' || (k % 7)::STRING || '}, ' ||
+	'{"event": "updated", "seq": ' || (k % 11)::STRING || '}, ' ||
+	'{"event": "archived", "seq": ' || (k % 13)::STRING || '}]' ||
+	', "attributes": {"color": "blue", "size": "large", "weight": ' || (k % 500)::STRING || ', "fragile": false}}'
+)::JSONB) STORED`
+
 var (
 	cdcBenchScanTypes = []cdcBenchScanType{
 		cdcBenchInitialScan, cdcBenchCatchupScan, cdcBenchColdCatchupScan}
+	cdcBenchEmissionModes = []cdcBenchEmissionMode{
+		cdcBenchEmissionRandom, cdcBenchEmissionSequential}
+	// cdcBenchEmissionWriteRates are the rates, in rows per second, at which
+	// the write workload of emission benchmarks writes.
+	cdcBenchEmissionWriteRates = []int{1000, 10000}
+	cdcBenchSchedulerPools     = []cdcBenchSchedulerPool{
+		cdcBenchSchedulerPoolDefault, cdcBenchSchedulerPoolPerCPU}
+	cdcBenchSchemas = []cdcBenchSchema{
+		cdcBenchSchemaKV, cdcBenchSchemaJSONB}
+	cdcBenchFormats = []string{
+		cdcBenchFormatJSON, cdcBenchFormatAvro, cdcBenchFormatParquet}
+	// cdcBenchFeedMemPercents are the percentages of the rangefeed memory pool
+	// that a single feed may buffer events in, around the default of 5%.
+	cdcBenchFeedMemPercents  = []int{1, 20}
+	cdcBenchChangefeedCounts = []int{4, 16}
+	// cdcBenchScanNodeCounts and cdcBenchScanCPUCounts are the data node and
+	// CPU counts, besides the defaults of 5 and 16, that some scan benchmarks
+	// are also run with to study how they scale.
+	cdcBenchScanNodeCounts = []int{3, 10}
+	cdcBenchScanCPUCounts  = []int{8, 32}
+	cdcBenchMemoryBudgets  = []int64{64 << 20, 256 << 20, 1 << 30}
+	// cdcBenchRangeGranularities are the range counts that variants with
+	// rangeGranularity split the same data into.
+	cdcBenchRangeGranularities = []int64{100, 1000, 10000, 100000}
+	// cdcBenchScanDefaultRanges is the range count of the scan benchmarks of
+	// the variants other than the default one.
+	cdcBenchScanDefaultRanges = int64(100000)
+	// cdcBenchScanSmokeRows is the row count of the small scan benchmarks, which
+	// aren't run nightly but are quick enough to run locally or in CI.
+	cdcBenchScanSmokeRows = int64(10_000_000)
 )
 
+// cdcBenchGeoZones are the GCE zones that multi-region benchmarks spread the
+// cluster across, one per region.
+const cdcBenchGeoZones = "us-east1-b,us-west1-b,europe-west2-b"
+
+// cdcBenchClusterOpts configures the cluster for a CDC benchmark. The zero
+// value uses the defaults.
+type cdcBenchClusterOpts struct {
+	// schedulerPool selects the rangefeed scheduler pool size. Defaults to
+	// cdcBenchSchedulerPoolDefault.
+	schedulerPool cdcBenchSchedulerPool
+	// nodeByteRateLimit limits the rate at which changefeeds on each node
+	// consume events, in bytes per second. 0 disables the limit.
+	nodeByteRateLimit int64
+	// replicationFactor is the number of replicas for all ranges, including the
+	// workload table. Defaults to 3.
+	replicationFactor int
+	// sink selects the sink that the changefeed emits to. Defaults to
+	// cdcBenchSinkNull.
+	sink cdcBenchSink
+	// kvScanBaseline runs a SQL full table scan before the changefeed, to
+	// measure the cost of reading the data separately from the cost of the
+	// rangefeed machinery.
+	kvScanBaseline bool
+	// minCheckpointFrequency sets the changefeed's min_checkpoint_frequency
+	// option. 0 uses the changefeed's default.
+	minCheckpointFrequency time.Duration
+	// coordinatorCPUs limits the number of CPUs that the changefeed
+	// coordinator node can use, via GOMAXPROCS. 0 uses all of the node's CPUs.
+	coordinatorCPUs int
+	// incrementalSplits is the number of ranges to split off the table at
+	// every cdcBenchSplitInterval while the scan runs, to model a table that
+	// grows during a long catchup scan. 0 disables splits during the scan.
+	incrementalSplits int
+	// compactionPressure builds up a compaction backlog on the data nodes
+	// before the scan, by limiting their compaction concurrency while writing
+	// to a separate table, and keeps the concurrency limited during the scan.
+	compactionPressure bool
+	// columnFamilies is the number of column families of the workload table.
+	// Families beyond the first each contain a hidden computed column. 0 uses
+	// a single family.
+	columnFamilies int
+	// coordinatorSQLLoad runs a KV query workload against the changefeed
+	// coordinator during the scan, and lets the coordinator hold data like the
+	// other nodes. This models small clusters, where the coordinator also
+	// serves application traffic.
+	coordinatorSQLLoad bool
+	// slowStoreNodes is the number of data nodes whose store read bandwidth is
+	// limited to cdcBenchSlowStoreReadBandwidth during the scan, to model
+	// clusters with a mix of fast and slow disks. 0 doesn't limit any nodes.
+	// This is only supported on GCE.
+	slowStoreNodes int
+	// concurrentImport imports cdcBenchConcurrentImportRows rows into a
+	// separate table while the changefeed scans, to measure how bulk ingestion
+	// contends with the scan for CPU, disk and admission control.
+	concurrentImport bool
+	// initialScanOnly runs initial scans with initial_scan = 'only', which
+	// finishes the changefeed once the scan completes, instead of with
+	// initial_scan = 'yes' and an end time. This is only supported for initial
+	// scans.
+	initialScanOnly bool
+	// feedMemPercent overrides the percentage of the rangefeed memory pool that
+	// a single feed may use to buffer events. 0 uses the default of 5%.
+	feedMemPercent int
+	// multiRegion spreads the cluster across the regions in cdcBenchGeoZones,
+	// and records the network traffic of the data nodes. This is only
+	// supported on GCE.
+	multiRegion bool
+	// mixedHistory runs a workload of reads, upserts and deletes after the
+	// data is ingested, so that the catchup scan reads several versions and
+	// tombstones per key rather than a single insert. This is only supported
+	// for catchup scans.
+	mixedHistory bool
+	// changefeeds is the number of identical changefeeds to run concurrently
+	// over the workload table. Each of them protects the data it needs from
+	// GC with its own protected timestamp record. Defaults to 1.
+	changefeeds int
+	// separateConnections creates each of the changefeeds concurrently from
+	// its own SQL connection to the coordinator, rather than one after the
+	// other from a single connection, to model many tenants creating
+	// changefeeds at once.
+	separateConnections bool
+	// underReplicated ingests the data with a single replica per range of the
+	// workload table, and only raises the table to the replication factor
+	// right before the changefeed starts, so that its ranges upreplicate while
+	// the changefeed scans them. This models changefeeds that catch up during
+	// cluster recovery.
+	underReplicated bool
+	// memoryBudget sets changefeed.memory.per_changefeed_limit, the memory
+	// that each changefeed can use on each node to buffer events, in bytes. A
+	// tighter budget forces the changefeed to flush more often. 0 uses the
+	// benchmark's default of 4 GB.
+	memoryBudget int64
+	// releaseVersion runs the cluster on the cockroach binary of the given
+	// release, such as "v24.1.0", instead of the binary under test, to track
+	// the scan rate across releases. cdcBenchReleasePredecessor selects the
+	// latest release preceding the binary under test.
+	releaseVersion string
+	// rangeGranularity runs the benchmark with the data split into each of
+	// cdcBenchRangeGranularities, rather than only into the range counts of
+	// the schema, and records the layout of the table before the scan. The
+	// data size is the same for each range count, so differences in the scan
+	// rate between them are due to the per-range overhead of registering and
+	// scanning rangefeeds. This is only supported with the KV schema.
+	rangeGranularity bool
+	// nodes is the number of data nodes of scan benchmarks, excluding the
+	// coordinator and the destination cluster. Defaults to 5.
+	nodes int
+	// cpus is the number of CPUs of every node of scan benchmarks. Defaults to
+	// 16.
+	cpus int
+	// compression compresses the files emitted by file sinks. Defaults to
+	// cdcBenchCompressionNone.
+	compression cdcBenchCompression
+}
+
+// cdcBenchReleasePredecessor is the releaseVersion that selects the latest
+// release preceding the binary under test.
+const cdcBenchReleasePredecessor = "predecessor"
+
+// getReleaseVersion returns the release to run the cluster on, or nil to run
+// the binary under test.
+func (o cdcBenchClusterOpts) getReleaseVersion(t test.Test) *clusterupgrade.Version {
+	switch o.releaseVersion {
+	case "":
+		return nil
+	case cdcBenchReleasePredecessor:
+		pred, err := release.LatestPredecessor(t.BuildVersion())
+		require.NoError(t, err)
+		return clusterupgrade.MustParseVersion(pred)
+	default:
+		return clusterupgrade.MustParseVersion(o.releaseVersion)
+	}
+}
+
+// cdcBenchSlowStoreReadBandwidth is the read bandwidth of the slow stores
+// with slowStoreNodes, in bytes per second.
+const cdcBenchSlowStoreReadBandwidth = 32 << 20 // 32 MiB/s
+
+// getNodes returns the number of data nodes, or the default of 5 if unset.
+func (o cdcBenchClusterOpts) getNodes() int {
+	if o.nodes == 0 {
+		return 5
+	}
+	return o.nodes
+}
+
+// getCPUs returns the number of CPUs per node, or the default of 16 if unset.
+func (o cdcBenchClusterOpts) getCPUs() int {
+	if o.cpus == 0 {
+		return 16
+	}
+	return o.cpus
+}
+
+// getReplicationFactor returns the replication factor, or the default of 3 if
+// unset.
+func (o cdcBenchClusterOpts) getReplicationFactor() int {
+	if o.replicationFactor == 0 {
+		return 3
+	}
+	return o.replicationFactor
+}
+
+// getColumnFamilies returns the number of column families, or the default of
+// 1 if unset.
+func (o cdcBenchClusterOpts) getColumnFamilies() int {
+	if o.columnFamilies == 0 {
+		return 1
+	}
+	return o.columnFamilies
+}
+
+// getChangefeeds returns the number of changefeeds, or the default of 1 if
+// unset.
+func (o cdcBenchClusterOpts) getChangefeeds() int {
+	if o.changefeeds == 0 {
+		return 1
+	}
+	return o.changefeeds
+}
+
+// getSink returns the sink, or the default null sink if unset.
+func (o cdcBenchClusterOpts) getSink() cdcBenchSink {
+	if o.sink == "" {
+		return cdcBenchSinkNull
+	}
+	return o.sink
+}
+
+// getCompression returns the compression of the emitted files, or the default
+// cdcBenchCompressionNone if unset.
+func (o cdcBenchClusterOpts) getCompression() cdcBenchCompression {
+	if o.compression == "" {
+		return cdcBenchCompressionNone
+	}
+	return o.compression
+}
+
+// getDestNodes returns the number of nodes of the destination cluster that
+// the sink replicates into, which is 0 unless it's cdcBenchSinkKafkaCRDB.
+func (o cdcBenchClusterOpts) getDestNodes() int {
+	if o.getSink() == cdcBenchSinkKafkaCRDB {
+		return cdcBenchReplicationDestNodes
+	}
+	return 0
+}
+
+// cdcBenchScanVariant is a cluster configuration to run a scan benchmark with,
+// along with the suffix to add to the test name for it.
+type cdcBenchScanVariant struct {
+	name string
+	opts cdcBenchClusterOpts
+	// isDefault is set for the variant of each scan type that the schemas,
+	// formats and range counts are swept for. The other variants only run with
+	// the KV schema, the JSON format and cdcBenchScanDefaultRanges ranges.
+	isDefault bool
+	// weekly is set for the one-off sweeps, which are run weekly rather than
+	// nightly.
+	weekly bool
+}
+
+// cdcBenchScanVariants returns the configurations to run the given scan type
+// with.
+func cdcBenchScanVariants(scanType cdcBenchScanType) []cdcBenchScanVariant {
+	switch scanType {
+	case cdcBenchInitialScan:
+		// Initial scans are also run with a per-node rate limit, to verify that
+		// the limit is respected, and with a Kafka sink both with and without TLS
+		// to measure the cost of encrypting the emitted rows. They're also run
+		// with a Kafka sink that is consumed into a second cluster, to size
+		// replication pipelines between clusters. The sink is part of the test
+		// name already, so these don't need a suffix.
+		const limit = 32 << 20 // 32 MiB/s
+		variants := []cdcBenchScanVariant{
+			{isDefault: true},
+			{
+				name: fmt.Sprintf("/node-rate-limit=%dMiB", limit>>20),
+				opts: cdcBenchClusterOpts{nodeByteRateLimit: limit},
+			},
+			{opts: cdcBenchClusterOpts{sink: cdcBenchSinkKafka}},
+			{opts: cdcBenchClusterOpts{sink: cdcBenchSinkKafkaTLS}},
+			{opts: cdcBenchClusterOpts{sink: cdcBenchSinkKafkaCRDB}},
+			{opts: cdcBenchClusterOpts{sink: cdcBenchSinkLocalFile}},
+			{opts: cdcBenchClusterOpts{sink: cdcBenchSinkCloudStorage}},
+			// Compress the files emitted to cloud storage, to compare the CPU
+			// cost of compression against the bandwidth it saves.
+			{
+				name: fmt.Sprintf("/compression=%s", cdcBenchCompressionGzip),
+				opts: cdcBenchClusterOpts{sink: cdcBenchSinkCloudStorage, compression: cdcBenchCompressionGzip},
+			},
+			// Sweep the checkpoint frequency around the default of 30s, to
+			// measure how much checkpointing progress costs during a scan.
+			{
+				name:   "/min-checkpoint-frequency=1s",
+				opts:   cdcBenchClusterOpts{minCheckpointFrequency: time.Second},
+				weekly: true,
+			},
+			{
+				name:   "/min-checkpoint-frequency=5s",
+				opts:   cdcBenchClusterOpts{minCheckpointFrequency: 5 * time.Second},
+				weekly: true,
+			},
+			{
+				name:   "/min-checkpoint-frequency=5m",
+				opts:   cdcBenchClusterOpts{minCheckpointFrequency: 5 * time.Minute},
+				weekly: true,
+			},
+			// Constrain the coordinator's CPU, since production coordinators are
+			// often smaller than the data nodes.
+			{
+				name: "/coordinator-cpu=2",
+				opts: cdcBenchClusterOpts{coordinatorCPUs: 2},
+			},
+			{
+				name: "/coordinator-cpu=4",
+				opts: cdcBenchClusterOpts{coordinatorCPUs: 4},
+			},
+			// Sweep the number of column families, since changefeeds emit a
+			// separate event for each family of a row.
+			{
+				name: "/column-families=2",
+				opts: cdcBenchClusterOpts{columnFamilies: 2},
+			},
+			{
+				name: "/column-families=4",
+				opts: cdcBenchClusterOpts{columnFamilies: 4},
+			},
+			// Serve queries from the coordinator during the scan, to measure how
+			// the scan and the queries interfere with each other.
+			{
+				name: "/coordinator-sql-load",
+				opts: cdcBenchClusterOpts{coordinatorSQLLoad: true},
+			},
+			// Run a one-shot scan, which is commonly used to export tables, to
+			// compare it against a scan with an end time.
+			{
+				name: "/scan=initial-only",
+				opts: cdcBenchClusterOpts{initialScanOnly: true},
+			},
+		}
+		// Scale the cluster, to measure how the scan rate scales with the number
+		// of nodes and CPUs. These are part of the test name already.
+		for _, nodes := range cdcBenchScanNodeCounts {
+			variants = append(variants, cdcBenchScanVariant{opts: cdcBenchClusterOpts{nodes: nodes}})
+		}
+		for _, cpus := range cdcBenchScanCPUCounts {
+			variants = append(variants, cdcBenchScanVariant{opts: cdcBenchClusterOpts{cpus: cpus}})
+		}
+		return variants
+
+	case cdcBenchCatchupScan:
+		// Warm catchup scans emit events through the rangefeed processors, so
+		// run them with different scheduler pool sizes to compare throughput and
+		// goroutine counts. Initial scans don't use rangefeeds.
+		var variants []cdcBenchScanVariant
+		// The default pool is also run with a sweep of range counts over the
+		// same data, to separate the per-range overhead from the data volume.
+		for _, pool := range cdcBenchSchedulerPools {
+			variants = append(variants, cdcBenchScanVariant{
+				name: fmt.Sprintf("/scheduler=%s", pool),
+				opts: cdcBenchClusterOpts{
+					schedulerPool:    pool,
+					rangeGranularity: pool == cdcBenchSchedulerPoolDefault,
+				},
+				isDefault: pool == cdcBenchSchedulerPoolDefault,
+			})
+		}
+		// Also compare the catchup scan against a plain KV scan of the same
+		// data, to attribute its cost to the rangefeed machinery rather than to
+		// reading the data.
+		variants = append(variants, cdcBenchScanVariant{
+			name: fmt.Sprintf("/scheduler=%s/baseline=kv-scan", cdcBenchSchedulerPoolDefault),
+			opts: cdcBenchClusterOpts{
+				schedulerPool:  cdcBenchSchedulerPoolDefault,
+				kvScanBaseline: true,
+			},
+		})
+		// Split the table further while the scan runs, to measure how the scan
+		// rate adapts as the number of ranges grows.
+		const incrementalSplits = 1000
+		variants = append(variants, cdcBenchScanVariant{
+			name: fmt.Sprintf("/scheduler=%s/incremental-splits=%d", cdcBenchSchedulerPoolDefault, incrementalSplits),
+			opts: cdcBenchClusterOpts{
+				schedulerPool:     cdcBenchSchedulerPoolDefault,
+				incrementalSplits: incrementalSplits,
+			},
+		})
+		// Import into another table while the scan runs, to compare the scan
+		// rate against the default scheduler variant without the import.
+		variants = append(variants, cdcBenchScanVariant{
+			name: fmt.Sprintf("/scheduler=%s/concurrent-import", cdcBenchSchedulerPoolDefault),
+			opts: cdcBenchClusterOpts{
+				schedulerPool:    cdcBenchSchedulerPoolDefault,
+				concurrentImport: true,
+			},
+		})
+		// Sweep the memory budget that each rangefeed may buffer events in, to
+		// measure how it trades off memory usage against the scan rate when the
+		// changefeed can't keep up.
+		for _, pct := range cdcBenchFeedMemPercents {
+			variants = append(variants, cdcBenchScanVariant{
+				name: fmt.Sprintf("/scheduler=%s/feed-mem-pct=%d", cdcBenchSchedulerPoolDefault, pct),
+				opts: cdcBenchClusterOpts{
+					schedulerPool:  cdcBenchSchedulerPoolDefault,
+					feedMemPercent: pct,
+				},
+				weekly: true,
+			})
+		}
+		// Spread the cluster across regions, to measure the scan rate and the
+		// network traffic of locality-aware rangefeed routing in geo-distributed
+		// clusters.
+		variants = append(variants, cdcBenchScanVariant{
+			name: fmt.Sprintf("/scheduler=%s/regions=3", cdcBenchSchedulerPoolDefault),
+			opts: cdcBenchClusterOpts{
+				schedulerPool: cdcBenchSchedulerPoolDefault,
+				multiRegion:   true,
+			},
+		})
+		// Build a history of updates and deletes before the scan, since the
+		// insert-only history of the other variants is cheaper to scan than
+		// that of most production tables.
+		variants = append(variants, cdcBenchScanVariant{
+			name: fmt.Sprintf("/scheduler=%s/history=mixed", cdcBenchSchedulerPoolDefault),
+			opts: cdcBenchClusterOpts{
+				schedulerPool: cdcBenchSchedulerPoolDefault,
+				mixedHistory:  true,
+			},
+		})
+		// Run several changefeeds at once, to measure how the bookkeeping of
+		// their protected timestamp records scales with the number of
+		// changefeeds.
+		for _, changefeeds := range cdcBenchChangefeedCounts {
+			variants = append(variants, cdcBenchScanVariant{
+				name: fmt.Sprintf("/scheduler=%s/changefeeds=%d", cdcBenchSchedulerPoolDefault, changefeeds),
+				opts: cdcBenchClusterOpts{
+					schedulerPool: cdcBenchSchedulerPoolDefault,
+					changefeeds:   changefeeds,
+				},
+				weekly: true,
+			})
+		}
+		// Also create them concurrently from separate connections, to measure
+		// the load that creating many changefeeds puts on the SQL layer.
+		for _, changefeeds := range cdcBenchChangefeedCounts {
+			variants = append(variants, cdcBenchScanVariant{
+				name: fmt.Sprintf("/scheduler=%s/changefeeds=%d/connections=%d",
+					cdcBenchSchedulerPoolDefault, changefeeds, changefeeds),
+				opts: cdcBenchClusterOpts{
+					schedulerPool:       cdcBenchSchedulerPoolDefault,
+					changefeeds:         changefeeds,
+					separateConnections: true,
+				},
+				weekly: true,
+			})
+		}
+		// Sweep the changefeed memory budget below the benchmark's default, to
+		// find the smallest budget that doesn't hold back the scan.
+		for _, budget := range cdcBenchMemoryBudgets {
+			variants = append(variants, cdcBenchScanVariant{
+				name: fmt.Sprintf("/scheduler=%s/memory-budget=%dMiB", cdcBenchSchedulerPoolDefault, budget>>20),
+				opts: cdcBenchClusterOpts{
+					schedulerPool: cdcBenchSchedulerPoolDefault,
+					memoryBudget:  budget,
+				},
+				weekly: true,
+			})
+		}
+		// Run the previous release too, to catch regressions that build up
+		// gradually across releases. Its stats are tagged with its version.
+		variants = append(variants, cdcBenchScanVariant{
+			name: fmt.Sprintf("/scheduler=%s/release=%s", cdcBenchSchedulerPoolDefault, cdcBenchReleasePredecessor),
+			opts: cdcBenchClusterOpts{
+				schedulerPool:  cdcBenchSchedulerPoolDefault,
+				releaseVersion: cdcBenchReleasePredecessor,
+			},
+		})
+		// Start the scan while the table is still upreplicating, since the
+		// other variants wait for full replication first, unlike changefeeds
+		// catching up during recovery.
+		variants = append(variants, cdcBenchScanVariant{
+			name: fmt.Sprintf("/scheduler=%s/under-replicated", cdcBenchSchedulerPoolDefault),
+			opts: cdcBenchClusterOpts{
+				schedulerPool:   cdcBenchSchedulerPoolDefault,
+				underReplicated: true,
+			},
+		})
+		// Split the table into two column families, like the initial scan
+		// variants do. Catchup scans emit a separate rangefeed event for each
+		// family of a row, rather than scanning the rows themselves.
+		variants = append(variants, cdcBenchScanVariant{
+			name: fmt.Sprintf("/scheduler=%s/column-families=2", cdcBenchSchedulerPoolDefault),
+			opts: cdcBenchClusterOpts{
+				schedulerPool:  cdcBenchSchedulerPoolDefault,
+				columnFamilies: 2,
+			},
+		})
+		return variants
+
+	case cdcBenchColdCatchupScan:
+		// Cold catchup scans are also run with 5x replication, to compare how the
+		// replication factor affects the cost of catchup scans.
+		variants := []cdcBenchScanVariant{
+			{isDefault: true},
+		}
+		const replicationFactor = 5
+		variants = append(variants, cdcBenchScanVariant{
+			name: fmt.Sprintf("/replicas=%d", replicationFactor),
+			opts: cdcBenchClusterOpts{replicationFactor: replicationFactor},
+		})
+		// Also scale the cluster, since cold catchup scans are the common case in
+		// production clusters of all sizes.
+		for _, nodes := range cdcBenchScanNodeCounts {
+			variants = append(variants, cdcBenchScanVariant{opts: cdcBenchClusterOpts{nodes: nodes}})
+		}
+		// Production scans often overlap with compactions, so also run with a
+		// compaction backlog, which affects how much of the LSM the scan must
+		// read.
+		variants = append(variants, cdcBenchScanVariant{
+			name: "/compaction-pressure",
+			opts: cdcBenchClusterOpts{compactionPressure: true},
+		})
+		// Limit the disk bandwidth of a single node, to measure how much a slow
+		// node holds back the scan across the cluster.
+		variants = append(variants, cdcBenchScanVariant{
+			name: "/slow-stores=1",
+			opts: cdcBenchClusterOpts{slowStoreNodes: 1},
+		})
+		return variants
+
+	default:
+		panic(fmt.Sprintf("unknown scan type %q", scanType))
+	}
+}
+
 func registerCDCBench(r registry.Registry) {
+	// NB: all benchmarks use the mux rangefeed protocol, since the
+	// changefeed.mux_rangefeed.enabled setting and the non-mux protocol were
+	// removed in 24.1. The names keep protocol=mux for continuity with the
+	// historical results in roachperf.
 
 	// Initial/catchup scan benchmarks.
 	for _, scanType := range cdcBenchScanTypes {
-		for _, ranges := range []int64{100, 100000} {
-			scanType, ranges := scanType, ranges // pin loop variables
-			const (
-				nodes  = 5 // excluding coordinator/workload node
-				cpus   = 16
-				rows   = 1_000_000_000 // 19 GB
-				format = "json"
+		for _, schema := range cdcBenchSchemas {
+			// The JSONB schema has much larger rows, so use fewer of them to keep
+			// the data size manageable, and only run with 100 ranges.
+			var (
+				rows        = int64(1_000_000_000) // 19 GB
+				rangeCounts = []int64{100, 100000}
+				schemaName  = ""
 			)
-			r.Add(registry.TestSpec{
-				Name: fmt.Sprintf(
-					"cdc/scan/%s/nodes=%d/cpu=%d/rows=%s/ranges=%s/protocol=mux/format=%s/sink=null",
-					scanType, nodes, cpus, formatSI(rows), formatSI(ranges), format),
-				Owner:            registry.OwnerCDC,
-				Benchmark:        true,
-				Cluster:          r.MakeClusterSpec(nodes+1, spec.CPU(cpus)),
-				CompatibleClouds: registry.AllExceptAWS,
-				Suites:           registry.Suites(registry.Nightly),
-				RequiresLicense:  true,
-				Timeout:          4 * time.Hour, // Allow for the initial import and catchup scans with 100k ranges.
-				Run: func(ctx context.Context, t test.Test, c cluster.Cluster) {
-					runCDCBenchScan(ctx, t, c, scanType, rows, ranges, format)
-				},
-			})
+			if schema == cdcBenchSchemaJSONB {
+				rows = 100_000_000 // 47 GB
+				rangeCounts = []int64{100}
+				schemaName = fmt.Sprintf("/schema=%s", schema)
+			}
+			for _, variant := range cdcBenchScanVariants(scanType) {
+				// Only the default variant sweeps the schemas, formats and range
+				// counts, to keep the number of nightly benchmarks manageable.
+				if !variant.isDefault && schema != cdcBenchSchemaKV {
+					continue
+				}
+				variantRangeCounts := rangeCounts
+				if !variant.isDefault {
+					variantRangeCounts = []int64{cdcBenchScanDefaultRanges}
+				}
+				// The range granularity sweep holds the data size constant, so it
+				// only runs with the KV schema. The range counts other than the
+				// default ones are only run weekly.
+				if variant.opts.rangeGranularity && schema == cdcBenchSchemaKV {
+					variantRangeCounts = cdcBenchRangeGranularities
+				}
+				// The default variant also has a small variant for quick runs.
+				variantRowCounts := []int64{rows}
+				if variant.name == "" && variant.opts.getSink() == cdcBenchSinkNull {
+					variantRowCounts = append(variantRowCounts, cdcBenchScanSmokeRows)
+				}
+				for _, rows := range variantRowCounts {
+					for _, ranges := range variantRangeCounts {
+						for _, format := range cdcBenchFormats {
+							if !variant.isDefault && format != cdcBenchFormatJSON {
+								continue
+							}
+							if !cdcBenchScanFormatSupported(format, scanType, variant.opts.getSink()) {
+								continue
+							}
+							scanType, schema, rows, ranges, variant, format := scanType, schema, rows, ranges, variant, format // pin loop variables
+							var (
+								nodes = variant.opts.getNodes() // excluding coordinator/workload node and destination cluster
+								cpus  = variant.opts.getCPUs()
+							)
+							// Disk bandwidth can only be limited on GCE, see cgroupDiskStaller,
+							// and the multi-region zones and the cloud storage sink's bucket
+							// are on GCE.
+							clouds := registry.AllExceptAWS
+							specOpts := []spec.Option{spec.CPU(cpus)}
+							if variant.opts.slowStoreNodes > 0 || variant.opts.getSink() == cdcBenchSinkCloudStorage {
+								clouds = registry.OnlyGCE
+							}
+							if variant.opts.multiRegion {
+								clouds = registry.OnlyGCE
+								specOpts = append(specOpts, spec.Geo(), spec.GCEZones(cdcBenchGeoZones))
+							}
+							// Allow for the initial import and catchup scans with 100k ranges.
+							// The small variants are only run manually, and the one-off
+							// sweeps weekly.
+							suites, timeout := registry.Suites(registry.Nightly), 4*time.Hour
+							switch {
+							case rows == cdcBenchScanSmokeRows:
+								suites, timeout = registry.ManualOnly, time.Hour
+							case variant.weekly || !slices.Contains(rangeCounts, ranges):
+								suites = registry.Suites(registry.Weekly)
+							}
+							r.Add(registry.TestSpec{
+								Name: fmt.Sprintf(
+									"cdc/scan/%s/nodes=%d/cpu=%d/rows=%s%s/ranges=%s%s/protocol=mux/format=%s/sink=%s",
+									scanType, nodes, cpus, formatSI(rows), schemaName, formatSI(ranges), variant.name, format,
+									variant.opts.getSink()),
+								Owner:            registry.OwnerCDC,
+								Benchmark:        true,
+								Cluster:          r.MakeClusterSpec(nodes+1+variant.opts.getDestNodes(), specOpts...),
+								CompatibleClouds: clouds,
+								Suites:           suites,
+								RequiresLicense:  true,
+								Timeout:          timeout,
+								Run: func(ctx context.Context, t test.Test, c cluster.Cluster) {
+									runCDCBenchScan(ctx, t, c, scanType, schema, rows, ranges, format, variant.opts)
+								},
+							})
+						}
+					}
+				}
+			}
+		}
+	}
+
+	// Steady-state emission benchmarks.
+	for _, mode := range cdcBenchEmissionModes {
+		for _, ranges := range []int64{100, 100000} {
+			for _, writeRate := range cdcBenchEmissionWriteRates {
+				mode, ranges, writeRate := mode, ranges, writeRate // pin loop variables
+				const (
+					nodes    = 5 // excluding coordinator/workload node
+					cpus     = 16
+					rows     = int64(100_000_000) // 1.9 GB
+					duration = 15 * time.Minute
+					format   = "json"
+				)
+				r.Add(registry.TestSpec{
+					Name: fmt.Sprintf(
+						"cdc/emission/%s/nodes=%d/cpu=%d/rows=%s/ranges=%s/rate=%s/duration=%dm/protocol=mux/format=%s/sink=null",
+						mode, nodes, cpus, formatSI(rows), formatSI(ranges), formatSI(int64(writeRate)),
+						int(duration.Minutes()), format),
+					Owner:            registry.OwnerCDC,
+					Benchmark:        true,
+					Cluster:          r.MakeClusterSpec(nodes+1, spec.CPU(cpus)),
+					CompatibleClouds: registry.AllExceptAWS,
+					Suites:           registry.Suites(registry.Nightly),
+					RequiresLicense:  true,
+					Timeout:          2 * time.Hour,
+					Run: func(ctx context.Context, t test.Test, c cluster.Cluster) {
+						runCDCBenchEmission(ctx, t, c, mode, rows, ranges, duration, writeRate, format)
+					},
+				})
+			}
 		}
 	}
 
+	// Changefeed query (CDC transformation) benchmarks.
+	for _, ranges := range []int64{100, 100000} {
+		ranges := ranges // pin loop variable
+		const (
+			nodes  = 5 // excluding coordinator/workload node
+			cpus   = 16
+			rows   = int64(1_000_000_000) // 19 GB
+			format = "json"
+		)
+		r.Add(registry.TestSpec{
+			Name: fmt.Sprintf(
+				"cdc/query/initial/nodes=%d/cpu=%d/rows=%s/ranges=%s/filter=%dpct/protocol=mux/format=%s/sink=null",
+				nodes, cpus, formatSI(rows), formatSI(ranges), 100/cdcBenchQueryFilterModulus, format),
+			Owner:            registry.OwnerCDC,
+			Benchmark:        true,
+			Cluster:          r.MakeClusterSpec(nodes+1, spec.CPU(cpus)),
+			CompatibleClouds: registry.AllExceptAWS,
+			Suites:           registry.Suites(registry.Nightly),
+			RequiresLicense:  true,
+			Timeout:          4 * time.Hour, // Allow for the initial import and scans with 100k ranges.
+			Run: func(ctx context.Context, t test.Test, c cluster.Cluster) {
+				runCDCBenchQuery(ctx, t, c, rows, ranges, format)
+			},
+		})
+	}
+
+	// Changefeed restart benchmarks.
+	for _, ranges := range []int64{100, 10000} {
+		ranges := ranges // pin loop variable
+		const (
+			nodes    = 5 // excluding coordinator and workload nodes
+			cpus     = 16
+			downtime = time.Minute
+			format   = "json"
+		)
+		r.Add(registry.TestSpec{
+			Name: fmt.Sprintf(
+				"cdc/restart/nodes=%d/cpu=%d/ranges=%s/downtime=%dm/protocol=mux/format=%s/sink=null",
+				nodes, cpus, formatSI(ranges), int(downtime.Minutes()), format),
+			Owner:            registry.OwnerCDC,
+			Benchmark:        true,
+			Cluster:          r.MakeClusterSpec(nodes+2, spec.CPU(cpus)),
+			CompatibleClouds: registry.AllExceptAWS,
+			Suites:           registry.Suites(registry.Nightly),
+			RequiresLicense:  true,
+			Timeout:          time.Hour,
+			Run: func(ctx context.Context, t test.Test, c cluster.Cluster) {
+				runCDCBenchRestart(ctx, t, c, ranges, downtime, format)
+			},
+		})
+	}
+
+	// Schema change benchmarks.
+	for _, ranges := range []int64{100, 10000} {
+		ranges := ranges // pin loop variable
+		const (
+			nodes  = 5 // excluding coordinator/workload node
+			cpus   = 16
+			rows   = 10_000_000
+			format = "json"
+		)
+		r.Add(registry.TestSpec{
+			Name: fmt.Sprintf(
+				"cdc/schemachange/add-column/nodes=%d/cpu=%d/rows=%s/ranges=%s/protocol=mux/format=%s/sink=null",
+				nodes, cpus, formatSI(rows), formatSI(ranges), format),
+			Owner:            registry.OwnerCDC,
+			Benchmark:        true,
+			Cluster:          r.MakeClusterSpec(nodes+1, spec.CPU(cpus)),
+			CompatibleClouds: registry.AllExceptAWS,
+			Suites:           registry.Suites(registry.Nightly),
+			RequiresLicense:  true,
+			Timeout:          2 * time.Hour,
+			Run: func(ctx context.Context, t test.Test, c cluster.Cluster) {
+				runCDCBenchSchemaChange(ctx, t, c, rows, ranges, format)
+			},
+		})
+	}
+
 	// Workload impact benchmarks.
 	for _, readPercent := range []int{0, 100} {
 		for _, ranges := range []int64{100, 100000} {
@@ -119,41 +1005,114 @@ func registerCDCBench(r registry.Registry) {
 				RequiresLicense:  true,
 				Timeout:          time.Hour,
 				Run: func(ctx context.Context, t test.Test, c cluster.Cluster) {
-					runCDCBenchWorkload(ctx, t, c, ranges, readPercent, "")
+					runCDCBenchWorkload(ctx, t, c, ranges, readPercent, "", cdcBenchSinkNull)
 				},
 			})
 
-			// Workloads with a concurrent changefeed running.
-			r.Add(registry.TestSpec{
-				Name: fmt.Sprintf(
-					"cdc/workload/kv%d/nodes=%d/cpu=%d/ranges=%s/server=scheduler/protocol=mux/format=%s/sink=null",
-					readPercent, nodes, cpus, formatSI(ranges), format),
-				Owner:            registry.OwnerCDC,
-				Benchmark:        true,
-				Cluster:          r.MakeClusterSpec(nodes+2, spec.CPU(cpus)),
-				CompatibleClouds: registry.AllExceptAWS,
-				Suites:           registry.Suites(registry.Nightly),
-				RequiresLicense:  true,
-				Timeout:          time.Hour,
-				Run: func(ctx context.Context, t test.Test, c cluster.Cluster) {
-					runCDCBenchWorkload(ctx, t, c, ranges, readPercent, format)
-				},
-			})
+			// Workloads with a concurrent changefeed running. With a Kafka sink,
+			// the sink's backpressure factors into the workload impact too.
+			for _, sink := range []cdcBenchSink{cdcBenchSinkNull, cdcBenchSinkKafka} {
+				sink := sink // pin loop variable
+				r.Add(registry.TestSpec{
+					Name: fmt.Sprintf(
+						"cdc/workload/kv%d/nodes=%d/cpu=%d/ranges=%s/server=scheduler/protocol=mux/format=%s/sink=%s",
+						readPercent, nodes, cpus, formatSI(ranges), format, sink),
+					Owner:            registry.OwnerCDC,
+					Benchmark:        true,
+					Cluster:          r.MakeClusterSpec(nodes+2, spec.CPU(cpus)),
+					CompatibleClouds: registry.AllExceptAWS,
+					Suites:           registry.Suites(registry.Nightly),
+					RequiresLicense:  true,
+					Timeout:          time.Hour,
+					Run: func(ctx context.Context, t test.Test, c cluster.Cluster) {
+						runCDCBenchWorkload(ctx, t, c, ranges, readPercent, format, sink)
+					},
+				})
+			}
+		}
+	}
+}
+
+// cdcBenchScanWithOptions returns the WITH options of the changefeed of a scan
+// benchmark, except for the sink-specific ones. The end time is only used
+// unless the changefeed runs a one-shot initial scan, and the cursor is only
+// used by catchup scans.
+func cdcBenchScanWithOptions(
+	scanType cdcBenchScanType,
+	format string,
+	endTime, cursor time.Time,
+	clusterOpts cdcBenchClusterOpts,
+) (string, error) {
+	with := fmt.Sprintf(`format = '%s'`, format)
+	if !clusterOpts.initialScanOnly {
+		with += fmt.Sprintf(`, end_time = '%s'`, endTime.Format(time.RFC3339))
+	}
+	switch scanType {
+	case cdcBenchInitialScan:
+		if clusterOpts.initialScanOnly {
+			with += ", initial_scan = 'only'"
+		} else {
+			with += ", initial_scan = 'yes'"
 		}
+	case cdcBenchCatchupScan, cdcBenchColdCatchupScan:
+		with += fmt.Sprintf(", cursor = '%s'", cursor.Format(time.RFC3339))
+	default:
+		return "", errors.Errorf("unknown scan type %q", scanType)
+	}
+	if freq := clusterOpts.minCheckpointFrequency; freq > 0 {
+		with += fmt.Sprintf(", min_checkpoint_frequency = '%s'", freq)
+	}
+	if clusterOpts.getColumnFamilies() > 1 {
+		with += ", split_column_families"
+	}
+	switch compression := clusterOpts.getCompression(); compression {
+	case cdcBenchCompressionNone:
+	case cdcBenchCompressionGzip:
+		with += fmt.Sprintf(", compression = '%s'", compression)
+	default:
+		return "", errors.Errorf("unknown compression %q", compression)
 	}
+	return with, nil
 }
 
+// formatSI formats the given number with an SI suffix for use in test names,
+// e.g. 100k or 1.5M. Values below 1000 are formatted exactly, and larger values
+// aren't truncated, so that distinct values never get the same name.
 func formatSI(num int64) string {
+	if num > -1000 && num < 1000 {
+		return strconv.FormatInt(num, 10)
+	}
 	numSI, suffix := humanize.ComputeSI(float64(num))
-	return fmt.Sprintf("%d%s", int64(numSI), suffix)
+	return strconv.FormatFloat(numSI, 'f', -1, 64) + suffix
 }
 
 // makeCDCBenchOptions creates common cluster options for CDC benchmarks.
-func makeCDCBenchOptions(c cluster.Cluster) (option.StartOpts, install.ClusterSettings) {
+func makeCDCBenchOptions(
+	c cluster.Cluster, clusterOpts cdcBenchClusterOpts,
+) (option.StartOpts, install.ClusterSettings) {
 	opts := option.DefaultStartOpts()
 	settings := install.MakeClusterSettings()
 	settings.ClusterSettings["kv.rangefeed.enabled"] = "true"
 
+	// Size the rangefeed scheduler pool. This is only configurable via an
+	// environment variable, and applies to each store.
+	switch clusterOpts.schedulerPool {
+	case "", cdcBenchSchedulerPoolDefault:
+	case cdcBenchSchedulerPoolPerCPU:
+		settings.Env = append(settings.Env, fmt.Sprintf(
+			"COCKROACH_RANGEFEED_SCHEDULER_WORKERS=%d", c.Spec().CPUs))
+	default:
+		panic(fmt.Sprintf("unknown scheduler pool %q", clusterOpts.schedulerPool))
+	}
+
+	// Limit the rate at which changefeeds on each node consume events. Cluster
+	// settings are applied via a double-quoted shell command, so the quotes in
+	// the JSON config must be escaped.
+	if clusterOpts.nodeByteRateLimit > 0 {
+		settings.ClusterSettings["changefeed.node_throttle_config"] = fmt.Sprintf(
+			`{\"ByteRate\": %d}`, clusterOpts.nodeByteRateLimit)
+	}
+
 	// Disable the stuck watcher, since it can cause continual catchup scans when
 	// ranges aren't able to keep up.
 	settings.ClusterSettings["kv.rangefeed.range_stuck_threshold"] = "0"
@@ -187,6 +1146,9 @@ func makeCDCBenchOptions(c cluster.Cluster) (option.StartOpts, install.ClusterSe
 	// Current default is 3s, but if needed increase this time out:
 	//    settings.ClusterSettings["kv.rangefeed.closed_timestamp_refresh_interval"] = "5s"
 	settings.ClusterSettings["changefeed.memory.per_changefeed_limit"] = "4G"
+	if clusterOpts.memoryBudget > 0 {
+		settings.ClusterSettings["changefeed.memory.per_changefeed_limit"] = fmt.Sprint(clusterOpts.memoryBudget)
+	}
 
 	// Scheduled backups may interfere with performance, disable them.
 	opts.RoachprodOpts.ScheduleBackups = false
@@ -199,6 +1161,21 @@ func makeCDCBenchOptions(c cluster.Cluster) (option.StartOpts, install.ClusterSe
 	// catchup scans.
 	settings.Env = append(settings.Env, "COCKROACH_RANGEFEED_SEND_TIMEOUT=0")
 
+	// Forward the protected timestamps of concurrent changefeeds much more
+	// often than the default of 10 minutes, so that updating them contributes
+	// to the overhead measured during the scan.
+	if clusterOpts.changefeeds > 1 {
+		settings.ClusterSettings["changefeed.protect_timestamp_interval"] = "30s"
+	}
+
+	// Size the memory budget of each rangefeed. Like the scheduler pool, this
+	// is only configurable via an environment variable, and is a fraction of
+	// the memory pool shared by all rangefeeds on the node.
+	if clusterOpts.feedMemPercent > 0 {
+		settings.Env = append(settings.Env, fmt.Sprintf(
+			"COCKROACH_RANGEFEED_TOTAL_MEM_FRACTION=%.2f", float64(clusterOpts.feedMemPercent)/100))
+	}
+
 	// If this benchmark experiences periodic changefeed restarts due to rpc errors
 	// (grpc context canceled), consider increase network timeout.
 	// Under significant load (due to rangefeed), timeout could easily be triggered
@@ -209,109 +1186,1978 @@ func makeCDCBenchOptions(c cluster.Cluster) (option.StartOpts, install.ClusterSe
 	return opts, settings
 }
 
+// envCDCBenchReuseDataset makes the scan benchmarks reuse the dataset of a
+// previous run if the cluster still holds it, to speed up repeated local runs.
+const envCDCBenchReuseDataset = "ROACHTEST_CDC_BENCH_REUSE_DATASET"
+
 // runCDCBenchScan benchmarks throughput for a changefeed initial or catchup
-// scan as rows scanned per second.
+// scan as rows scanned per second, along with the rate of bytes scanned and
+// emitted and the CPU time used.
 //
 // It sets up a cluster with N-1 data nodes, and a separate changefeed
 // coordinator node. The latter is also used as the workload runner, since we
-// don't start the coordinator until the data has been imported.
+// don't start the coordinator until the data has been imported, and runs the
+// Kafka broker when emitting to Kafka. With coordinatorSQLLoad, the
+// coordinator is started along with the data nodes and holds data too. With
+// cdcBenchSinkKafkaCRDB, the last nodes form the destination cluster, after
+// the coordinator.
 func runCDCBenchScan(
 	ctx context.Context,
 	t test.Test,
 	c cluster.Cluster,
 	scanType cdcBenchScanType,
+	schema cdcBenchSchema,
 	numRows, numRanges int64,
 	format string,
+	clusterOpts cdcBenchClusterOpts,
 ) {
-	const sink = "null://"
 	var (
-		numNodes = c.Spec().NodeCount
-		nData    = c.Range(1, numNodes-1)
-		nCoord   = c.Node(numNodes)
+		numNodes          = c.Spec().NodeCount - clusterOpts.getDestNodes()
+		nData             = c.Range(1, numNodes-1)
+		nCoord            = c.Node(numNodes)
+		nDest             option.NodeListOption
+		replicationFactor = clusterOpts.getReplicationFactor()
 	)
+	if clusterOpts.getDestNodes() > 0 {
+		nDest = c.Range(numNodes+1, c.Spec().NodeCount)
+	}
+	if clusterOpts.coordinatorSQLLoad {
+		nData = nData.Merge(nCoord)
+	}
+	if replicationFactor > len(nData) {
+		t.Fatalf("replication factor %d exceeds %d data nodes", replicationFactor, len(nData))
+	}
+	if clusterOpts.slowStoreNodes > len(nData) {
+		t.Fatalf("%d slow stores exceed %d data nodes", clusterOpts.slowStoreNodes, len(nData))
+	}
+	if clusterOpts.initialScanOnly && scanType != cdcBenchInitialScan {
+		t.Fatalf("initial_scan = 'only' is not supported for %s scans", scanType)
+	}
+	if clusterOpts.mixedHistory && scanType != cdcBenchCatchupScan {
+		t.Fatalf("mixed history is not supported for %s scans", scanType)
+	}
+	// The cloud storage sink needs credentials for its bucket, which are only
+	// present in some roachtest environments.
+	var cloudStorageCreds string
+	if clusterOpts.getSink() == cdcBenchSinkCloudStorage {
+		if cloudStorageCreds = os.Getenv(KMSGCSCredentials); cloudStorageCreds == "" {
+			t.Skip(fmt.Sprintf("%s sink requires credentials in %s", cdcBenchSinkCloudStorage, KMSGCSCredentials))
+		}
+	}
+	if clusterOpts.getSink() == cdcBenchSinkKafkaCRDB && clusterOpts.getColumnFamilies() > 1 {
+		t.Fatalf("%s sink is not supported with column families", cdcBenchSinkKafkaCRDB)
+	}
+	if !cdcBenchScanFormatSupported(format, scanType, clusterOpts.getSink()) {
+		t.Fatalf("format %s is not supported for %s scans with the %s sink", format, scanType, clusterOpts.getSink())
+	}
 
 	// Start data nodes first to place data on them. We'll start the changefeed
 	// coordinator later, since we don't want any data on it.
-	opts, settings := makeCDCBenchOptions(c)
+	opts, settings := makeCDCBenchOptions(c, clusterOpts)
+
+	// Stage the binary of the release to run on all nodes, including the
+	// coordinator. The workload still runs with the binary under test, since
+	// it's only a client.
+	version := &clusterupgrade.Version{Version: *t.BuildVersion()}
+	if v := clusterOpts.getReleaseVersion(t); v != nil {
+		t.L().Printf("running release %s", v)
+		path, err := clusterupgrade.UploadCockroach(ctx, t, t.L(), c, nData.Merge(nCoord), v)
+		require.NoError(t, err)
+		settings.Binary = path
+		version = v
+	}
 
 	c.Start(ctx, t.L(), opts, settings, nData)
-	m := c.NewMonitor(ctx, nData.Merge(nCoord))
+	m := c.NewMonitor(ctx, nData.Merge(nCoord).Merge(nDest))
 
 	conn := c.Conn(ctx, t.L(), nData[0])
 	defer conn.Close()
 
-	// Prohibit ranges on the changefeed coordinator.
-	t.L().Printf("configuring zones")
+	// Prohibit ranges on the changefeed coordinator unless it also serves
+	// queries, and set the replication factor. The workload table inherits it
+	// from the default zone.
+	t.L().Printf("configuring zones with %dx replication", replicationFactor)
+	constraints := fmt.Sprintf("[-node%d]", nCoord[0])
+	if clusterOpts.coordinatorSQLLoad {
+		constraints = "[]"
+	}
 	for _, target := range getAllZoneTargets(ctx, t, conn) {
 		_, err := conn.ExecContext(ctx, fmt.Sprintf(
-			`ALTER %s CONFIGURE ZONE USING num_replicas=3, constraints='[-node%d]'`, target, nCoord[0]))
+			`ALTER %s CONFIGURE ZONE USING num_replicas=%d, constraints='%s'`,
+			target, replicationFactor, constraints))
 		require.NoError(t, err)
 	}
 
 	// Wait for system ranges to upreplicate.
-	require.NoError(t, WaitFor3XReplication(ctx, t, t.L(), conn))
+	require.NoError(t, WaitForReplication(
+		ctx, t, t.L(), conn, replicationFactor, atLeastReplicationFactor))
 
-	// Create and split the workload table. We don't import data here, because it
-	// imports before splitting, which takes a very long time.
-	//
-	// NB: don't scatter -- the ranges end up fairly well-distributed anyway, and
-	// the scatter can often fail with 100k ranges.
-	t.L().Printf("creating table with %s ranges", humanize.Comma(numRanges))
-	c.Run(ctx, option.WithNodes(nCoord), fmt.Sprintf(
-		`./cockroach workload init kv --splits %d {pgurl:%d}`, numRanges, nData[0]))
-	require.NoError(t, WaitFor3XReplication(ctx, t, t.L(), conn))
+	// Reuse the dataset of a previous run on the same cluster if requested,
+	// instead of creating, splitting and ingesting the table again.
+	reuseDataset := false
+	if os.Getenv(envCDCBenchReuseDataset) != "" {
+		if scanType == cdcBenchCatchupScan || clusterOpts.mixedHistory || clusterOpts.underReplicated {
+			t.Fatalf("%s is not supported for this variant, since it writes data after creating the table",
+				envCDCBenchReuseDataset)
+		}
+		existing, err := getCDCBenchDataset(ctx, conn)
+		require.NoError(t, err)
+		reuseDataset, err = checkCDCBenchDataset(existing, cdcBenchDataset{
+			rows:     numRows,
+			ranges:   numRanges,
+			columns:  cdcBenchDatasetColumns(schema, clusterOpts.getColumnFamilies()),
+			families: int64(clusterOpts.getColumnFamilies()),
+			replicas: int64(replicationFactor),
+		})
+		require.NoError(t, err)
+		if reuseDataset {
+			t.L().Printf("reusing existing dataset with %s rows in %s ranges",
+				humanize.Comma(existing.rows), humanize.Comma(existing.ranges))
+		}
+	}
+
+	families := clusterOpts.getColumnFamilies()
+	var cursor time.Time
+	if !reuseDataset {
+		// Create and split the workload table. We don't import data here,
+		// because it imports before splitting, which takes a very long time.
+		//
+		// NB: don't scatter -- the ranges end up fairly well-distributed anyway,
+		// and the scatter can often fail with 100k ranges.
+		t.L().Printf("creating table with %s ranges", humanize.Comma(numRanges))
+		c.Run(ctx, option.WithNodes(nCoord), fmt.Sprintf(
+			`./cockroach workload init kv --splits %d {pgurl:%d}`, numRanges, nData[0]))
+		require.NoError(t, WaitForReplication(
+			ctx, t, t.L(), conn, replicationFactor, atLeastReplicationFactor))
+
+		// Drop the table to a single replica per range before ingesting data.
+		// It is raised to the replication factor again when the changefeed
+		// starts.
+		if clusterOpts.underReplicated {
+			t.L().Printf("reducing table to 1x replication")
+			_, err := conn.ExecContext(ctx, `ALTER TABLE kv.kv CONFIGURE ZONE USING num_replicas = 1`)
+			require.NoError(t, err)
+			_, err = cdcBenchWaitForTableReplicas(ctx, t, conn, 1)
+			require.NoError(t, err)
+		}
+
+		// Add the JSONB column while the table is still empty, to avoid a
+		// backfill.
+		switch schema {
+		case cdcBenchSchemaKV:
+		case cdcBenchSchemaJSONB:
+			t.L().Printf("adding JSONB column")
+			_, err := conn.ExecContext(ctx, cdcBenchJSONBColumnDef)
+			require.NoError(t, err)
+		default:
+			t.Fatalf("unknown schema %q", schema)
+		}
+
+		// Add the extra column families while the table is still empty too.
+		// The columns are computed from the key, so that the data loaders
+		// populate them, and are in their own families so that every row has a
+		// KV in each.
+		if families > 1 {
+			t.L().Printf("adding %d column families", families-1)
+			for i := 1; i < families; i++ {
+				_, err := conn.ExecContext(ctx, fmt.Sprintf(
+					`ALTER TABLE kv.kv ADD COLUMN f%[1]d STRING NOT VISIBLE AS (k::STRING) STORED CREATE FAMILY f%[1]d`, i))
+				require.NoError(t, err)
+			}
+		}
 
-	cursor := timeutil.Now() // before data is ingested
+		cursor = timeutil.Now() // before data is ingested
 
-	// Ingest data. init allows us to import into the existing table. However,
-	// catchup scans can't operate across an import, so use inserts in that case.
-	loader := "import"
-	if scanType == cdcBenchCatchupScan {
-		loader = "insert"
+		// Ingest data. init allows us to import into the existing table.
+		// However, catchup scans can't operate across an import, so use inserts
+		// in that case.
+		loader := "import"
+		if scanType == cdcBenchCatchupScan {
+			loader = "insert"
+		}
+		t.L().Printf("ingesting %s rows using %s", humanize.Comma(numRows), loader)
+		c.Run(ctx, option.WithNodes(nCoord), fmt.Sprintf(
+			`./cockroach workload init kv --insert-count %d --data-loader %s {pgurl:%d}`,
+			numRows, loader, nData[0]))
+
+		// Build up MVCC history on top of the ingested rows. This happens after
+		// the cursor, so the changefeed scans all of it.
+		if clusterOpts.mixedHistory {
+			cdcBenchBuildMixedHistory(ctx, t, c, nData, nCoord, numRows)
+		}
 	}
-	t.L().Printf("ingesting %s rows using %s", humanize.Comma(numRows), loader)
-	c.Run(ctx, option.WithNodes(nCoord), fmt.Sprintf(
-		`./cockroach workload init kv --insert-count %d --data-loader %s {pgurl:%d}`,
-		numRows, loader, nData[0]))
 
-	// Now that the ranges are placed, start the changefeed coordinator.
-	t.L().Printf("starting coordinator node")
-	c.Start(ctx, t.L(), opts, settings, nCoord)
+	// Now that the ranges are placed, start the changefeed coordinator. The
+	// CPU limit only applies to the coordinator, so give it its own copy of the
+	// environment. GOMAXPROCS limits the number of threads running Go code at
+	// once, which is also how the runtime sizes itself under a cgroup quota.
+	if !clusterOpts.coordinatorSQLLoad {
+		t.L().Printf("starting coordinator node")
+		coordSettings := settings
+		if cpus := clusterOpts.coordinatorCPUs; cpus > 0 {
+			t.L().Printf("limiting coordinator to %d CPUs", cpus)
+			coordSettings.Env = append(append([]string(nil), settings.Env...), fmt.Sprintf("GOMAXPROCS=%d", cpus))
+		}
+		c.Start(ctx, t.L(), opts, coordSettings, nCoord)
+	}
 
 	conn = c.Conn(ctx, t.L(), nCoord[0])
 	defer conn.Close()
 
+	// Make sure that the zone configs kept the data off the coordinator, since
+	// the results would otherwise be skewed by the coordinator's own work.
+	if !clusterOpts.coordinatorSQLLoad {
+		ranges, err := getRangeReplicas(ctx, conn)
+		require.NoError(t, err)
+		require.NoError(t, assertNoRangesOnNode(ranges, nCoord[0]))
+	}
+
 	if scanType == cdcBenchColdCatchupScan {
 		cursor = timeutil.Now() // after data is ingested
 	}
 
+	// Limit the read bandwidth of the slow stores now that the data has been
+	// ingested, so that only the scan is affected.
+	if n := clusterOpts.slowStoreNodes; n > 0 {
+		nSlow := nData[len(nData)-n:]
+		t.L().Printf("limiting store read bandwidth on nodes %v to %s/s",
+			nSlow, humanize.IBytes(cdcBenchSlowStoreReadBandwidth))
+		staller := &cgroupDiskStaller{t: t, c: c}
+		require.NoError(t, staller.setThroughput(ctx, nSlow, readBandwidth,
+			throughput{limited: true, bytesPerSecond: cdcBenchSlowStoreReadBandwidth}))
+	}
+
+	var releaseCompactions func()
+	if clusterOpts.compactionPressure {
+		releaseCompactions = cdcBenchBuildCompactionBacklog(ctx, t, c, m, nData, nCoord)
+	}
+
 	// Start the scan on the changefeed coordinator. We set an explicit end time
 	// in the near future, and compute throughput based on the job's start and
-	// finish time.
+	// finish time. One-shot initial scans finish on their own once the scan
+	// completes, so they don't need an end time.
 	t.L().Printf("running changefeed %s scan", scanType)
-	with := fmt.Sprintf(`format = '%s', end_time = '%s'`,
-		format, timeutil.Now().Add(5*time.Second).Format(time.RFC3339))
-	switch scanType {
-	case cdcBenchInitialScan:
-		with += ", initial_scan = 'yes'"
-	case cdcBenchCatchupScan, cdcBenchColdCatchupScan:
-		with += fmt.Sprintf(", cursor = '%s'", cursor.Format(time.RFC3339))
+	with, err := cdcBenchScanWithOptions(
+		scanType, format, timeutil.Now().Add(5*time.Second), cursor, clusterOpts)
+	require.NoError(t, err)
+
+	// Avro requires a schema registry for every sink. It runs on the
+	// coordinator, with the Kafka broker of the Kafka sinks.
+	schemaRegistry := format == cdcBenchFormatAvro
+	var schemaRegistryURL string
+	var sink string
+	var replicationKafka kafkaManager
+	var destConn *gosql.DB
+	switch clusterOpts.getSink() {
+	case cdcBenchSinkNull:
+		sink = "null://"
+	case cdcBenchSinkKafka, cdcBenchSinkKafkaTLS:
+		sink, schemaRegistryURL = setupCDCBenchKafkaSink(
+			ctx, t, c, nCoord, clusterOpts.getSink() == cdcBenchSinkKafkaTLS, schemaRegistry)
+		with += fmt.Sprintf(", kafka_sink_config = '%s'", cdcBenchKafkaSinkConfig)
+	case cdcBenchSinkKafkaCRDB:
+		replicationKafka, destConn = setupCDCBenchReplication(ctx, t, c, nCoord, nDest)
+		defer destConn.Close()
+		sink = replicationKafka.sinkURL(ctx)
+		with += fmt.Sprintf(", kafka_sink_config = '%s'", cdcBenchKafkaSinkConfig)
+	case cdcBenchSinkLocalFile:
+		// Each node writes the files of its own aggregators, so that the files
+		// are written to the local disk rather than sent to another node.
+		sink = "nodelocal://self/" + cdcBenchLocalFileSinkDir
+	case cdcBenchSinkCloudStorage:
+		// The credentials are a JSON key file, which GCS URIs take base64
+		// encoded.
+		params := url.Values{}
+		params.Set(cloudstorage.AuthParam, cloudstorage.AuthParamSpecified)
+		params.Set(gcp.CredentialsParam, base64.StdEncoding.EncodeToString([]byte(cloudStorageCreds)))
+		sink = fmt.Sprintf("gs://%s/roachtest/cdc-bench/%s/%s?%s", cdcBenchCloudStorageSinkBucket,
+			c.Name(), timeutil.Now().Format(`20060102150405`), params.Encode())
 	default:
-		t.Fatalf("unknown scan type %q", scanType)
+		t.Fatalf("unknown sink %q", clusterOpts.getSink())
+	}
+	if schemaRegistry {
+		if schemaRegistryURL == "" {
+			schemaRegistryURL = setupCDCBenchSchemaRegistry(ctx, t, c, nCoord)
+		}
+		with += fmt.Sprintf(", confluent_schema_registry = '%s'", schemaRegistryURL)
+	}
+
+	// Lock schema so that changefeed schema feed runs under fast path.
+	_, err = conn.ExecContext(ctx, "ALTER TABLE kv.kv  SET (schema_locked = true);")
+	require.NoError(t, err)
+
+	// Measure the duration of a plain full table scan, which reads the same KVs
+	// as the changefeed. It aggregates the value column so that the values are
+	// read and decoded, but not encoded or emitted. The scan also warms the
+	// block cache, which benefits the changefeed, so the measured overhead is a
+	// lower bound.
+	var kvScanDuration time.Duration
+	if clusterOpts.kvScanBaseline {
+		t.L().Printf("running baseline kv scan")
+		start := timeutil.Now()
+		var valueBytes int64
+		require.NoError(t, conn.QueryRowContext(ctx,
+			`SELECT sum(length(v)) FROM kv.kv`).Scan(&valueBytes))
+		kvScanDuration = timeutil.Since(start)
+		t.L().Printf("kv scan completed in %s (scanned %s rows per second)",
+			kvScanDuration.Truncate(time.Second),
+			humanize.Comma(int64(float64(numRows)/kvScanDuration.Seconds())))
+	}
+
+	// Snapshot the bytes loaded by rangefeed iterators on the data nodes, to
+	// compute the amount of data scanned by the changefeed.
+	scanBytesBefore := cdcBenchRangefeedBlockBytes(ctx, t, c, nData)
+	nodeScanBytesBefore := make([]int64, len(nData))
+	for i, node := range nData {
+		nodeScanBytesBefore[i] = cdcBenchRangefeedBlockBytes(ctx, t, c, c.Node(node))
+	}
+	bufferBytesBefore := cdcBenchNodeMetricSum(ctx, t, c, nData, "changefeed.buffer_entries_mem.acquired")
+	budgetBlockedBefore := cdcBenchNodeMetricSum(ctx, t, c, nData, "kv.rangefeed.budget_allocation_blocked")
+	netSendBytesBefore := cdcBenchNodeMetricSum(ctx, t, c, nData, "sys.host.net.send.bytes")
+	emittedBytesBefore := cdcBenchNodeMetricSum(ctx, t, c, nData.Merge(nCoord), "changefeed.emitted_bytes")
+	cloudWriteBytesBefore := cdcBenchNodeMetricSum(ctx, t, c, nData.Merge(nCoord), "cloud.write_bytes")
+	emittedMessagesBefore := cdcBenchNodeMetricSum(ctx, t, c, nData.Merge(nCoord), "changefeed.emitted_messages")
+	cpuNanosBefore := cdcBenchCPUNanos(ctx, t, c, nData.Merge(nCoord))
+	nodeCPUNanosBefore := cdcBenchNodeCPUNanos(ctx, t, c, nData.Merge(nCoord))
+	cpuWindowStart := timeutil.Now()
+	checkpointsBefore := cdcBenchNodeMetricSum(ctx, t, c, nData.Merge(nCoord), "changefeed.checkpoint_hist_nanos-count")
+	checkpointNanosBefore := cdcBenchNodeMetricSum(ctx, t, c, nData.Merge(nCoord), "changefeed.checkpoint_hist_nanos-sum")
+	flushesBefore := cdcBenchNodeMetricSum(ctx, t, c, nData.Merge(nCoord), "changefeed.flushes")
+	pushbackNanosBefore := cdcBenchNodeMetricSum(ctx, t, c, nData.Merge(nCoord), "changefeed.buffer_pushback_nanos")
+	compactionsBefore := cdcBenchNodeMetricSum(ctx, t, c, nData, "rocksdb.compactions")
+	compactionDebt := cdcBenchNodeMetricSum(ctx, t, c, nData, "rocksdb.estimated-pending-compaction")
+	t.L().Printf("estimated compaction debt on data nodes: %s", humanize.IBytes(uint64(compactionDebt)))
+
+	// Count the live rows and versions that the scan will read. This only
+	// changes with mixed history, where the workload writes and deletes rows.
+	var liveRows, versions int64
+	if clusterOpts.mixedHistory {
+		liveRows, versions = cdcBenchTableVersions(ctx, t, conn)
+		t.L().Printf("table has %s live rows and %s versions",
+			humanize.Comma(liveRows), humanize.Comma(versions))
+	}
+
+	// Record the number and size of the ranges that the scan will read. Ranges
+	// may split beyond the requested count as data is ingested, in which case
+	// the sweep doesn't hold the range count that it names.
+	var tableRanges, tableBytes int64
+	if clusterOpts.rangeGranularity {
+		tableRanges, tableBytes = cdcBenchTableLayout(ctx, t, conn)
+		t.L().Printf("table has %s ranges with %s of data",
+			humanize.Comma(tableRanges), humanize.IBytes(uint64(tableBytes)))
+		if tableRanges != numRanges {
+			t.L().Printf("WARNING: table has %s ranges rather than the requested %s",
+				humanize.Comma(tableRanges), humanize.Comma(numRanges))
+		}
+	}
+
+	// Create the query workload's table before the scan, so that only the
+	// queries themselves run concurrently with it.
+	if clusterOpts.coordinatorSQLLoad {
+		c.Run(ctx, option.WithNodes(nCoord), fmt.Sprintf(
+			`./cockroach workload init kv --db %s {pgurl:%d}`, cdcBenchQueriesDB, nCoord[0]))
+	}
+
+	// Raise the table to the replication factor without waiting for it, so
+	// that the ranges upreplicate while the changefeed scans them.
+	if clusterOpts.underReplicated {
+		t.L().Printf("raising table to %dx replication", replicationFactor)
+		_, err := conn.ExecContext(ctx, fmt.Sprintf(
+			`ALTER TABLE kv.kv CONFIGURE ZONE USING num_replicas = %d`, replicationFactor))
+		require.NoError(t, err)
+	}
+
+	changefeeds := clusterOpts.getChangefeeds()
+	reconciledBefore := cdcBenchNodeMetricSum(ctx, t, c, nData.Merge(nCoord), "kv.protectedts.reconciliation.records_processed")
+	createStmt := fmt.Sprintf(`CREATE CHANGEFEED FOR kv.kv INTO '%s' WITH %s`, sink, with)
+	jobIDs := make([]int, changefeeds)
+	var connectDurations, createDurations []time.Duration
+	if clusterOpts.separateConnections {
+		var closeConns func()
+		jobIDs, connectDurations, createDurations, closeConns = cdcBenchCreateChangefeedsConcurrently(
+			ctx, t, c, nCoord, createStmt, changefeeds)
+		defer closeConns()
+	} else {
+		for i := range jobIDs {
+			require.NoError(t, conn.QueryRowContext(ctx, createStmt).Scan(&jobIDs[i]))
+		}
+	}
+
+	// Every changefeed writes a protected timestamp record when it's created.
+	var ptsRecords int64
+	require.NoError(t, conn.QueryRowContext(ctx,
+		`SELECT count(*) FROM system.protected_ts_records`).Scan(&ptsRecords))
+	t.L().Printf("created %d changefeeds with %d protected timestamp records", changefeeds, ptsRecords)
+
+	// Sample goroutine counts and rangefeed memory usage on the data nodes
+	// while the changefeed runs, to track the resource usage of rangefeed
+	// processing.
+	stopSampling := cdcBenchSampleNodeMetric(ctx, t, c, nData, "sys.goroutines")
+	stopMemSampling := cdcBenchSampleNodeMetric(ctx, t, c, nData, "kv.rangefeed.mem_shared")
+	stopPTSSampling := cdcBenchSampleNodeMetric(ctx, t, c, nData, "spanconfig.kvsubscriber.protected_record_count")
+	stopConnSampling := cdcBenchSampleNodeMetric(ctx, t, c, nCoord, "sql.conns")
+
+	// Wait for the changefeeds to complete, and compute throughput across all
+	// of them, from the first start to the last finish.
+	var scanRate int64
+	var scanDuration time.Duration
+	changefeedRates := make([]int64, changefeeds)
+	scanDone := make(chan struct{})
+	m.Go(func(ctx context.Context) error {
+		defer close(scanDone)
+		// The job succeeds once its frontier reaches the end time, or with
+		// initial_scan = 'only', once the initial scan completes.
+		t.L().Printf("waiting for changefeed to finish")
+		var startedTime, finishedTime time.Time
+		for i, jobID := range jobIDs {
+			// Fail with a clear error rather than a test timeout if the scan stops
+			// making progress.
+			stall := cdcBenchStallDetector{timeout: cdcBenchScanStallTimeout}
+			var runningStatus string
+			info, err := waitForChangefeed(ctx, conn, jobID, t.L(), func(info changefeedInfo) (bool, error) {
+				if info.runningStatus != runningStatus {
+					t.L().Printf("changefeed %d running status changed from %q to %q",
+						jobID, runningStatus, info.runningStatus)
+					runningStatus = info.runningStatus
+				}
+				switch jobs.Status(info.status) {
+				case jobs.StatusSucceeded:
+					return true, nil
+				case jobs.StatusPending, jobs.StatusRunning:
+					t.L().Printf("changefeed %d scanned %.1f%%", jobID, info.fractionCompleted*100)
+					return false, stall.observe(timeutil.Now(), info)
+				default:
+					return false, errors.Errorf("unexpected changefeed status %q", info.status)
+				}
+			})
+			if err != nil {
+				return err
+			}
+			// Cold catchup scans don't emit any rows, so make sure that the
+			// changefeed actually advanced past the cursor rather than only
+			// succeeding.
+			if scanType == cdcBenchColdCatchupScan {
+				if _, err := highWaterAtLeast(cursor)(info); err != nil {
+					return err
+				}
+			}
+			if startedTime.IsZero() || info.startedTime.Before(startedTime) {
+				startedTime = info.startedTime
+			}
+			if info.finishedTime.After(finishedTime) {
+				finishedTime = info.finishedTime
+			}
+			changefeedRates[i] = int64(float64(numRows) / info.finishedTime.Sub(info.startedTime).Seconds())
+		}
+
+		duration := finishedTime.Sub(startedTime)
+		rate := int64(float64(numRows*int64(changefeeds)) / duration.Seconds())
+		t.L().Printf("changefeed completed in %s (scanned %s rows per second)",
+			duration.Truncate(time.Second), humanize.Comma(rate))
+
+		scanRate, scanDuration = rate, duration
+		return nil
+	})
+
+	// Restore the compaction concurrency once the changefeed completes, which
+	// also lets the monitor finish.
+	if releaseCompactions != nil {
+		m.Go(func(context.Context) error {
+			<-scanDone
+			releaseCompactions()
+			return nil
+		})
+	}
+
+	// Run queries on the coordinator until the changefeed completes, sampling
+	// their latency.
+	var queryP99 time.Duration
+	if clusterOpts.coordinatorSQLLoad {
+		m.Go(func(ctx context.Context) error {
+			var err error
+			queryP99, err = cdcBenchRunCoordinatorQueries(ctx, t, c, nCoord, scanDone)
+			return err
+		})
+	}
+
+	// Import into a separate table while the changefeed runs. The import may
+	// outlast the scan, in which case the monitor waits for it to finish.
+	var importDuration time.Duration
+	if clusterOpts.concurrentImport {
+		m.Go(func(ctx context.Context) error {
+			var err error
+			importDuration, err = cdcBenchImportDuringScan(ctx, t, c, nData, nCoord)
+			return err
+		})
+	}
+
+	// Keep splitting the table until the changefeed completes, sampling the
+	// scan rate as the range count grows.
+	var splitSamples []cdcBenchSplitSample
+	if splits := clusterOpts.incrementalSplits; splits > 0 {
+		m.Go(func(ctx context.Context) error {
+			var err error
+			splitSamples, err = cdcBenchSplitDuringScan(ctx, t, c, conn, nData, splits, scanDone)
+			return err
+		})
+	}
+
+	// Wait for the table to upreplicate, which may outlast the scan.
+	var underReplicatedRanges int64
+	var upreplicationDuration time.Duration
+	if clusterOpts.underReplicated {
+		m.Go(func(ctx context.Context) error {
+			start := timeutil.Now()
+			var err error
+			underReplicatedRanges, err = cdcBenchWaitForTableReplicas(ctx, t, conn, replicationFactor)
+			upreplicationDuration = timeutil.Since(start)
+			return err
+		})
+	}
+
+	// Apply the emitted rows to the destination cluster, which lags behind the
+	// changefeed, until all of them have been applied.
+	var replication cdcBenchReplicationResult
+	if clusterOpts.getSink() == cdcBenchSinkKafkaCRDB {
+		m.Go(func(ctx context.Context) error {
+			var err error
+			replication, err = cdcBenchApplyReplicatedRows(ctx, t, replicationKafka, destConn, numRows, scanDone)
+			return err
+		})
+	}
+
+	m.Wait()
+
+	peakGoroutines := stopSampling()
+	t.L().Printf("peak goroutines on data nodes: %s", humanize.Comma(peakGoroutines))
+	peakRangefeedMem := stopMemSampling()
+	t.L().Printf("peak rangefeed memory on data nodes: %s", humanize.IBytes(uint64(peakRangefeedMem)))
+	peakPTSRecords := stopPTSSampling()
+	peakConns := stopConnSampling()
+
+	// This only includes the CPU time of the CockroachDB processes, and not the
+	// Kafka broker on the coordinator.
+	cpuSeconds := (cdcBenchCPUNanos(ctx, t, c, nData.Merge(nCoord)) - cpuNanosBefore) / int64(time.Second)
+	t.L().Printf("changefeed used %s CPU seconds across all nodes", humanize.Comma(cpuSeconds))
+
+	// Record the CPU utilization of the busiest node along with the mean across
+	// nodes, so that a single node bottlenecking the scan stands out.
+	maxNodeCPU, meanNodeCPU := cdcBenchNodeCPUUtilization(nodeCPUNanosBefore,
+		cdcBenchNodeCPUNanos(ctx, t, c, nData.Merge(nCoord)), timeutil.Since(cpuWindowStart), clusterOpts.getCPUs())
+	t.L().Printf("node CPU utilization during the scan: max %d%%, mean %d%%", maxNodeCPU, meanNodeCPU)
+
+	scanBytes := cdcBenchRangefeedBlockBytes(ctx, t, c, nData) - scanBytesBefore
+	scanByteRate := int64(float64(scanBytes) / scanDuration.Seconds())
+	t.L().Printf("changefeed scanned %s (%s/s)",
+		humanize.IBytes(uint64(scanBytes)), humanize.IBytes(uint64(scanByteRate)))
+
+	// Record scan rate, scanned data and peak goroutines to stats.json. The stats
+	// are recorded as durations in seconds, so record the scanned data in MB to
+	// avoid overflow.
+	stats := map[string]int64{
+		"release-version":    cdcBenchVersionTag(version),
+		"scan-rate":          scanRate,
+		"scan-bytes-mb":      scanBytes / (1 << 20),
+		"scan-byte-rate-mb":  scanByteRate / (1 << 20),
+		"peak-goroutines":    peakGoroutines,
+		"peak-rangefeed-mb":  peakRangefeedMem / (1 << 20),
+		"replication-factor": int64(replicationFactor),
+		"cpu-seconds":        cpuSeconds,
+		"node-cpu-max-pct":   maxNodeCPU,
+		"node-cpu-mean-pct":  meanNodeCPU,
+	}
+
+	// Record the rate at which encoded rows were emitted, which includes the
+	// encoding cost. Cold catchup scans don't emit or encode any rows, so only
+	// the scan rate is meaningful for them.
+	if scanType != cdcBenchColdCatchupScan {
+		// Make sure that every changefeed emitted all the rows, so that the rates
+		// don't cover a partial scan. The job record doesn't track the emitted
+		// messages, so they're taken from the node metrics, which count the
+		// messages of all changefeeds. Each column family of a row is emitted as a
+		// separate message, and with mixed history, every version of a row is
+		// emitted, which the MVCC stats count per column family too. Messages may
+		// be emitted more than once, so this is a lower bound.
+		emittedMessages := cdcBenchNodeMetricSum(ctx, t, c, nData.Merge(nCoord), "changefeed.emitted_messages") -
+			emittedMessagesBefore
+		expectedMessages := numRows * int64(families)
+		if clusterOpts.mixedHistory {
+			expectedMessages = versions
+		}
+		expectedMessages *= int64(changefeeds)
+		if emittedMessages < expectedMessages {
+			t.Fatalf("changefeeds emitted %s messages, expected at least %s",
+				humanize.Comma(emittedMessages), humanize.Comma(expectedMessages))
+		}
+		emitRate := int64(float64(emittedMessages) / scanDuration.Seconds())
+		t.L().Printf("changefeed emitted %s messages (%s per second)",
+			humanize.Comma(emittedMessages), humanize.Comma(emitRate))
+		stats["emit-rate"] = emitRate
+
+		emittedBytes := cdcBenchNodeMetricSum(ctx, t, c, nData.Merge(nCoord), "changefeed.emitted_bytes") -
+			emittedBytesBefore
+		emitByteRate := int64(float64(emittedBytes) / scanDuration.Seconds())
+		t.L().Printf("changefeed emitted %s (%s/s)",
+			humanize.IBytes(uint64(emittedBytes)), humanize.IBytes(uint64(emitByteRate)))
+		stats["emitted-bytes-mb"] = emittedBytes / (1 << 20)
+		stats["emit-byte-rate-mb"] = emitByteRate / (1 << 20)
+	}
+
+	// Record the amount of data written to disk by the local file sink, and
+	// remove the files afterwards, since they take up as much space as the
+	// emitted rows.
+	if clusterOpts.getSink() == cdcBenchSinkLocalFile {
+		fileBytes := cdcBenchLocalFileSinkBytes(ctx, t, c, nData.Merge(nCoord))
+		fileByteRate := int64(float64(fileBytes) / scanDuration.Seconds())
+		t.L().Printf("changefeed wrote %s to local files (%s/s)",
+			humanize.IBytes(uint64(fileBytes)), humanize.IBytes(uint64(fileByteRate)))
+		stats["file-bytes-mb"] = fileBytes / (1 << 20)
+		stats["file-byte-rate-mb"] = fileByteRate / (1 << 20)
+		c.Run(ctx, option.WithNodes(nData.Merge(nCoord)),
+			"rm -rf {store-dir}/extern/"+cdcBenchLocalFileSinkDir)
+	}
+
+	// Record the amount of data written to the bucket by the cloud storage sink.
+	// The scan rate is the rate of rows written to it already.
+	if clusterOpts.getSink() == cdcBenchSinkCloudStorage {
+		cloudBytes := cdcBenchNodeMetricSum(ctx, t, c, nData.Merge(nCoord), "cloud.write_bytes") -
+			cloudWriteBytesBefore
+		cloudByteRate := int64(float64(cloudBytes) / scanDuration.Seconds())
+		t.L().Printf("changefeed wrote %s to cloud storage (%s/s)",
+			humanize.IBytes(uint64(cloudBytes)), humanize.IBytes(uint64(cloudByteRate)))
+		stats["cloud-bytes-mb"] = cloudBytes / (1 << 20)
+		stats["cloud-byte-rate-mb"] = cloudByteRate / (1 << 20)
+	}
+
+	// With replication into a second cluster, the scan rate is the rate of the
+	// source side. Also record the rate at which the consumer applied rows to
+	// the destination cluster, and how long it took to apply the last row once
+	// the changefeed completed. Rows may be emitted more than once, so the
+	// destination may have fewer rows than were applied.
+	if clusterOpts.getSink() == cdcBenchSinkKafkaCRDB {
+		var destRows int64
+		require.NoError(t, destConn.QueryRowContext(ctx, `SELECT count(*) FROM kv.kv`).Scan(&destRows))
+		applyRate := int64(float64(replication.applied) / replication.duration.Seconds())
+		t.L().Printf("consumer applied %s rows in %s (%s rows per second), %s after the changefeed completed",
+			humanize.Comma(replication.applied), replication.duration.Truncate(time.Second),
+			humanize.Comma(applyRate), replication.lag.Truncate(time.Second))
+		if destRows != numRows {
+			t.L().Printf("WARNING: destination cluster has %s rows rather than %s",
+				humanize.Comma(destRows), humanize.Comma(numRows))
+		}
+		stats["replication-apply-rate"] = applyRate
+		stats["replication-applied-rows"] = replication.applied
+		stats["replication-dest-rows"] = destRows
+		stats["replication-lag-s"] = int64(replication.lag / time.Second)
+	}
+
+	// Record the number of times the changefeed checkpointed its progress, and
+	// the total time spent doing so.
+	checkpoints := cdcBenchNodeMetricSum(ctx, t, c, nData.Merge(nCoord), "changefeed.checkpoint_hist_nanos-count") -
+		checkpointsBefore
+	checkpointNanos := cdcBenchNodeMetricSum(ctx, t, c, nData.Merge(nCoord), "changefeed.checkpoint_hist_nanos-sum") -
+		checkpointNanosBefore
+	t.L().Printf("changefeed wrote %d checkpoints in %s",
+		checkpoints, time.Duration(checkpointNanos).Truncate(time.Millisecond))
+	stats["checkpoint-writes"] = checkpoints
+	stats["checkpoint-time-ms"] = checkpointNanos / int64(time.Millisecond)
+	if freq := clusterOpts.minCheckpointFrequency; freq > 0 {
+		stats["min-checkpoint-frequency-s"] = int64(freq / time.Second)
+	}
+	if cpus := clusterOpts.coordinatorCPUs; cpus > 0 {
+		stats["coordinator-cpus"] = int64(cpus)
+	}
+	if clusterOpts.initialScanOnly {
+		stats["initial-scan-only"] = 1
+	}
+
+	// Every row emits an event per column family, so record the event rate
+	// too when there are several of them.
+	if families > 1 {
+		stats["column-families"] = int64(families)
+		stats["event-rate"] = scanRate * int64(families)
+	}
+
+	// Record the compaction debt at the start of the scan, and the number of
+	// compactions that ran during it, to compare runs with and without
+	// compaction pressure.
+	compactions := cdcBenchNodeMetricSum(ctx, t, c, nData, "rocksdb.compactions") - compactionsBefore
+	t.L().Printf("data nodes ran %s compactions during the scan", humanize.Comma(compactions))
+	stats["compaction-debt-mb"] = compactionDebt / (1 << 20)
+	stats["compactions"] = compactions
+
+	if clusterOpts.coordinatorSQLLoad {
+		t.L().Printf("queries on the coordinator had a p99 latency of up to %s", queryP99)
+		stats["query-p99-ms"] = queryP99.Milliseconds()
+	}
+
+	// Record the import rate, to see how the scan affects the import in turn.
+	if clusterOpts.concurrentImport {
+		importRate := int64(float64(cdcBenchConcurrentImportRows) / importDuration.Seconds())
+		t.L().Printf("concurrent import completed in %s (imported %s rows per second)",
+			importDuration.Truncate(time.Second), humanize.Comma(importRate))
+		stats["import-rate"] = importRate
+		stats["import-duration-s"] = int64(importDuration / time.Second)
+	}
+
+	// With a custom memory budget, record it along with the number of times
+	// rangefeeds had to wait for memory to buffer events, which happens when
+	// the changefeed falls behind and the budgets are used up.
+	if clusterOpts.feedMemPercent > 0 {
+		budgetBlocked := cdcBenchNodeMetricSum(ctx, t, c, nData, "kv.rangefeed.budget_allocation_blocked") -
+			budgetBlockedBefore
+		t.L().Printf("rangefeeds blocked on memory budget %s times with a budget of %d%% of the pool",
+			humanize.Comma(budgetBlocked), clusterOpts.feedMemPercent)
+		stats["feed-mem-pct"] = int64(clusterOpts.feedMemPercent)
+		stats["budget-blocked"] = budgetBlocked
+	}
+
+	// In multi-region clusters, record the amount of data that the data nodes
+	// sent over the network during the scan, most of which is rangefeed
+	// traffic, as an upper bound of the egress across regions.
+	if clusterOpts.multiRegion {
+		netSendBytes := cdcBenchNodeMetricSum(ctx, t, c, nData, "sys.host.net.send.bytes") - netSendBytesBefore
+		t.L().Printf("data nodes sent %s over the network", humanize.IBytes(uint64(netSendBytes)))
+		stats["net-send-bytes-mb"] = netSendBytes / (1 << 20)
+	}
+
+	// With mixed history, the scan reads every version rather than a single
+	// one per row, so also record the rate at which versions were scanned,
+	// and the ratio of live rows to versions. Stats are integers, so record
+	// the ratio per thousand versions.
+	if clusterOpts.mixedHistory && versions > 0 {
+		versionRate := int64(float64(versions) / scanDuration.Seconds())
+		t.L().Printf("changefeed scanned %s versions per second", humanize.Comma(versionRate))
+		stats["live-rows"] = liveRows
+		stats["versions"] = versions
+		stats["version-scan-rate"] = versionRate
+		stats["live-rows-per-1k-versions"] = liveRows * 1000 / versions
+	}
+
+	// With the range granularity sweep, record the layout of the table, so
+	// that the scan rates of the range counts can be compared per range.
+	if clusterOpts.rangeGranularity && tableRanges > 0 {
+		stats["ranges"] = tableRanges
+		stats["rows-per-range"] = numRows / tableRanges
+		stats["mean-range-size-kb"] = tableBytes / tableRanges / (1 << 10)
+	}
+
+	// With several changefeeds, the scan rate is the total across all of
+	// them, so also record the rate of each. Record the protected timestamp
+	// records that they wrote, the peak number of records that the data nodes
+	// applied to their span configs, and the number of records that the
+	// reconciler processed, to track the cost of the bookkeeping.
+	if changefeeds > 1 {
+		reconciled := cdcBenchNodeMetricSum(ctx, t, c, nData.Merge(nCoord), "kv.protectedts.reconciliation.records_processed") -
+			reconciledBefore
+		t.L().Printf("%d changefeeds scanned %s rows per second each, reconciler processed %s protected timestamp records",
+			changefeeds, humanize.Comma(scanRate/int64(changefeeds)), humanize.Comma(reconciled))
+		stats["changefeeds"] = int64(changefeeds)
+		stats["changefeed-scan-rate"] = scanRate / int64(changefeeds)
+		stats["protected-timestamp-records"] = ptsRecords
+		stats["peak-protected-records"] = peakPTSRecords
+		stats["pts-records-reconciled"] = reconciled
+	}
+
+	// With changefeeds created from separate connections, record how long it
+	// took to open the connections and to create the changefeeds, the peak
+	// number of SQL connections on the coordinator, and how evenly the
+	// changefeeds progressed, as the spread of their individual scan rates.
+	if clusterOpts.separateConnections {
+		var minRate, maxRate, sumRate int64
+		for i, rate := range changefeedRates {
+			if i == 0 || rate < minRate {
+				minRate = rate
+			}
+			if rate > maxRate {
+				maxRate = rate
+			}
+			sumRate += rate
+		}
+		meanRate := float64(sumRate) / float64(len(changefeedRates))
+		var variance float64
+		for _, rate := range changefeedRates {
+			variance += (float64(rate) - meanRate) * (float64(rate) - meanRate)
+		}
+		stddevRate := int64(math.Sqrt(variance / float64(len(changefeedRates))))
+		var maxConnect, maxCreate, sumCreate time.Duration
+		for i := range createDurations {
+			if connectDurations[i] > maxConnect {
+				maxConnect = connectDurations[i]
+			}
+			if createDurations[i] > maxCreate {
+				maxCreate = createDurations[i]
+			}
+			sumCreate += createDurations[i]
+		}
+		meanCreate := sumCreate / time.Duration(len(createDurations))
+		t.L().Printf("%d connections connected in up to %s and created changefeeds in %s on average (up to %s), "+
+			"changefeeds scanned %s to %s rows per second (stddev %s), with up to %d SQL connections",
+			len(createDurations), maxConnect, meanCreate, maxCreate,
+			humanize.Comma(minRate), humanize.Comma(maxRate), humanize.Comma(stddevRate), peakConns)
+		stats["connections"] = int64(len(createDurations))
+		stats["peak-sql-conns"] = peakConns
+		stats["connect-max-ms"] = maxConnect.Milliseconds()
+		stats["create-changefeed-mean-ms"] = meanCreate.Milliseconds()
+		stats["create-changefeed-max-ms"] = maxCreate.Milliseconds()
+		stats["changefeed-scan-rate-min"] = minRate
+		stats["changefeed-scan-rate-max"] = maxRate
+		stats["changefeed-scan-rate-stddev"] = stddevRate
+	}
+
+	// With a custom memory budget, record the number of times the changefeed
+	// flushed the sink, and the time that it spent waiting for memory to
+	// buffer events, to compare the cost of smaller budgets.
+	if budget := clusterOpts.memoryBudget; budget > 0 {
+		flushes := cdcBenchNodeMetricSum(ctx, t, c, nData.Merge(nCoord), "changefeed.flushes") - flushesBefore
+		pushbackNanos := cdcBenchNodeMetricSum(ctx, t, c, nData.Merge(nCoord), "changefeed.buffer_pushback_nanos") -
+			pushbackNanosBefore
+		t.L().Printf("changefeed flushed %s times and waited %s for memory with a budget of %s",
+			humanize.Comma(flushes), time.Duration(pushbackNanos).Truncate(time.Millisecond),
+			humanize.IBytes(uint64(budget)))
+		stats["memory-budget-mb"] = budget / (1 << 20)
+		stats["flushes"] = flushes
+		stats["flush-rate"] = int64(float64(flushes) / scanDuration.Seconds())
+		stats["memory-pushback-ms"] = pushbackNanos / int64(time.Millisecond)
+	}
+
+	// When the table started out under-replicated, the scan rate was measured
+	// while it upreplicated. Record how many of its ranges were
+	// under-replicated when the changefeed started, and how long they took to
+	// upreplicate compared to the scan.
+	if clusterOpts.underReplicated {
+		t.L().Printf("%s ranges upreplicated in %s, changefeed completed in %s",
+			humanize.Comma(underReplicatedRanges), upreplicationDuration.Truncate(time.Second),
+			scanDuration.Truncate(time.Second))
+		stats["under-replicated-ranges"] = underReplicatedRanges
+		stats["upreplication-s"] = int64(upreplicationDuration / time.Second)
+		stats["scan-duration-s"] = int64(scanDuration / time.Second)
+	}
+
+	// Record the baseline KV scan rate, and the changefeed's scan duration
+	// relative to it as a percentage. A ratio of 100% means that the rangefeed
+	// machinery adds no overhead on top of reading the data.
+	if clusterOpts.kvScanBaseline {
+		stats["kv-scan-rate"] = int64(float64(numRows) / kvScanDuration.Seconds())
+		overhead := int64(100 * scanDuration.Seconds() / kvScanDuration.Seconds())
+		t.L().Printf("changefeed scan took %d%% of the kv scan duration", overhead)
+		stats["kv-scan-overhead-pct"] = overhead
+	}
+
+	// With slow stores, record the scan rate of every data node, since the scan
+	// can only complete once the slowest node has scanned its ranges. The rates
+	// are averaged over the entire scan, so nodes that finish early have lower
+	// rates than they actually scanned at.
+	if clusterOpts.slowStoreNodes > 0 {
+		stats["slow-stores"] = int64(clusterOpts.slowStoreNodes)
+		for i, node := range nData {
+			nodeScanBytes := cdcBenchRangefeedBlockBytes(ctx, t, c, c.Node(node)) - nodeScanBytesBefore[i]
+			nodeScanByteRate := int64(float64(nodeScanBytes) / scanDuration.Seconds())
+			t.L().Printf("n%d scanned %s (%s/s)",
+				node, humanize.IBytes(uint64(nodeScanBytes)), humanize.IBytes(uint64(nodeScanByteRate)))
+			stats[fmt.Sprintf("n%d-scan-byte-rate-mb", node)] = nodeScanByteRate / (1 << 20)
+		}
+	}
+
+	// Record the scan rate and range count at every split interval, so that
+	// the rate can be plotted against the range count.
+	for i, sample := range splitSamples {
+		stats[fmt.Sprintf("split-interval-%02d-ranges", i)] = sample.ranges
+		stats[fmt.Sprintf("split-interval-%02d-scan-byte-rate-mb", i)] = sample.scanByteRate / (1 << 20)
+	}
+
+	// If the changefeed was rate limited, verify that the rate at which the
+	// changefeed consumed events was within the limit across the data nodes.
+	// The throttler admits events by their approximate size, which the buffer
+	// acquires memory for scaled by the event memory multiplier.
+	if limit := clusterOpts.nodeByteRateLimit; limit > 0 {
+		var multiplier float64
+		require.NoError(t, conn.QueryRowContext(ctx,
+			`SHOW CLUSTER SETTING changefeed.event_memory_multiplier`).Scan(&multiplier))
+		bufferBytes := cdcBenchNodeMetricSum(ctx, t, c, nData, "changefeed.buffer_entries_mem.acquired") -
+			bufferBytesBefore
+		observedRate := int64(float64(bufferBytes) / multiplier / scanDuration.Seconds())
+		maxRate := limit * int64(len(nData))
+		t.L().Printf("changefeed consumed %s/s with a limit of %s/s (%s/s per node)",
+			humanize.IBytes(uint64(observedRate)), humanize.IBytes(uint64(maxRate)),
+			humanize.IBytes(uint64(limit)))
+
+		// Allow some slack for the throttler's burst budget, which is 1 second
+		// worth of quota per node.
+		const tolerance = 1.1
+		if float64(observedRate) > tolerance*float64(maxRate) {
+			t.Fatalf("changefeed consumed %s/s, exceeding the limit of %s/s",
+				humanize.IBytes(uint64(observedRate)), humanize.IBytes(uint64(maxRate)))
+		}
+		stats["rate-limit-mb"] = limit / (1 << 20)
+		stats["observed-rate-mb"] = observedRate / (1 << 20)
+	}
+
+	require.NoError(t, writeCDCBenchStats(ctx, t, c, nCoord, stats))
+}
+
+// cdcBenchSampleNodeMetric periodically samples the given node metric on the
+// given nodes in the background. The returned function stops sampling and
+// returns the peak value seen on any single node. Sampling errors are logged
+// but otherwise ignored, since they shouldn't fail the benchmark.
+func cdcBenchSampleNodeMetric(
+	ctx context.Context, t test.Test, c cluster.Cluster, nodes option.NodeListOption, metric string,
+) func() int64 {
+	const interval = 10 * time.Second
+
+	ctx, cancel := context.WithCancel(ctx)
+	conns := make([]*gosql.DB, len(nodes))
+	for i, node := range nodes {
+		conns[i] = c.Conn(ctx, t.L(), node)
+	}
+
+	var peak int64
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			for i, conn := range conns {
+				var value float64
+				if err := conn.QueryRowContext(ctx,
+					`SELECT value FROM crdb_internal.node_metrics WHERE name = $1`, metric,
+				).Scan(&value); err != nil {
+					if ctx.Err() == nil {
+						t.L().Printf("failed to sample %s on n%d: %s", metric, nodes[i], err)
+					}
+					continue
+				}
+				if int64(value) > peak {
+					peak = int64(value)
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return func() int64 {
+		cancel()
+		<-done
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+		return peak
+	}
+}
+
+// cdcBenchBuildCompactionBacklog limits the compaction concurrency of the
+// data nodes to 1, and runs a write-only KV workload on a separate database
+// until a compaction backlog has built up. The concurrency stays limited until
+// the returned function is called, which must happen before waiting on the
+// monitor.
+//
+// This assumes that each data node has a single store with the same ID as the
+// node.
+func cdcBenchBuildCompactionBacklog(
+	ctx context.Context,
+	t test.Test,
+	c cluster.Cluster,
+	m cluster.Monitor,
+	nData, nWorkload option.NodeListOption,
+) func() {
+	const (
+		db       = "cdc_compaction"
+		duration = 10 * time.Minute
+	)
+
+	var cancels []func()
+	for _, node := range nData {
+		node := node // pin loop variable
+		cancels = append(cancels, m.GoWithCancel(func(ctx context.Context) error {
+			conn := c.Conn(ctx, t.L(), node)
+			defer conn.Close()
+			// This blocks until cancelled, and then restores the previous
+			// compaction concurrency.
+			_, err := conn.ExecContext(ctx, fmt.Sprintf(
+				`SELECT crdb_internal.set_compaction_concurrency(%d, %d, 1)`, node, node))
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}))
+	}
+
+	t.L().Printf("building compaction backlog for %s", duration)
+	c.Run(ctx, option.WithNodes(nWorkload), fmt.Sprintf(
+		`./cockroach workload init kv --db %s {pgurl:%d}`, db, nData[0]))
+	c.Run(ctx, option.WithNodes(nWorkload), fmt.Sprintf(
+		`./cockroach workload run kv --db %s --read-percent 0 --concurrency 256 `+
+			`--min-block-bytes 4096 --max-block-bytes 4096 --duration %s {pgurl%s}`,
+		db, duration, nData))
+
+	return func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}
+}
+
+// cdcBenchQueriesDB is the database used by cdcBenchRunCoordinatorQueries.
+const cdcBenchQueriesDB = "cdc_queries"
+
+// cdcBenchRunCoordinatorQueries runs a read-mostly KV workload against the
+// given coordinator node until done is closed. The workload runs on a separate
+// database, so that its writes aren't emitted by the changefeed. It returns
+// the highest p99 SQL service latency sampled on the coordinator while the
+// workload ran. The coordinator's latency histograms are windowed, so this is
+// a close approximation of the p99 latency during the scan.
+func cdcBenchRunCoordinatorQueries(
+	ctx context.Context,
+	t test.Test,
+	c cluster.Cluster,
+	nCoord option.NodeListOption,
+	done <-chan struct{},
+) (time.Duration, error) {
+	const (
+		interval    = 10 * time.Second
+		concurrency = 64
+	)
+
+	// The workload is stopped by killing it once the scan is done, so give it
+	// a duration that outlasts any scan.
+	workloadDone := make(chan error, 1)
+	go func() {
+		t.L().Printf("running query workload on coordinator")
+		workloadDone <- c.RunE(ctx, option.WithNodes(nCoord), fmt.Sprintf(
+			`./cockroach workload run kv --db %s --read-percent 95 --concurrency %d `+
+				`--duration 24h --tolerate-errors {pgurl:%d}`,
+			cdcBenchQueriesDB, concurrency, nCoord[0]))
+	}()
+
+	conn := c.Conn(ctx, t.L(), nCoord[0])
+	defer conn.Close()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var p99 time.Duration
+	for {
+		select {
+		case <-ticker.C:
+		case <-done:
+			t.L().Printf("stopping query workload on coordinator")
+			if err := c.RunE(ctx, option.WithNodes(nCoord), fmt.Sprintf(
+				`pkill -f "workload run kv --db %s"`, cdcBenchQueriesDB)); err != nil {
+				return 0, err
+			}
+			// The workload fails when killed, so its error is expected.
+			<-workloadDone
+			return p99, nil
+		case err := <-workloadDone:
+			return 0, errors.Wrap(err, "query workload exited before the scan completed")
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+
+		var nanos float64
+		if err := conn.QueryRowContext(ctx,
+			`SELECT value FROM crdb_internal.node_metrics WHERE name = 'sql.service.latency-p99'`,
+		).Scan(&nanos); err != nil {
+			return 0, err
+		}
+		if d := time.Duration(nanos); d > p99 {
+			p99 = d
+		}
+	}
+}
+
+// cdcBenchImportDB is the database used by cdcBenchImportDuringScan.
+const cdcBenchImportDB = "cdc_import"
+
+// cdcBenchConcurrentImportRows is the number of rows imported by
+// cdcBenchImportDuringScan. This is large enough for the import to overlap
+// with most of the scan.
+const cdcBenchConcurrentImportRows = 200_000_000 // 4 GB
+
+// cdcBenchImportDuringScan imports rows into a kv table in a separate
+// database, so that they aren't emitted by the changefeed, using the workload
+// runner on the given node. It returns the duration of the import.
+func cdcBenchImportDuringScan(
+	ctx context.Context, t test.Test, c cluster.Cluster, nData, nWorkload option.NodeListOption,
+) (time.Duration, error) {
+	t.L().Printf("importing %s rows into %s concurrently with the scan",
+		humanize.Comma(cdcBenchConcurrentImportRows), cdcBenchImportDB)
+	start := timeutil.Now()
+	if err := c.RunE(ctx, option.WithNodes(nWorkload), fmt.Sprintf(
+		`./cockroach workload init kv --db %s --insert-count %d --data-loader import {pgurl:%d}`,
+		cdcBenchImportDB, cdcBenchConcurrentImportRows, nData[0])); err != nil {
+		return 0, errors.Wrap(err, "concurrent import failed")
+	}
+	return timeutil.Since(start), nil
+}
+
+// cdcBenchMixedHistoryBatch is the number of rows written or deleted by each
+// operation of cdcBenchBuildMixedHistory.
+const cdcBenchMixedHistoryBatch = 100
+
+// cdcBenchBuildMixedHistory runs a kv workload of reads, upserts and deletes
+// against the kv table from the given workload node, writing about a tenth
+// as many rows as numRows. The workload's keys are distinct from the ones
+// written by init --insert-count, so it cycles through a key space of a
+// hundredth of numRows to overwrite and delete keys several times over.
+func cdcBenchBuildMixedHistory(
+	ctx context.Context, t test.Test, c cluster.Cluster, nData, nWorkload option.NodeListOption, numRows int64,
+) {
+	ops := numRows / 10 / cdcBenchMixedHistoryBatch
+	cycleLength := numRows / 100
+	t.L().Printf("building mixed history with %s operations over %s keys",
+		humanize.Comma(ops), humanize.Comma(cycleLength))
+	c.Run(ctx, option.WithNodes(nWorkload), fmt.Sprintf(
+		`./cockroach workload run kv --read-percent 20 --del-percent 20 --batch %d `+
+			`--cycle-length %d --max-ops %d --concurrency 256 --tolerate-errors {pgurl%s}`,
+		cdcBenchMixedHistoryBatch, cycleLength, ops, nData))
+}
+
+// cdcBenchTableVersions returns the number of live rows in the kv table, and
+// the number of versions of them, including tombstones, from the MVCC stats
+// of its ranges.
+func cdcBenchTableVersions(ctx context.Context, t test.Test, conn *gosql.DB) (liveRows, versions int64) {
+	require.NoError(t, conn.QueryRowContext(ctx, `
+		SELECT sum((span_stats->>'live_count')::INT8), sum((span_stats->>'val_count')::INT8)
+		FROM [SHOW RANGES FROM TABLE kv.kv WITH DETAILS]`).Scan(&liveRows, &versions))
+	return liveRows, versions
+}
+
+// cdcBenchTableLayout returns the number of ranges of the kv table, and the
+// total size of their data, from the MVCC stats of its ranges.
+func cdcBenchTableLayout(ctx context.Context, t test.Test, conn *gosql.DB) (ranges, bytes int64) {
+	require.NoError(t, conn.QueryRowContext(ctx, `
+		SELECT count(*), COALESCE(sum(range_size), 0)::INT8
+		FROM [SHOW RANGES FROM TABLE kv.kv WITH DETAILS]`).Scan(&ranges, &bytes))
+	return ranges, bytes
+}
+
+// cdcBenchVersionTag encodes a version as an integer for stats.json, which
+// only records integers, such that later releases have larger tags. For
+// example, v24.1.3 is encoded as 240103.
+func cdcBenchVersionTag(v *clusterupgrade.Version) int64 {
+	return int64(v.Major()*10000 + v.Minor()*100 + v.Patch())
+}
+
+// cdcBenchCreateChangefeedsConcurrently opens n SQL connections to the given
+// node, and then runs the given CREATE CHANGEFEED statement once on each of
+// them concurrently. It returns the IDs of the changefeed jobs, the time that
+// each connection took to open and to create its changefeed, and a function
+// that closes the connections. The connections are kept open until then, so
+// that their sessions count towards the resource usage of the node.
+func cdcBenchCreateChangefeedsConcurrently(
+	ctx context.Context,
+	t test.Test,
+	c cluster.Cluster,
+	node option.NodeListOption,
+	stmt string,
+	n int,
+) (jobIDs []int, connectDurations, createDurations []time.Duration, closeConns func()) {
+	jobIDs = make([]int, n)
+	connectDurations = make([]time.Duration, n)
+	createDurations = make([]time.Duration, n)
+	conns := make([]*gosql.DB, n)
+	closeConns = func() {
+		for _, conn := range conns {
+			if conn != nil {
+				_ = conn.Close()
+			}
+		}
+	}
+
+	// The connections are opened concurrently too, since opening n of them is
+	// part of the load on the SQL layer. Each pool is limited to a single
+	// connection, so that every changefeed is created from its own session.
+	g := ctxgroup.WithContext(ctx)
+	for i := 0; i < n; i++ {
+		i := i
+		g.GoCtx(func(ctx context.Context) error {
+			start := timeutil.Now()
+			conn, err := c.ConnE(ctx, t.L(), node[0])
+			if err != nil {
+				return err
+			}
+			conn.SetMaxOpenConns(1)
+			conns[i] = conn
+			if err := conn.PingContext(ctx); err != nil {
+				return err
+			}
+			connectDurations[i] = timeutil.Since(start)
+
+			start = timeutil.Now()
+			if err := conn.QueryRowContext(ctx, stmt).Scan(&jobIDs[i]); err != nil {
+				return errors.Wrapf(err, "creating changefeed %d", i)
+			}
+			createDurations[i] = timeutil.Since(start)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		closeConns()
+		t.Fatal(err)
+	}
+	return jobIDs, connectDurations, createDurations, closeConns
+}
+
+// cdcBenchWaitForTableReplicas waits until every range of the kv table has the
+// given number of replicas. It returns the number of ranges that didn't when
+// it started.
+func cdcBenchWaitForTableReplicas(
+	ctx context.Context, t test.Test, conn *gosql.DB, replicas int,
+) (int64, error) {
+	const interval = 10 * time.Second
+
+	initial := int64(-1)
+	for {
+		var ranges int64
+		if err := conn.QueryRowContext(ctx, `
+			SELECT count(*) FROM [SHOW RANGES FROM TABLE kv.kv]
+			WHERE array_length(replicas, 1) != $1`, replicas).Scan(&ranges); err != nil {
+			return 0, err
+		}
+		if initial < 0 {
+			initial = ranges
+		}
+		if ranges == 0 {
+			return initial, nil
+		}
+		t.L().Printf("waiting for %s ranges to reach %dx replication", humanize.Comma(ranges), replicas)
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
+
+// cdcBenchSplitInterval is the interval at which cdcBenchSplitDuringScan
+// splits the table.
+const cdcBenchSplitInterval = time.Minute
+
+// cdcBenchSplitSample is the scan rate during a split interval, along with the
+// range count of the table at the end of it.
+type cdcBenchSplitSample struct {
+	ranges       int64
+	scanByteRate int64
+}
+
+// cdcBenchSplitDuringScan splits the kv table at the given number of random
+// keys every cdcBenchSplitInterval until done is closed. It returns the
+// rangefeed scan rate across the data nodes for each interval, along with the
+// table's range count at the end of it. The last partial interval is not
+// sampled, since the scan may have finished long before it ends.
+func cdcBenchSplitDuringScan(
+	ctx context.Context,
+	t test.Test,
+	c cluster.Cluster,
+	conn *gosql.DB,
+	nData option.NodeListOption,
+	splits int,
+	done <-chan struct{},
+) ([]cdcBenchSplitSample, error) {
+	ticker := time.NewTicker(cdcBenchSplitInterval)
+	defer ticker.Stop()
+
+	var samples []cdcBenchSplitSample
+	lastBytes, lastTime := cdcBenchRangefeedBlockBytes(ctx, t, c, nData), timeutil.Now()
+	for {
+		// Keys are uniformly distributed across the int64 space. Splitting at an
+		// existing split point is a noop, so the range count may grow slightly
+		// less than the number of splits.
+		t.L().Printf("splitting %d more ranges during scan", splits)
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf(
+			`ALTER TABLE kv.kv SPLIT AT SELECT ((random() * 2 - 1) * 9e18)::INT FROM generate_series(1, %d)`,
+			splits)); err != nil {
+			return nil, err
+		}
+
+		select {
+		case <-ticker.C:
+		case <-done:
+			return samples, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		var ranges int64
+		if err := conn.QueryRowContext(ctx,
+			`SELECT count(*) FROM [SHOW RANGES FROM TABLE kv.kv]`).Scan(&ranges); err != nil {
+			return nil, err
+		}
+		scanBytes, now := cdcBenchRangefeedBlockBytes(ctx, t, c, nData), timeutil.Now()
+		rate := int64(float64(scanBytes-lastBytes) / now.Sub(lastTime).Seconds())
+		t.L().Printf("changefeed scanned %s/s with %s ranges",
+			humanize.IBytes(uint64(rate)), humanize.Comma(ranges))
+		samples = append(samples, cdcBenchSplitSample{ranges: ranges, scanByteRate: rate})
+		lastBytes, lastTime = scanBytes, now
+	}
+}
+
+// cdcBenchRangefeedBlockBytes returns the total number of bytes loaded by
+// rangefeed storage iterators across the given nodes, including cached blocks.
+// This is a cumulative counter, so callers should diff it across a scan.
+func cdcBenchRangefeedBlockBytes(
+	ctx context.Context, t test.Test, c cluster.Cluster, nodes option.NodeListOption,
+) int64 {
+	return cdcBenchNodeMetricSum(ctx, t, c, nodes, "storage.iterator.category-rangefeed.block-load.bytes")
+}
+
+// cdcBenchCPUNanos returns the total user and system CPU time used by the
+// given nodes, in nanoseconds. This is a cumulative counter, so callers should
+// diff it across a scan.
+func cdcBenchCPUNanos(
+	ctx context.Context, t test.Test, c cluster.Cluster, nodes option.NodeListOption,
+) int64 {
+	return cdcBenchNodeMetricSum(ctx, t, c, nodes, "sys.cpu.user.ns") +
+		cdcBenchNodeMetricSum(ctx, t, c, nodes, "sys.cpu.sys.ns")
+}
+
+// cdcBenchNodeCPUNanos returns the total user and system CPU time used by each
+// of the given nodes, in nanoseconds.
+func cdcBenchNodeCPUNanos(
+	ctx context.Context, t test.Test, c cluster.Cluster, nodes option.NodeListOption,
+) []int64 {
+	cpuNanos := make([]int64, len(nodes))
+	for i, node := range nodes {
+		cpuNanos[i] = cdcBenchCPUNanos(ctx, t, c, c.Node(node))
+	}
+	return cpuNanos
+}
+
+// cdcBenchNodeCPUUtilization returns the maximum and mean CPU utilization
+// across nodes, in percent of their CPUs, given the CPU time used by each node
+// at the start and the end of the given duration.
+func cdcBenchNodeCPUUtilization(
+	before, after []int64, duration time.Duration, cpus int,
+) (maxPct, meanPct int64) {
+	if len(before) == 0 || duration <= 0 {
+		return 0, 0
+	}
+	var total int64
+	for i := range before {
+		pct := 100 * (after[i] - before[i]) / (int64(duration) * int64(cpus))
+		if pct > maxPct {
+			maxPct = pct
+		}
+		total += pct
+	}
+	return maxPct, total / int64(len(before))
+}
+
+// setupCDCBenchKafkaSink installs and starts a Kafka broker on the given node,
+// and returns the sink URI to use for it. The broker is always configured with
+// both plaintext and TLS listeners, so that the Kafka sinks only differ in the
+// encryption of the connection. With TLS, the handshake is verified against
+// the broker's CA certificate before returning, since a misconfigured listener
+// would otherwise only show up as a failed or skewed benchmark. With
+// schemaRegistry, a schema registry is started along with the broker, and its
+// URL is returned too.
+func setupCDCBenchKafkaSink(
+	ctx context.Context,
+	t test.Test,
+	c cluster.Cluster,
+	node option.NodeListOption,
+	tls, schemaRegistry bool,
+) (sinkURL, schemaRegistryURL string) {
+	kafka := kafkaManager{
+		t:             t,
+		c:             c,
+		kafkaSinkNode: node,
+	}
+	kafka.install(ctx)
+	certs := kafka.configureAuth(ctx)
+	if schemaRegistry {
+		kafka.start(ctx, "schema-registry")
+		schemaRegistryURL = kafka.schemaRegistryURL(ctx)
+	} else {
+		kafka.start(ctx, "kafka")
+	}
+
+	if !tls {
+		return kafka.sinkURL(ctx), schemaRegistryURL
+	}
+
+	t.L().Printf("verifying TLS handshake with kafka")
+	host := strings.TrimPrefix(kafka.sinkURLTLS(ctx), "kafka://")
+	c.Run(ctx, option.WithNodes(node), fmt.Sprintf(
+		"openssl s_client -connect %s -CAfile %s -verify_return_error -brief < /dev/null",
+		host, filepath.Join(kafka.configDir(), "ca.crt")))
+
+	params := url.Values{}
+	params.Set(changefeedbase.SinkParamTLSEnabled, "true")
+	params.Set(changefeedbase.SinkParamCACert, certs.CACertBase64())
+	return kafka.sinkURLTLS(ctx) + "?" + params.Encode(), schemaRegistryURL
+}
+
+// setupCDCBenchSchemaRegistry installs and starts a Confluent schema registry
+// on the given node, for sinks that don't run a Kafka broker there already,
+// and returns its URL. The registry stores its schemas in Kafka, so a broker
+// is started along with it.
+func setupCDCBenchSchemaRegistry(
+	ctx context.Context, t test.Test, c cluster.Cluster, node option.NodeListOption,
+) string {
+	kafka := kafkaManager{
+		t:             t,
+		c:             c,
+		kafkaSinkNode: node,
+	}
+	kafka.install(ctx)
+	kafka.start(ctx, "schema-registry")
+	return kafka.schemaRegistryURL(ctx)
+}
+
+// setupCDCBenchReplication sets up cdcBenchSinkKafkaCRDB. It starts a Kafka
+// broker on the given node that the test runner can consume from, creates the
+// topic that the changefeed emits to, and starts the destination cluster on
+// the destination nodes with an empty kv table. It returns the broker and a
+// connection to the destination cluster.
+func setupCDCBenchReplication(
+	ctx context.Context, t test.Test, c cluster.Cluster, node, nDest option.NodeListOption,
+) (kafkaManager, *gosql.DB) {
+	kafka, _ := setupKafka(ctx, t, c, node)
+
+	// Kafka creates topics with a single partition by default, which would
+	// limit the consumer to a single goroutine.
+	t.L().Printf("creating kafka topic with %d partitions", cdcBenchReplicationPartitions)
+	require.NoError(t, retry.ForDuration(kafkaCreateTopicRetryDuration, func() error {
+		admin, err := sarama.NewClusterAdmin([]string{kafka.consumerURL(ctx)}, sarama.NewConfig())
+		if err != nil {
+			return errors.Wrap(err, "admin client")
+		}
+		defer func() { _ = admin.Close() }()
+		return admin.CreateTopic("kv", &sarama.TopicDetail{
+			NumPartitions:     cdcBenchReplicationPartitions,
+			ReplicationFactor: 1,
+		}, false)
+	}))
+
+	// The destination cluster is a separate cluster, initialized on its first
+	// node. Split its table, so that the applied rows are spread across its
+	// nodes.
+	t.L().Printf("starting destination cluster on nodes %v", nDest)
+	startOpts := option.DefaultStartOptsNoBackups()
+	startOpts.RoachprodOpts.InitTarget = nDest[0]
+	roachtestutil.SetDefaultAdminUIPort(c, &startOpts.RoachprodOpts)
+	c.Start(ctx, t.L(), startOpts, install.MakeClusterSettings(), nDest)
+	const splits = 100
+	c.Run(ctx, option.WithNodes(node), fmt.Sprintf(
+		`./cockroach workload init kv --splits %d {pgurl:%d}`, splits, nDest[0]))
+	return kafka, c.Conn(ctx, t.L(), nDest[0])
+}
+
+// cdcBenchReplicationResult is the progress of applying the rows emitted by a
+// changefeed to the destination cluster with cdcBenchSinkKafkaCRDB.
+type cdcBenchReplicationResult struct {
+	// applied is the number of rows applied, including the rows that were
+	// emitted more than once.
+	applied int64
+	// duration is the time from applying the first row to applying the last.
+	duration time.Duration
+	// lag is the time from the changefeed completing to applying the last row.
+	lag time.Duration
+}
+
+// cdcBenchApplyReplicatedRows consumes the rows that the changefeed emits to
+// Kafka with cdcBenchSinkKafkaCRDB and upserts them into the destination
+// cluster, with a goroutine per partition. It returns once numRows rows have
+// been applied, or once no rows have been applied for
+// cdcBenchReplicationIdleTimeout after done is closed.
+func cdcBenchApplyReplicatedRows(
+	ctx context.Context,
+	t test.Test,
+	kafka kafkaManager,
+	destConn *gosql.DB,
+	numRows int64,
+	done <-chan struct{},
+) (cdcBenchReplicationResult, error) {
+	const topic = "kv"
+	config := sarama.NewConfig()
+	// The fetch size must not be smaller than the broker's max.message.bytes,
+	// see newConsumer.
+	config.Consumer.Fetch.Default = 1000012
+	consumer, err := sarama.NewConsumer([]string{kafka.consumerURL(ctx)}, config)
+	if err != nil {
+		return cdcBenchReplicationResult{}, err
+	}
+	defer func() { _ = consumer.Close() }()
+	partitions, err := consumer.Partitions(topic)
+	if err != nil {
+		return cdcBenchReplicationResult{}, err
+	}
+
+	var mu syncutil.Mutex
+	var res cdcBenchReplicationResult
+	var firstApplied, lastApplied time.Time
+	onApplied := func(rows int) {
+		now := timeutil.Now()
+		mu.Lock()
+		defer mu.Unlock()
+		if firstApplied.IsZero() {
+			firstApplied = now
+		}
+		lastApplied = now
+		res.applied += int64(rows)
+	}
+
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+	g := ctxgroup.WithContext(ctx)
+	for _, partition := range partitions {
+		pc, err := consumer.ConsumePartition(topic, partition, sarama.OffsetOldest)
+		if err != nil {
+			return cdcBenchReplicationResult{}, err
+		}
+		g.GoCtx(func(ctx context.Context) error {
+			defer func() { _ = pc.Close() }()
+			return cdcBenchApplyPartition(ctx, pc, destConn, onApplied)
+		})
+	}
+
+	// Stop the consumers once they're done, and note when the changefeed
+	// completed to compute the lag.
+	var doneTime time.Time
+	g.GoCtx(func(ctx context.Context) error {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		var prevApplied int64
+		lastProgress := timeutil.Now()
+		for {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return nil
+			}
+			now := timeutil.Now()
+			mu.Lock()
+			applied := res.applied
+			mu.Unlock()
+			if applied >= numRows {
+				stop()
+				return nil
+			}
+			if applied != prevApplied {
+				prevApplied, lastProgress = applied, now
+			}
+			select {
+			case <-done:
+				if doneTime.IsZero() {
+					doneTime = now
+					// Don't count the time that the consumer was idle before the
+					// changefeed completed.
+					lastProgress = now
+				}
+				if now.Sub(lastProgress) > cdcBenchReplicationIdleTimeout {
+					t.L().Printf("WARNING: consumer applied %s of %s rows before going idle",
+						humanize.Comma(applied), humanize.Comma(numRows))
+					stop()
+					return nil
+				}
+			default:
+			}
+		}
+	})
+	if err := g.Wait(); err != nil {
+		return cdcBenchReplicationResult{}, err
+	}
+
+	res.duration = lastApplied.Sub(firstApplied)
+	if !doneTime.IsZero() && lastApplied.After(doneTime) {
+		res.lag = lastApplied.Sub(doneTime)
+	}
+	return res, nil
+}
+
+// cdcBenchApplyPartition upserts the rows of the messages of a partition into
+// the destination cluster in batches of cdcBenchReplicationBatch rows, calling
+// onApplied with the number of rows of every batch, until ctx is canceled.
+// Initial scans only emit inserts, so every message has a row after the update.
+func cdcBenchApplyPartition(
+	ctx context.Context, pc sarama.PartitionConsumer, destConn *gosql.DB, onApplied func(rows int),
+) error {
+	// Partial batches are flushed periodically, so that the tail of the
+	// partition is applied too.
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	var stmt strings.Builder
+	args := make([]interface{}, 0, 2*cdcBenchReplicationBatch)
+	flush := func() error {
+		if len(args) == 0 {
+			return nil
+		}
+		stmt.Reset()
+		stmt.WriteString(`UPSERT INTO kv.kv (k, v) VALUES `)
+		for i := 0; i < len(args)/2; i++ {
+			if i > 0 {
+				stmt.WriteString(", ")
+			}
+			fmt.Fprintf(&stmt, "($%d, $%d::BYTES)", 2*i+1, 2*i+2)
+		}
+		if _, err := destConn.ExecContext(ctx, stmt.String(), args...); err != nil {
+			return errors.Wrap(err, "applying replicated rows")
+		}
+		onApplied(len(args) / 2)
+		args = args[:0]
+		return nil
+	}
+
+	for {
+		select {
+		case msg := <-pc.Messages():
+			// The JSON format emits bytes in their hex representation, which
+			// the cast to BYTES parses again.
+			var row struct {
+				After struct {
+					K int64  `json:"k"`
+					V string `json:"v"`
+				} `json:"after"`
+			}
+			if err := json.Unmarshal(msg.Value, &row); err != nil {
+				return errors.Wrapf(err, "decoding message %q", msg.Value)
+			}
+			args = append(args, row.After.K, row.After.V)
+			if len(args) < 2*cdcBenchReplicationBatch {
+				continue
+			}
+		case err := <-pc.Errors():
+			return err
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+	}
+}
+
+// cdcBenchLocalFileSinkBytes returns the total size of the files written by
+// cdcBenchSinkLocalFile across the given nodes.
+func cdcBenchLocalFileSinkBytes(
+	ctx context.Context, t test.Test, c cluster.Cluster, nodes option.NodeListOption,
+) int64 {
+	var total int64
+	for _, node := range nodes {
+		// Nodes without any aggregators don't have the directory.
+		result, err := c.RunWithDetailsSingleNode(ctx, t.L(), option.WithNodes(c.Node(node)), fmt.Sprintf(
+			"mkdir -p {store-dir}/extern/%[1]s && du -sb {store-dir}/extern/%[1]s | cut -f1",
+			cdcBenchLocalFileSinkDir))
+		require.NoError(t, err)
+		size, err := strconv.ParseInt(strings.TrimSpace(result.Stdout), 10, 64)
+		require.NoError(t, err)
+		total += size
+	}
+	return total
+}
+
+// cdcBenchNodeMetricSum returns the sum of the given metric across the given
+// nodes.
+func cdcBenchNodeMetricSum(
+	ctx context.Context, t test.Test, c cluster.Cluster, nodes option.NodeListOption, metric string,
+) int64 {
+	var total float64
+	for _, node := range nodes {
+		total += nodeMetric(ctx, t, c, node, metric)
+	}
+	return int64(total)
+}
+
+// runCDCBenchWorkload runs a KV workload on top of a changefeed, measuring the
+// workload throughput and latency. Rangefeeds are configured to backpressure
+// writers, which yields reliable results for the full write+emission cost.
+// The workload results (throughput and latency) can be compared to separate
+// control runs that only run the workload without changefeeds and rangefeeds.
+//
+// It sets up a cluster with N-2 data nodes, and a separate changefeed
+// coordinator node and workload runner. With a Kafka sink, the broker runs on
+// the coordinator node.
+func runCDCBenchWorkload(
+	ctx context.Context,
+	t test.Test,
+	c cluster.Cluster,
+	numRanges int64,
+	readPercent int,
+	format string,
+	sink cdcBenchSink,
+) {
+	var (
+		numNodes  = c.Spec().NodeCount
+		nData     = c.Range(1, numNodes-2)
+		nCoord    = c.Node(numNodes - 1)
+		nWorkload = c.Node(numNodes)
+
+		workloadSeed = randutil.NewPseudoSeed()
+		concurrency  = len(nData) * 64
+		duration     = 20 * time.Minute
+		insertCount  = int64(0)
+		cdcEnabled   = format != ""
+	)
+	if readPercent == 100 {
+		insertCount = 1_000_000 // ingest some data to read
+	}
+
+	// Start data nodes first to place data on them. We'll start the changefeed
+	// coordinator later, since we don't want any data on it.
+	opts, settings := makeCDCBenchOptions(c, cdcBenchClusterOpts{})
+	settings.ClusterSettings["kv.rangefeed.enabled"] = strconv.FormatBool(cdcEnabled)
+
+	c.Start(ctx, t.L(), opts, settings, nData)
+	m := c.NewMonitor(ctx, nData.Merge(nCoord))
+
+	conn := c.Conn(ctx, t.L(), nData[0])
+	defer conn.Close()
+
+	// Prohibit ranges on the changefeed coordinator.
+	t.L().Printf("configuring zones")
+	for _, target := range getAllZoneTargets(ctx, t, conn) {
+		_, err := conn.ExecContext(ctx, fmt.Sprintf(
+			`ALTER %s CONFIGURE ZONE USING num_replicas=3, constraints='[-node%d]'`, target, nCoord[0]))
+		require.NoError(t, err)
+	}
+
+	// Wait for system ranges to upreplicate.
+	require.NoError(t, WaitFor3XReplication(ctx, t, t.L(), conn))
+
+	// Create and split the workload table.
+	//
+	// NB: don't scatter -- the ranges end up fairly well-distributed anyway, and
+	// the scatter can often fail with 100k ranges.
+	t.L().Printf("creating table with %s ranges", humanize.Comma(numRanges))
+	c.Run(ctx, option.WithNodes(nWorkload), fmt.Sprintf(
+		`./cockroach workload init kv --splits %d {pgurl:%d}`, numRanges, nData[0]))
+	require.NoError(t, WaitFor3XReplication(ctx, t, t.L(), conn))
+
+	// For read-only workloads, ingest some data. init --insert-count does not use
+	// the standard key generator that the read workload uses, so we have to write
+	// them with a separate write workload first, see:
+	// https://github.com/cockroachdb/cockroach/issues/107874
+	if insertCount > 0 {
+		const batchSize = 1000
+		batches := (insertCount-1)/batchSize + 1 // ceiling division
+		t.L().Printf("ingesting %s rows", humanize.Comma(insertCount))
+		c.Run(ctx, option.WithNodes(nWorkload), fmt.Sprintf(
+			`./cockroach workload run kv --seed %d --read-percent 0 --batch %d --max-ops %d {pgurl:%d}`,
+			workloadSeed, batchSize, batches, nData[0]))
+	}
+
+	// Now that the ranges are placed, start the changefeed coordinator.
+	t.L().Printf("starting coordinator node")
+	c.Start(ctx, t.L(), opts, settings, nCoord)
+
+	conn = c.Conn(ctx, t.L(), nCoord[0])
+	defer conn.Close()
+
+	// Start the changefeed if enabled. We disable the initial scan, since we
+	// don't care about the historical data.
+	var jobID int
+	var done atomic.Value // time.Time
+	if cdcEnabled {
+		with := fmt.Sprintf(`format = '%s', initial_scan = 'no'`, format)
+		var sinkURI string
+		switch sink {
+		case cdcBenchSinkNull:
+			sinkURI = "null://"
+		case cdcBenchSinkKafka:
+			t.L().Printf("starting kafka broker on coordinator node")
+			kafka, stopKafka := setupKafka(ctx, t, c, nCoord)
+			defer stopKafka()
+			sinkURI = kafka.sinkURL(ctx)
+			with += fmt.Sprintf(", kafka_sink_config = '%s'", cdcBenchKafkaSinkConfig)
+		default:
+			t.Fatalf("unsupported sink %q", sink)
+		}
+
+		t.L().Printf("starting changefeed")
+
+		// Lock schema so that changefeed schema feed runs under fast path.
+		_, err := conn.ExecContext(ctx, "ALTER TABLE kv.kv  SET (schema_locked = true);")
+		require.NoError(t, err)
+
+		require.NoError(t, conn.QueryRowContext(ctx, fmt.Sprintf(
+			`CREATE CHANGEFEED FOR kv.kv INTO '%s' WITH %s`, sinkURI, with)).
+			Scan(&jobID))
+
+		// Monitor the changefeed for failures. When the workload finishes, it will
+		// store the completion timestamp in done, and we'll wait for the
+		// changefeed's watermark to reach it.
+		//
+		// The watermark and lag isn't recorded by the benchmark, but we make sure
+		// all data is eventually emitted. It is also helpful for inspection, and we
+		// may want to track or assert on it later. Initially, this asserted that
+		// the changefeed wasn't lagging by more than 1-2 minutes, but with 100k
+		// ranges it was found to sometimes lag by over 8 minutes.
+		m.Go(func(ctx context.Context) error {
+			info, err := waitForChangefeed(ctx, conn, jobID, t.L(), func(info changefeedInfo) (bool, error) {
+				switch jobs.Status(info.status) {
+				case jobs.StatusPending, jobs.StatusRunning:
+					doneValue := done.Load()
+					return doneValue != nil && info.highwaterTime.After(doneValue.(time.Time)), nil
+				default:
+					return false, errors.Errorf("unexpected changefeed status %s", info.status)
+				}
+			})
+			if err != nil {
+				return err
+			}
+			t.L().Printf("changefeed watermark is %s", info.highwaterTime.Format(time.RFC3339))
+			return nil
+		})
+
+		// Wait for a stable changefeed before starting the workload, by waiting for
+		// the watermark to reach the current time.
+		now := timeutil.Now()
+		t.L().Printf("waiting for changefeed watermark to reach current time (%s)",
+			now.Format(time.RFC3339))
+		info, err := waitForChangefeed(ctx, conn, jobID, t.L(), func(info changefeedInfo) (bool, error) {
+			switch jobs.Status(info.status) {
+			case jobs.StatusPending, jobs.StatusRunning:
+				return info.highwaterTime.After(now), nil
+			default:
+				return false, errors.Errorf("unexpected changefeed status %s", info.status)
+			}
+		})
+		require.NoError(t, err)
+		t.L().Printf("changefeed watermark is %s", info.highwaterTime.Format(time.RFC3339))
+
+	} else {
+		t.L().Printf("control run, not starting changefeed")
+	}
+
+	// Run the workload and record stats. Make sure to use the same seed, so we
+	// read any rows we wrote above.
+	m.Go(func(ctx context.Context) error {
+		// If there's more than 10,000 replicas per node they may struggle to
+		// maintain RPC connections or liveness, which occasionally fails client
+		// write requests with ambiguous errors. We tolerate errors in this case
+		// until we optimize rangefeeds.
+		//
+		// TODO(erikgrinaker): remove this when benchmarks are stable.
+		var extra string
+		if readPercent < 100 && (numRanges/int64(len(nData))) >= 10000 {
+			extra += ` --tolerate-errors`
+		}
+		t.L().Printf("running workload")
+		err := c.RunE(ctx, option.WithNodes(nWorkload), fmt.Sprintf(
+			`./cockroach workload run kv --seed %d --histograms=%s/stats.json `+
+				`--concurrency %d --duration %s --write-seq R%d --read-percent %d %s {pgurl:%d-%d}`,
+			workloadSeed, t.PerfArtifactsDir(), concurrency, duration, insertCount, readPercent, extra,
+			nData[0], nData[len(nData)-1]))
+		if err != nil {
+			return err
+		}
+		t.L().Printf("workload completed")
+
+		// When the workload completes, signal the completion time to the changefeed
+		// monitor via done, which will wait for it to fully catch up.
+		if cdcEnabled {
+			now := timeutil.Now()
+			done.Store(now)
+			info, err := getChangefeedInfo(conn, jobID)
+			if err != nil {
+				return err
+			}
+			t.L().Printf("waiting for changefeed watermark to reach %s (lagging by %s)",
+				now.Format(time.RFC3339), now.Sub(info.highwaterTime).Truncate(time.Second))
+		}
+		return nil
+	})
+
+	m.Wait()
+}
+
+// cdcBenchQueryFilterModulus is the modulus of the WHERE filter of the
+// changefeed query benchmarks, which emit every row whose key is a multiple of
+// it, i.e. a quarter of the rows.
+const cdcBenchQueryFilterModulus = 4
+
+// runCDCBenchQuery measures the initial scan throughput of a changefeed
+// created with a query, i.e. CREATE CHANGEFEED ... AS SELECT, that projects
+// the key column only and filters the rows by it. The changefeed evaluates the
+// projection and filter for every row it scans, so the scan rate can be
+// compared against the initial scan benchmarks over the same data, which emit
+// every row as is. The scan rate, the rate of emitted rows and the CPU time
+// used are recorded in stats.json.
+//
+// It sets up a cluster with N-1 data nodes, and a separate changefeed
+// coordinator node, which also runs the workload.
+func runCDCBenchQuery(
+	ctx context.Context, t test.Test, c cluster.Cluster, numRows, numRanges int64, format string,
+) {
+	var (
+		numNodes = c.Spec().NodeCount
+		nData    = c.Range(1, numNodes-1)
+		nCoord   = c.Node(numNodes)
+	)
+
+	// Start data nodes first to place data on them. We'll start the changefeed
+	// coordinator later, since we don't want any data on it.
+	opts, settings := makeCDCBenchOptions(c, cdcBenchClusterOpts{})
+	c.Start(ctx, t.L(), opts, settings, nData)
+	m := c.NewMonitor(ctx, nData.Merge(nCoord))
+
+	conn := c.Conn(ctx, t.L(), nData[0])
+	defer conn.Close()
+
+	// Prohibit ranges on the changefeed coordinator.
+	t.L().Printf("configuring zones")
+	for _, target := range getAllZoneTargets(ctx, t, conn) {
+		_, err := conn.ExecContext(ctx, fmt.Sprintf(
+			`ALTER %s CONFIGURE ZONE USING num_replicas=3, constraints='[-node%d]'`, target, nCoord[0]))
+		require.NoError(t, err)
 	}
 
+	// Wait for system ranges to upreplicate.
+	require.NoError(t, WaitFor3XReplication(ctx, t, t.L(), conn))
+
+	// Create and split the workload table, then import data into it. The
+	// import happens separately, because it imports before splitting otherwise,
+	// which takes a very long time.
+	t.L().Printf("creating table with %s ranges", humanize.Comma(numRanges))
+	c.Run(ctx, option.WithNodes(nCoord), fmt.Sprintf(
+		`./cockroach workload init kv --splits %d {pgurl:%d}`, numRanges, nData[0]))
+	require.NoError(t, WaitFor3XReplication(ctx, t, t.L(), conn))
+
+	t.L().Printf("ingesting %s rows using import", humanize.Comma(numRows))
+	c.Run(ctx, option.WithNodes(nCoord), fmt.Sprintf(
+		`./cockroach workload init kv --insert-count %d --data-loader import {pgurl:%d}`,
+		numRows, nData[0]))
+
+	// The import writes random keys, so count the rows that the filter emits.
+	var filteredRows int64
+	require.NoError(t, conn.QueryRowContext(ctx, fmt.Sprintf(
+		`SELECT count(*) FROM kv.kv WHERE k %% %d = 0`, cdcBenchQueryFilterModulus)).Scan(&filteredRows))
+
+	// Now that the ranges are placed, start the changefeed coordinator.
+	t.L().Printf("starting coordinator node")
+	c.Start(ctx, t.L(), opts, settings, nCoord)
+
+	conn = c.Conn(ctx, t.L(), nCoord[0])
+	defer conn.Close()
+
 	// Lock schema so that changefeed schema feed runs under fast path.
 	_, err := conn.ExecContext(ctx, "ALTER TABLE kv.kv  SET (schema_locked = true);")
 	require.NoError(t, err)
 
+	emittedMessagesBefore := cdcBenchNodeMetricSum(ctx, t, c, nData.Merge(nCoord), "changefeed.emitted_messages")
+	cpuNanosBefore := cdcBenchCPUNanos(ctx, t, c, nData.Merge(nCoord))
+
+	// Run a one-shot initial scan, which completes on its own, and compute
+	// throughput based on the job's start and finish time.
+	t.L().Printf("running changefeed query scan")
 	var jobID int
-	require.NoError(t, conn.QueryRowContext(ctx,
-		fmt.Sprintf(`CREATE CHANGEFEED FOR kv.kv INTO '%s' WITH %s`, sink, with)).
+	require.NoError(t, conn.QueryRowContext(ctx, fmt.Sprintf(
+		`CREATE CHANGEFEED INTO 'null://' WITH format = '%s', initial_scan = 'only' `+
+			`AS SELECT k FROM kv.kv WHERE k %% %d = 0`, format, cdcBenchQueryFilterModulus)).
 		Scan(&jobID))
 
-	// Wait for the changefeed to complete, and compute throughput.
+	var scanRate int64
+	var scanDuration time.Duration
 	m.Go(func(ctx context.Context) error {
-		t.L().Printf("waiting for changefeed to finish")
 		info, err := waitForChangefeed(ctx, conn, jobID, t.L(), func(info changefeedInfo) (bool, error) {
 			switch jobs.Status(info.status) {
 			case jobs.StatusSucceeded:
@@ -325,57 +3171,325 @@ func runCDCBenchScan(
 		if err != nil {
 			return err
 		}
-
-		duration := info.finishedTime.Sub(info.startedTime)
-		rate := int64(float64(numRows) / duration.Seconds())
+		scanDuration = info.finishedTime.Sub(info.startedTime)
+		scanRate = int64(float64(numRows) / scanDuration.Seconds())
 		t.L().Printf("changefeed completed in %s (scanned %s rows per second)",
-			duration.Truncate(time.Second), humanize.Comma(rate))
-
-		// Record scan rate to stats.json.
-		return writeCDCBenchStats(ctx, t, c, nCoord, "scan-rate", rate)
+			scanDuration.Truncate(time.Second), humanize.Comma(scanRate))
+		return nil
 	})
-
 	m.Wait()
+
+	cpuSeconds := (cdcBenchCPUNanos(ctx, t, c, nData.Merge(nCoord)) - cpuNanosBefore) / int64(time.Second)
+	t.L().Printf("changefeed used %s CPU seconds across all nodes", humanize.Comma(cpuSeconds))
+
+	// Make sure that the changefeed emitted all the rows that pass the filter.
+	// Messages may be emitted more than once, so this is a lower bound.
+	emittedMessages := cdcBenchNodeMetricSum(ctx, t, c, nData.Merge(nCoord), "changefeed.emitted_messages") -
+		emittedMessagesBefore
+	if emittedMessages < filteredRows {
+		t.Fatalf("changefeed emitted %s messages, expected at least %s",
+			humanize.Comma(emittedMessages), humanize.Comma(filteredRows))
+	}
+	emitRate := int64(float64(emittedMessages) / scanDuration.Seconds())
+	t.L().Printf("changefeed emitted %s of %s rows (%s per second)",
+		humanize.Comma(emittedMessages), humanize.Comma(numRows), humanize.Comma(emitRate))
+
+	require.NoError(t, writeCDCBenchStats(ctx, t, c, nCoord, map[string]int64{
+		"scan-rate":   scanRate,
+		"emit-rate":   emitRate,
+		"cpu-seconds": cpuSeconds,
+	}))
 }
 
-// runCDCBenchWorkload runs a KV workload on top of a changefeed, measuring the
-// workload throughput and latency. Rangefeeds are configured to backpressure
-// writers, which yields reliable results for the full write+emission cost.
-// The workload results (throughput and latency) can be compared to separate
-// control runs that only run the workload without changefeeds and rangefeeds.
+// runCDCBenchRestart measures how long a changefeed takes to recover after its
+// coordinator node is killed and restarted, while a KV workload writes to the
+// table. The changefeed emits the workload's writes for a while before the
+// coordinator is killed, and is down for the given duration. The recovery time
+// is measured from the kill until the changefeed's high-water passes the time
+// of the kill again, and recorded in stats.json.
 //
 // It sets up a cluster with N-2 data nodes, and a separate changefeed
 // coordinator node and workload runner.
-func runCDCBenchWorkload(
+func runCDCBenchRestart(
 	ctx context.Context,
 	t test.Test,
 	c cluster.Cluster,
 	numRanges int64,
-	readPercent int,
+	downtime time.Duration,
 	format string,
 ) {
-	const sink = "null://"
+	const (
+		emitDuration = 5 * time.Minute
+		writeRate    = 5000
+	)
 	var (
 		numNodes  = c.Spec().NodeCount
 		nData     = c.Range(1, numNodes-2)
 		nCoord    = c.Node(numNodes - 1)
 		nWorkload = c.Node(numNodes)
 
-		workloadSeed = randutil.NewPseudoSeed()
-		concurrency  = len(nData) * 64
-		duration     = 20 * time.Minute
-		insertCount  = int64(0)
-		cdcEnabled   = format != ""
+		concurrency = len(nData) * 16
+		// The workload keeps writing through the restart and the recovery.
+		duration = emitDuration + downtime + 20*time.Minute
+	)
+
+	// Start data nodes first to place data on them. We'll start the changefeed
+	// coordinator later, since we don't want any data on it.
+	opts, settings := makeCDCBenchOptions(c, cdcBenchClusterOpts{})
+	c.Start(ctx, t.L(), opts, settings, nData)
+	m := c.NewMonitor(ctx, nData.Merge(nCoord))
+
+	conn := c.Conn(ctx, t.L(), nData[0])
+	defer conn.Close()
+
+	// Prohibit ranges on the changefeed coordinator.
+	t.L().Printf("configuring zones")
+	for _, target := range getAllZoneTargets(ctx, t, conn) {
+		_, err := conn.ExecContext(ctx, fmt.Sprintf(
+			`ALTER %s CONFIGURE ZONE USING num_replicas=3, constraints='[-node%d]'`, target, nCoord[0]))
+		require.NoError(t, err)
+	}
+
+	// Wait for system ranges to upreplicate.
+	require.NoError(t, WaitFor3XReplication(ctx, t, t.L(), conn))
+
+	t.L().Printf("creating table with %s ranges", humanize.Comma(numRanges))
+	c.Run(ctx, option.WithNodes(nWorkload), fmt.Sprintf(
+		`./cockroach workload init kv --splits %d {pgurl:%d}`, numRanges, nData[0]))
+	require.NoError(t, WaitFor3XReplication(ctx, t, t.L(), conn))
+
+	// Now that the ranges are placed, start the changefeed coordinator.
+	t.L().Printf("starting coordinator node")
+	c.Start(ctx, t.L(), opts, settings, nCoord)
+
+	coordConn := c.Conn(ctx, t.L(), nCoord[0])
+	defer coordConn.Close()
+
+	// Lock schema so that changefeed schema feed runs under fast path.
+	_, err := coordConn.ExecContext(ctx, "ALTER TABLE kv.kv  SET (schema_locked = true);")
+	require.NoError(t, err)
+
+	// Create the changefeed on the coordinator, which makes it the node that
+	// the changefeed is coordinated from. The changefeed is monitored from a
+	// data node, which stays up throughout.
+	t.L().Printf("starting changefeed")
+	var jobID int
+	require.NoError(t, coordConn.QueryRowContext(ctx, fmt.Sprintf(
+		`CREATE CHANGEFEED FOR kv.kv INTO 'null://' WITH format = '%s', initial_scan = 'no'`, format)).
+		Scan(&jobID))
+
+	now := timeutil.Now()
+	t.L().Printf("waiting for changefeed watermark to reach current time (%s)", now.Format(time.RFC3339))
+	info, err := waitForChangefeed(ctx, conn, jobID, t.L(), highWaterAtLeast(now))
+	require.NoError(t, err)
+	t.L().Printf("changefeed watermark is %s", info.highwaterTime.Format(time.RFC3339))
+
+	m.Go(func(ctx context.Context) error {
+		t.L().Printf("running workload for %s", duration)
+		return c.RunE(ctx, option.WithNodes(nWorkload), fmt.Sprintf(
+			`./cockroach workload run kv --read-percent 0 --concurrency %d --max-rate %d --duration %s {pgurl%s}`,
+			concurrency, writeRate, duration, nData))
+	})
+
+	// Let the changefeed emit the workload's writes, then kill the coordinator
+	// and restart it after the downtime.
+	var recovery time.Duration
+	m.Go(func(ctx context.Context) error {
+		t.L().Printf("letting changefeed emit for %s", emitDuration)
+		sleepFor(ctx, t, emitDuration)
+
+		t.L().Printf("killing coordinator node")
+		m.ExpectDeath()
+		killed := timeutil.Now()
+		if err := c.StopE(ctx, t.L(), option.DefaultStopOpts(), nCoord); err != nil {
+			return err
+		}
+		sleepFor(ctx, t, downtime)
+		t.L().Printf("restarting coordinator node")
+		if err := c.StartE(ctx, t.L(), opts, settings, nCoord); err != nil {
+			return err
+		}
+		m.ResetDeaths()
+
+		t.L().Printf("waiting for changefeed watermark to reach %s", killed.Format(time.RFC3339))
+		info, err := waitForChangefeed(ctx, conn, jobID, t.L(), highWaterAtLeast(killed))
+		if err != nil {
+			return err
+		}
+		recovery = timeutil.Since(killed)
+		t.L().Printf("changefeed watermark is %s, recovered %s after the kill",
+			info.highwaterTime.Format(time.RFC3339), recovery.Truncate(time.Second))
+		return nil
+	})
+	m.Wait()
+
+	require.NoError(t, writeCDCBenchStats(ctx, t, c, nCoord, map[string]int64{
+		"recovery-seconds": int64(recovery / time.Second),
+		"downtime-seconds": int64(downtime / time.Second),
+	}))
+}
+
+// runCDCBenchSchemaChange measures how long a changefeed's emission stalls
+// when a column is added to its table mid-stream, while a KV workload writes to
+// the table. The changefeed uses the backfill schema change policy, so it
+// re-emits every row of the table at the schema change's timestamp before its
+// high-water can advance past it. The stall is measured from the completion of
+// the schema change until the high-water passes it, and recorded in stats.json
+// along with the number of messages emitted in the meantime.
+//
+// It sets up a cluster with N-1 data nodes, and a separate changefeed
+// coordinator node which also runs the workload.
+func runCDCBenchSchemaChange(
+	ctx context.Context, t test.Test, c cluster.Cluster, numRows, numRanges int64, format string,
+) {
+	const (
+		emitDuration = 5 * time.Minute
+		writeRate    = 1000
+		// The workload keeps writing through the schema change and the
+		// backfill.
+		duration = 30 * time.Minute
+	)
+	var (
+		numNodes    = c.Spec().NodeCount
+		nData       = c.Range(1, numNodes-1)
+		nCoord      = c.Node(numNodes)
+		concurrency = len(nData) * 16
+	)
+
+	// Start data nodes first to place data on them. We'll start the changefeed
+	// coordinator later, since we don't want any data on it.
+	opts, settings := makeCDCBenchOptions(c, cdcBenchClusterOpts{})
+	c.Start(ctx, t.L(), opts, settings, nData)
+	m := c.NewMonitor(ctx, nData.Merge(nCoord))
+
+	conn := c.Conn(ctx, t.L(), nData[0])
+	defer conn.Close()
+
+	// Prohibit ranges on the changefeed coordinator.
+	t.L().Printf("configuring zones")
+	for _, target := range getAllZoneTargets(ctx, t, conn) {
+		_, err := conn.ExecContext(ctx, fmt.Sprintf(
+			`ALTER %s CONFIGURE ZONE USING num_replicas=3, constraints='[-node%d]'`, target, nCoord[0]))
+		require.NoError(t, err)
+	}
+
+	// Wait for system ranges to upreplicate.
+	require.NoError(t, WaitFor3XReplication(ctx, t, t.L(), conn))
+
+	// Create and split the workload table, then import data into it. The
+	// import happens separately, because it imports before splitting otherwise,
+	// which takes a very long time.
+	t.L().Printf("creating table with %s ranges", humanize.Comma(numRanges))
+	c.Run(ctx, option.WithNodes(nCoord), fmt.Sprintf(
+		`./cockroach workload init kv --splits %d {pgurl:%d}`, numRanges, nData[0]))
+	require.NoError(t, WaitFor3XReplication(ctx, t, t.L(), conn))
+
+	t.L().Printf("ingesting %s rows using import", humanize.Comma(numRows))
+	c.Run(ctx, option.WithNodes(nCoord), fmt.Sprintf(
+		`./cockroach workload init kv --insert-count %d --data-loader import {pgurl:%d}`,
+		numRows, nData[0]))
+
+	// Now that the ranges are placed, start the changefeed coordinator.
+	t.L().Printf("starting coordinator node")
+	c.Start(ctx, t.L(), opts, settings, nCoord)
+
+	conn = c.Conn(ctx, t.L(), nCoord[0])
+	defer conn.Close()
+
+	// The schema isn't locked like in the other benchmarks, since that would
+	// prevent the schema change. The changefeed backfills on column changes
+	// rather than failing or stopping.
+	t.L().Printf("starting changefeed")
+	var jobID int
+	require.NoError(t, conn.QueryRowContext(ctx, fmt.Sprintf(
+		`CREATE CHANGEFEED FOR kv.kv INTO 'null://' WITH format = '%s', initial_scan = 'no', `+
+			`min_checkpoint_frequency = '1s', schema_change_events = 'column_changes', `+
+			`schema_change_policy = 'backfill'`, format)).
+		Scan(&jobID))
+
+	now := timeutil.Now()
+	t.L().Printf("waiting for changefeed watermark to reach current time (%s)", now.Format(time.RFC3339))
+	info, err := waitForChangefeed(ctx, conn, jobID, t.L(), highWaterAtLeast(now))
+	require.NoError(t, err)
+	t.L().Printf("changefeed watermark is %s", info.highwaterTime.Format(time.RFC3339))
+
+	m.Go(func(ctx context.Context) error {
+		t.L().Printf("running workload for %s", duration)
+		return c.RunE(ctx, option.WithNodes(nCoord), fmt.Sprintf(
+			`./cockroach workload run kv --read-percent 0 --concurrency %d --max-rate %d --duration %s {pgurl%s}`,
+			concurrency, writeRate, duration, nData))
+	})
+
+	// Let the changefeed emit the workload's writes, then add a column and wait
+	// for the changefeed to emit past the schema change.
+	var alterDuration, stall time.Duration
+	var emittedMessages int64
+	m.Go(func(ctx context.Context) error {
+		t.L().Printf("letting changefeed emit for %s", emitDuration)
+		sleepFor(ctx, t, emitDuration)
+
+		emittedMessagesBefore := cdcBenchNodeMetricSum(ctx, t, c, nData.Merge(nCoord), "changefeed.emitted_messages")
+		t.L().Printf("adding column")
+		alterStart := timeutil.Now()
+		if _, err := conn.ExecContext(ctx,
+			`ALTER TABLE kv.kv ADD COLUMN extra INT NOT NULL DEFAULT 0`); err != nil {
+			return err
+		}
+		alterDone := timeutil.Now()
+		alterDuration = alterDone.Sub(alterStart)
+		t.L().Printf("added column in %s", alterDuration.Truncate(time.Second))
+
+		t.L().Printf("waiting for changefeed watermark to reach %s", alterDone.Format(time.RFC3339))
+		info, err := waitForChangefeed(ctx, conn, jobID, t.L(), highWaterAtLeast(alterDone))
+		if err != nil {
+			return err
+		}
+		stall = timeutil.Since(alterDone)
+		emittedMessages = cdcBenchNodeMetricSum(ctx, t, c, nData.Merge(nCoord), "changefeed.emitted_messages") -
+			emittedMessagesBefore
+		t.L().Printf("changefeed watermark is %s, stalled for %s after the schema change and emitted %s messages",
+			info.highwaterTime.Format(time.RFC3339), stall.Truncate(time.Second), humanize.Comma(emittedMessages))
+		return nil
+	})
+	m.Wait()
+
+	require.NoError(t, writeCDCBenchStats(ctx, t, c, nCoord, map[string]int64{
+		"schema-change-stall-seconds":    int64(stall / time.Second),
+		"schema-change-duration-seconds": int64(alterDuration / time.Second),
+		"schema-change-emitted-messages": emittedMessages,
+	}))
+}
+
+// runCDCBenchEmission measures the steady-state throughput and latency of a
+// changefeed emitting the rows written by a KV workload at a fixed rate, for a
+// fixed duration. Unlike runCDCBenchWorkload, it doesn't backpressure writers,
+// so that it measures the changefeed rather than the impact on the workload.
+// The rate at which rows were emitted, the commit latency of the emitted rows
+// and the time it took the changefeed to catch up with the workload once it
+// completed are recorded in stats.json.
+//
+// It sets up a cluster with N-1 data nodes, and a separate changefeed
+// coordinator node, which also runs the workload.
+func runCDCBenchEmission(
+	ctx context.Context,
+	t test.Test,
+	c cluster.Cluster,
+	mode cdcBenchEmissionMode,
+	numRows, numRanges int64,
+	duration time.Duration,
+	writeRate int,
+	format string,
+) {
+	var (
+		numNodes    = c.Spec().NodeCount
+		nData       = c.Range(1, numNodes-1)
+		nCoord      = c.Node(numNodes)
+		concurrency = len(nData) * 64
 	)
-	if readPercent == 100 {
-		insertCount = 1_000_000 // ingest some data to read
-	}
 
 	// Start data nodes first to place data on them. We'll start the changefeed
 	// coordinator later, since we don't want any data on it.
-	opts, settings := makeCDCBenchOptions(c)
-	settings.ClusterSettings["kv.rangefeed.enabled"] = strconv.FormatBool(cdcEnabled)
-
+	opts, settings := makeCDCBenchOptions(c, cdcBenchClusterOpts{})
 	c.Start(ctx, t.L(), opts, settings, nData)
 	m := c.NewMonitor(ctx, nData.Merge(nCoord))
 
@@ -393,27 +3507,18 @@ func runCDCBenchWorkload(
 	// Wait for system ranges to upreplicate.
 	require.NoError(t, WaitFor3XReplication(ctx, t, t.L(), conn))
 
-	// Create and split the workload table.
-	//
-	// NB: don't scatter -- the ranges end up fairly well-distributed anyway, and
-	// the scatter can often fail with 100k ranges.
+	// Create and split the workload table, then import data into it. The
+	// import happens separately, because it imports before splitting otherwise,
+	// which takes a very long time.
 	t.L().Printf("creating table with %s ranges", humanize.Comma(numRanges))
-	c.Run(ctx, option.WithNodes(nWorkload), fmt.Sprintf(
+	c.Run(ctx, option.WithNodes(nCoord), fmt.Sprintf(
 		`./cockroach workload init kv --splits %d {pgurl:%d}`, numRanges, nData[0]))
 	require.NoError(t, WaitFor3XReplication(ctx, t, t.L(), conn))
 
-	// For read-only workloads, ingest some data. init --insert-count does not use
-	// the standard key generator that the read workload uses, so we have to write
-	// them with a separate write workload first, see:
-	// https://github.com/cockroachdb/cockroach/issues/107874
-	if insertCount > 0 {
-		const batchSize = 1000
-		batches := (insertCount-1)/batchSize + 1 // ceiling division
-		t.L().Printf("ingesting %s rows", humanize.Comma(insertCount))
-		c.Run(ctx, option.WithNodes(nWorkload), fmt.Sprintf(
-			`./cockroach workload run kv --seed %d --read-percent 0 --batch %d --max-ops %d {pgurl:%d}`,
-			workloadSeed, batchSize, batches, nData[0]))
-	}
+	t.L().Printf("ingesting %s rows using import", humanize.Comma(numRows))
+	c.Run(ctx, option.WithNodes(nCoord), fmt.Sprintf(
+		`./cockroach workload init kv --insert-count %d --data-loader import {pgurl:%d}`,
+		numRows, nData[0]))
 
 	// Now that the ranges are placed, start the changefeed coordinator.
 	t.L().Printf("starting coordinator node")
@@ -422,108 +3527,269 @@ func runCDCBenchWorkload(
 	conn = c.Conn(ctx, t.L(), nCoord[0])
 	defer conn.Close()
 
-	// Start the changefeed if enabled. We disable the initial scan, since we
-	// don't care about the historical data.
+	// Lock schema so that changefeed schema feed runs under fast path.
+	_, err := conn.ExecContext(ctx, "ALTER TABLE kv.kv  SET (schema_locked = true);")
+	require.NoError(t, err)
+
+	// Start the changefeed without an initial scan, since only the rows written
+	// by the workload are measured, and wait for its watermark to reach the
+	// current time before starting the workload. The changefeed checkpoints
+	// every second, so that its lag can be sampled from the job's high-water.
+	t.L().Printf("starting changefeed")
 	var jobID int
-	var done atomic.Value // time.Time
-	if cdcEnabled {
-		t.L().Printf("starting changefeed")
+	require.NoError(t, conn.QueryRowContext(ctx, fmt.Sprintf(
+		`CREATE CHANGEFEED FOR kv.kv INTO 'null://' WITH format = '%s', initial_scan = 'no', `+
+			`min_checkpoint_frequency = '1s'`, format)).
+		Scan(&jobID))
 
-		// Lock schema so that changefeed schema feed runs under fast path.
-		_, err := conn.ExecContext(ctx, "ALTER TABLE kv.kv  SET (schema_locked = true);")
-		require.NoError(t, err)
+	now := timeutil.Now()
+	t.L().Printf("waiting for changefeed watermark to reach current time (%s)", now.Format(time.RFC3339))
+	info, err := waitForChangefeed(ctx, conn, jobID, t.L(), highWaterAtLeast(now))
+	require.NoError(t, err)
+	t.L().Printf("changefeed watermark is %s", info.highwaterTime.Format(time.RFC3339))
 
-		require.NoError(t, conn.QueryRowContext(ctx, fmt.Sprintf(
-			`CREATE CHANGEFEED FOR kv.kv INTO '%s' WITH format = '%s', initial_scan = 'no'`,
-			sink, format)).
-			Scan(&jobID))
+	emittedMessagesBefore := cdcBenchNodeMetricSum(ctx, t, c, nData.Merge(nCoord), "changefeed.emitted_messages")
 
-		// Monitor the changefeed for failures. When the workload finishes, it will
-		// store the completion timestamp in done, and we'll wait for the
-		// changefeed's watermark to reach it.
-		//
-		// The watermark and lag isn't recorded by the benchmark, but we make sure
-		// all data is eventually emitted. It is also helpful for inspection, and we
-		// may want to track or assert on it later. Initially, this asserted that
-		// the changefeed wasn't lagging by more than 1-2 minutes, but with 100k
-		// ranges it was found to sometimes lag by over 8 minutes.
-		m.Go(func(ctx context.Context) error {
-			info, err := waitForChangefeed(ctx, conn, jobID, t.L(), func(info changefeedInfo) (bool, error) {
-				switch jobs.Status(info.status) {
-				case jobs.StatusPending, jobs.StatusRunning:
-					doneValue := done.Load()
-					return doneValue != nil && info.highwaterTime.After(doneValue.(time.Time)), nil
-				default:
-					return false, errors.Errorf("unexpected changefeed status %s", info.status)
-				}
-			})
+	// Sample the lag of the changefeed behind the current time while the
+	// workload runs. The changefeed has emitted every row written before its
+	// high-water, so the lag is an upper bound on the end-to-end latency of the
+	// emitted rows. The distribution of the samples is recorded in stats.json.
+	lagReg := histogram.NewRegistry(10*time.Minute, histogram.MockWorkloadName)
+	workloadDone := make(chan struct{})
+	m.Go(func(ctx context.Context) error {
+		lagHist := lagReg.GetHandle().Get("changefeed-lag")
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-workloadDone:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			info, err := getChangefeedInfo(conn, jobID)
 			if err != nil {
 				return err
 			}
-			t.L().Printf("changefeed watermark is %s", info.highwaterTime.Format(time.RFC3339))
-			return nil
-		})
-
-		// Wait for a stable changefeed before starting the workload, by waiting for
-		// the watermark to reach the current time.
-		now := timeutil.Now()
-		t.L().Printf("waiting for changefeed watermark to reach current time (%s)",
-			now.Format(time.RFC3339))
-		info, err := waitForChangefeed(ctx, conn, jobID, t.L(), func(info changefeedInfo) (bool, error) {
-			switch jobs.Status(info.status) {
-			case jobs.StatusPending, jobs.StatusRunning:
-				return info.highwaterTime.After(now), nil
-			default:
-				return false, errors.Errorf("unexpected changefeed status %s", info.status)
-			}
-		})
-		require.NoError(t, err)
-		t.L().Printf("changefeed watermark is %s", info.highwaterTime.Format(time.RFC3339))
-
-	} else {
-		t.L().Printf("control run, not starting changefeed")
-	}
+			lagHist.Record(timeutil.Since(info.highwaterTime))
+		}
+	})
 
-	// Run the workload and record stats. Make sure to use the same seed, so we
-	// read any rows we wrote above.
+	// Run the workload, then wait for the changefeed to catch up with it. The
+	// commit latency is sampled right as the workload completes, since the
+	// changefeed metrics only keep the latency of recent rows.
+	var emitDuration, catchupDuration time.Duration
+	var commitLatencyP50, commitLatencyP99 int64
 	m.Go(func(ctx context.Context) error {
-		// If there's more than 10,000 replicas per node they may struggle to
-		// maintain RPC connections or liveness, which occasionally fails client
-		// write requests with ambiguous errors. We tolerate errors in this case
-		// until we optimize rangefeeds.
-		//
-		// TODO(erikgrinaker): remove this when benchmarks are stable.
 		var extra string
-		if readPercent < 100 && (numRanges/int64(len(nData))) >= 10000 {
-			extra += ` --tolerate-errors`
+		if mode == cdcBenchEmissionSequential {
+			extra = " --sequential"
 		}
-		t.L().Printf("running workload")
-		err := c.RunE(ctx, option.WithNodes(nWorkload), fmt.Sprintf(
-			`./cockroach workload run kv --seed %d --histograms=%s/stats.json `+
-				`--concurrency %d --duration %s --write-seq R%d --read-percent %d %s {pgurl:%d-%d}`,
-			workloadSeed, t.PerfArtifactsDir(), concurrency, duration, insertCount, readPercent, extra,
-			nData[0], nData[len(nData)-1]))
-		if err != nil {
+		t.L().Printf("running %s workload at %s rows per second for %s",
+			mode, humanize.Comma(int64(writeRate)), duration)
+		start := timeutil.Now()
+		if err := c.RunE(ctx, option.WithNodes(nCoord), fmt.Sprintf(
+			`./cockroach workload run kv --read-percent 0 --concurrency %d --max-rate %d --duration %s%s {pgurl%s}`,
+			concurrency, writeRate, duration, extra, nData)); err != nil {
 			return err
 		}
+		done := timeutil.Now()
+		close(workloadDone)
 		t.L().Printf("workload completed")
 
-		// When the workload completes, signal the completion time to the changefeed
-		// monitor via done, which will wait for it to fully catch up.
-		if cdcEnabled {
-			now := timeutil.Now()
-			done.Store(now)
-			info, err := getChangefeedInfo(conn, jobID)
-			if err != nil {
-				return err
+		for _, node := range nData.Merge(nCoord) {
+			if p50 := int64(nodeMetric(ctx, t, c, node, "changefeed.commit_latency-p50")); p50 > commitLatencyP50 {
+				commitLatencyP50 = p50
+			}
+			if p99 := int64(nodeMetric(ctx, t, c, node, "changefeed.commit_latency-p99")); p99 > commitLatencyP99 {
+				commitLatencyP99 = p99
 			}
-			t.L().Printf("waiting for changefeed watermark to reach %s (lagging by %s)",
-				now.Format(time.RFC3339), now.Sub(info.highwaterTime).Truncate(time.Second))
 		}
+
+		t.L().Printf("waiting for changefeed watermark to reach %s", done.Format(time.RFC3339))
+		info, err := waitForChangefeed(ctx, conn, jobID, t.L(), highWaterAtLeast(done))
+		if err != nil {
+			return err
+		}
+		emitDuration = timeutil.Since(start)
+		catchupDuration = timeutil.Since(done)
+		t.L().Printf("changefeed watermark is %s, caught up %s after the workload completed",
+			info.highwaterTime.Format(time.RFC3339), catchupDuration.Truncate(time.Second))
 		return nil
 	})
-
 	m.Wait()
+
+	// The rate covers the time it took the changefeed to catch up, so that it
+	// includes all the rows written by the workload.
+	emittedMessages := cdcBenchNodeMetricSum(ctx, t, c, nData.Merge(nCoord), "changefeed.emitted_messages") -
+		emittedMessagesBefore
+	emitRate := int64(float64(emittedMessages) / emitDuration.Seconds())
+	t.L().Printf("changefeed emitted %s rows (%s rows per second) with p50/p99 commit latency %s/%s",
+		humanize.Comma(emittedMessages), humanize.Comma(emitRate),
+		time.Duration(commitLatencyP50).Truncate(time.Millisecond),
+		time.Duration(commitLatencyP99).Truncate(time.Millisecond))
+
+	stats, err := makeCDCBenchStatsRegistries(map[string]int64{
+		"emit-rate":             emitRate,
+		"emitted-messages":      emittedMessages,
+		"commit-latency-p50-ms": commitLatencyP50 / int64(time.Millisecond),
+		"commit-latency-p99-ms": commitLatencyP99 / int64(time.Millisecond),
+		"catchup-duration-ms":   int64(catchupDuration / time.Millisecond),
+		"workload-write-rate":   int64(writeRate),
+	})
+	require.NoError(t, err)
+	require.NoError(t, writeCDCBenchHistogram(ctx, t, c, nCoord, append(stats, lagReg)...))
+}
+
+// cdcBenchDataset describes the benchmark's kv.kv table.
+type cdcBenchDataset struct {
+	rows, ranges int64
+	// columns are the names and types of the columns, in order, e.g. "k INT8".
+	columns  []string
+	families int64
+	// replicas is the replication factor of the table's zone.
+	replicas int64
+}
+
+// cdcBenchDatasetColumns returns the columns of the kv.kv table created for a
+// benchmark with the given schema and number of column families, in the format
+// of cdcBenchDataset.columns.
+func cdcBenchDatasetColumns(schema cdcBenchSchema, families int) []string {
+	columns := []string{"k INT8", "v BYTES"}
+	if schema == cdcBenchSchemaJSONB {
+		columns = append(columns, "j JSONB")
+	}
+	for i := 1; i < families; i++ {
+		columns = append(columns, fmt.Sprintf("f%d STRING", i))
+	}
+	return columns
+}
+
+// getCDCBenchDataset returns the benchmark's kv.kv table, or the zero value if
+// the table doesn't exist.
+func getCDCBenchDataset(ctx context.Context, conn *gosql.DB) (cdcBenchDataset, error) {
+	var d cdcBenchDataset
+	var exists bool
+	if err := conn.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM crdb_internal.tables WHERE database_name = 'kv' AND name = 'kv')`,
+	).Scan(&exists); err != nil || !exists {
+		return cdcBenchDataset{}, err
+	}
+	if err := conn.QueryRowContext(ctx, `SELECT count(*) FROM kv.kv`).Scan(&d.rows); err != nil {
+		return cdcBenchDataset{}, err
+	}
+	if err := conn.QueryRowContext(ctx,
+		`SELECT count(*) FROM [SHOW RANGES FROM TABLE kv.kv]`).Scan(&d.ranges); err != nil {
+		return cdcBenchDataset{}, err
+	}
+	rows, err := conn.QueryContext(ctx, `SELECT column_name || ' ' || data_type FROM [SHOW COLUMNS FROM kv.kv]`)
+	if err != nil {
+		return cdcBenchDataset{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return cdcBenchDataset{}, err
+		}
+		d.columns = append(d.columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return cdcBenchDataset{}, err
+	}
+	if err := conn.QueryRowContext(ctx, `SELECT
+    jsonb_array_length(
+        crdb_internal.pb_to_json('cockroach.sql.sqlbase.Descriptor', descriptor)->'table'->'families'
+    )
+FROM system.descriptor
+WHERE id = 'kv.kv'::REGCLASS::OID`).Scan(&d.families); err != nil {
+		return cdcBenchDataset{}, err
+	}
+	if err := conn.QueryRowContext(ctx, `SELECT
+    regexp_extract(regexp_extract(raw_config_sql, e'num_replicas = \\d+'), e'\\d+')::INT8
+FROM [SHOW ZONE CONFIGURATION FOR TABLE kv.kv]`).Scan(&d.replicas); err != nil {
+		return cdcBenchDataset{}, err
+	}
+	return d, nil
+}
+
+// checkCDCBenchDataset returns whether the existing dataset can be reused for a
+// benchmark that requested the given one. An empty dataset isn't reused, and a
+// dataset that doesn't match the request fails the check. The table is split
+// into numRanges+1 ranges, and may split a bit further, so the range count
+// must only be close to the requested one.
+func checkCDCBenchDataset(existing, requested cdcBenchDataset) (bool, error) {
+	if existing.rows == 0 {
+		return false, nil
+	}
+	if existing.rows != requested.rows || existing.ranges < requested.ranges ||
+		existing.ranges > requested.ranges+requested.ranges/10+1 {
+		return false, errors.Errorf("existing dataset has %s rows in %s ranges, but %s rows in %s ranges were requested",
+			humanize.Comma(existing.rows), humanize.Comma(existing.ranges),
+			humanize.Comma(requested.rows), humanize.Comma(requested.ranges))
+	}
+	if !slices.Equal(existing.columns, requested.columns) {
+		return false, errors.Errorf("existing dataset has columns %v, but %v were requested",
+			existing.columns, requested.columns)
+	}
+	if existing.families != requested.families {
+		return false, errors.Errorf("existing dataset has %d column families, but %d were requested",
+			existing.families, requested.families)
+	}
+	if existing.replicas != requested.replicas {
+		return false, errors.Errorf("existing dataset has %dx replication, but %dx was requested",
+			existing.replicas, requested.replicas)
+	}
+	return true, nil
+}
+
+// rangeReplicas are the nodes with replicas of a range.
+type rangeReplicas struct {
+	rangeID  int64
+	replicas []int64
+}
+
+// getRangeReplicas returns the replicas of all ranges in the cluster.
+func getRangeReplicas(ctx context.Context, conn *gosql.DB) ([]rangeReplicas, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT range_id, replicas FROM crdb_internal.ranges_no_leases`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ranges []rangeReplicas
+	for rows.Next() {
+		var r rangeReplicas
+		if err := rows.Scan(&r.rangeID, pq.Array(&r.replicas)); err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, rows.Err()
+}
+
+// assertNoRangesOnNode returns an error if any of the given ranges has a
+// replica on the given node.
+func assertNoRangesOnNode(ranges []rangeReplicas, node int) error {
+	var onNode []int64
+	for _, r := range ranges {
+		for _, replica := range r.replicas {
+			if replica == int64(node) {
+				onNode = append(onNode, r.rangeID)
+				break
+			}
+		}
+	}
+	if len(onNode) > 0 {
+		const maxListed = 10
+		listed := onNode
+		if len(listed) > maxListed {
+			listed = listed[:maxListed]
+		}
+		return errors.Errorf("%d ranges have replicas on node %d, including ranges %v",
+			len(onNode), node, listed)
+	}
+	return nil
 }
 
 // getAllZoneTargets returns all zone targets (e.g. "RANGE default", "DATABASE
@@ -541,7 +3807,65 @@ func getAllZoneTargets(ctx context.Context, t test.Test, conn *gosql.DB) []strin
 	return targets
 }
 
-// waitForChangefeed waits until the changefeed satisfies the given closure.
+// cdcBenchScanStallTimeout is how long a changefeed scan can go without making
+// progress before the benchmark fails.
+const cdcBenchScanStallTimeout = 15 * time.Minute
+
+// cdcBenchStallDetector detects changefeeds that stop making progress. Progress
+// is an increase of either the fraction completed or the high-water of the
+// changefeed, since a changefeed only reports one of them at a time.
+type cdcBenchStallDetector struct {
+	timeout time.Duration
+
+	// lastProgress is when progress was last observed, and is zero until the
+	// first observation.
+	lastProgress time.Time
+	fraction     float32
+	highWater    time.Time
+}
+
+// observe records the progress of the changefeed at the given time, and
+// returns an error if the changefeed hasn't made progress within the timeout.
+func (d *cdcBenchStallDetector) observe(now time.Time, info changefeedInfo) error {
+	if d.lastProgress.IsZero() || info.fractionCompleted > d.fraction || info.highwaterTime.After(d.highWater) {
+		d.lastProgress = now
+		d.fraction = info.fractionCompleted
+		d.highWater = info.highwaterTime
+		return nil
+	}
+	if stalled := now.Sub(d.lastProgress); stalled >= d.timeout {
+		return errors.Errorf("changefeed made no progress in %d minutes (%.1f%% scanned, high-water %s)",
+			int(stalled.Minutes()), d.fraction*100, d.highWater.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// changefeedPausePolicy determines how waitForChangefeedWithOpts treats a
+// paused changefeed.
+type changefeedPausePolicy int
+
+const (
+	// changefeedPausedIsError fails the wait once the changefeed is paused or
+	// pausing.
+	changefeedPausedIsError changefeedPausePolicy = iota
+	// changefeedPausedIsWaiting keeps waiting while the changefeed is paused or
+	// pausing, without calling the closure. This is for callers that pause the
+	// changefeed themselves and will resume it.
+	changefeedPausedIsWaiting
+)
+
+// changefeedWaitOpts are options for waitForChangefeedWithOpts.
+type changefeedWaitOpts struct {
+	pausePolicy changefeedPausePolicy
+	// pollInterval is how often the changefeed info is loaded. Defaults to 5s.
+	pollInterval time.Duration
+	// getInfo loads the changefeed info. Defaults to reading the job record,
+	// and is only overridden in tests.
+	getInfo func() (*changefeedInfo, error)
+}
+
+// waitForChangefeed waits until the changefeed satisfies the given closure. A
+// paused changefeed fails the wait, as does a terminal changefeed error.
 func waitForChangefeed(
 	ctx context.Context,
 	conn *gosql.DB,
@@ -549,7 +3873,28 @@ func waitForChangefeed(
 	logger *logger.Logger,
 	f func(changefeedInfo) (bool, error),
 ) (changefeedInfo, error) {
-	ticker := time.NewTicker(5 * time.Second)
+	return waitForChangefeedWithOpts(ctx, conn, jobID, logger, changefeedWaitOpts{}, f)
+}
+
+// waitForChangefeedWithOpts is like waitForChangefeed, but with the given
+// options.
+func waitForChangefeedWithOpts(
+	ctx context.Context,
+	conn *gosql.DB,
+	jobID int,
+	logger *logger.Logger,
+	opts changefeedWaitOpts,
+	f func(changefeedInfo) (bool, error),
+) (changefeedInfo, error) {
+	if opts.pollInterval == 0 {
+		opts.pollInterval = 5 * time.Second
+	}
+	if opts.getInfo == nil {
+		opts.getInfo = func() (*changefeedInfo, error) {
+			return getChangefeedInfo(conn, jobID)
+		}
+	}
+	ticker := time.NewTicker(opts.pollInterval)
 	defer ticker.Stop()
 	const maxLoadJobAttempts = 5
 	for loadJobAttempt := 0; ; loadJobAttempt++ {
@@ -559,7 +3904,7 @@ func waitForChangefeed(
 			return changefeedInfo{}, ctx.Err()
 		}
 
-		info, err := getChangefeedInfo(conn, jobID)
+		info, err := opts.getInfo()
 		if err != nil {
 			logger.Errorf("error getting changefeed info: %v (attempt %d)", err, loadJobAttempt+1)
 			if loadJobAttempt > 5 {
@@ -567,20 +3912,100 @@ func waitForChangefeed(
 			}
 			continue
 		} else if info.errMsg != "" {
-			return changefeedInfo{}, errors.Errorf("changefeed error: %s", info.errMsg)
+			if !changefeedErrorIsRetryable(*info) {
+				return changefeedInfo{}, errors.Errorf("changefeed error: %s", info.errMsg)
+			}
+			// The changefeed will retry, so let the closure observe its progress.
+			logger.Printf("changefeed %s with retryable error: %s", info.status, info.errMsg)
+		}
+		loadJobAttempt = 0
+		switch jobs.Status(info.status) {
+		case jobs.StatusPaused, jobs.StatusPauseRequested:
+			if opts.pausePolicy == changefeedPausedIsError {
+				return changefeedInfo{}, errors.Errorf("changefeed is %s", info.status)
+			}
+			logger.Printf("changefeed is %s, waiting for it to resume", info.status)
+			continue
 		}
 		if ok, err := f(*info); err != nil {
 			return changefeedInfo{}, err
 		} else if ok {
 			return *info, nil
 		}
-		loadJobAttempt = 0
 	}
 }
 
-// writeCDCBenchStats writes a single perf metric into stats.json on the
+// changefeedErrorIsRetryable returns whether the error recorded in the job of
+// the changefeed is retryable, i.e. whether the job is still active and will
+// retry. Errors of a job in any other status are terminal.
+func changefeedErrorIsRetryable(info changefeedInfo) bool {
+	switch jobs.Status(info.status) {
+	case jobs.StatusPending, jobs.StatusRunning, jobs.StatusReverting:
+		return true
+	default:
+		return false
+	}
+}
+
+// highWaterAtLeast returns a waitForChangefeed predicate that is satisfied once
+// the high-water timestamp of the changefeed reaches the given timestamp.
+// It fails if the changefeed stops without reaching it.
+func highWaterAtLeast(ts time.Time) func(changefeedInfo) (bool, error) {
+	return func(info changefeedInfo) (bool, error) {
+		reached := !info.highwaterTime.Before(ts)
+		switch jobs.Status(info.status) {
+		case jobs.StatusPending, jobs.StatusRunning:
+			return reached, nil
+		case jobs.StatusSucceeded:
+			if !reached {
+				return false, errors.Errorf("changefeed succeeded with high-water %s below %s",
+					info.highwaterTime.Format(time.RFC3339), ts.Format(time.RFC3339))
+			}
+			return true, nil
+		default:
+			return false, errors.Errorf("unexpected changefeed status %q", info.status)
+		}
+	}
+}
+
+// runningStatusContains returns a waitForChangefeed predicate that is satisfied
+// once the running status of the changefeed contains the given string, e.g. to
+// wait for the changefeed to reach a specific phase. It fails if the changefeed
+// stops running.
+func runningStatusContains(substr string) func(changefeedInfo) (bool, error) {
+	return func(info changefeedInfo) (bool, error) {
+		switch jobs.Status(info.status) {
+		case jobs.StatusPending, jobs.StatusRunning:
+			return strings.Contains(info.runningStatus, substr), nil
+		default:
+			return false, errors.Errorf("unexpected changefeed status %q", info.status)
+		}
+	}
+}
+
+// writeCDCBenchStats writes the given perf metrics into stats.json on the
 // given node, for graphing in roachperf.
 func writeCDCBenchStats(
+	ctx context.Context,
+	t test.Test,
+	c cluster.Cluster,
+	node option.NodeListOption,
+	metrics map[string]int64,
+) error {
+	regs, err := makeCDCBenchStatsRegistries(metrics)
+	if err != nil {
+		return err
+	}
+	return writeCDCBenchHistogram(ctx, t, c, node, regs...)
+}
+
+// writeCDCBenchStat writes a single perf metric into stats.json on the given
+// node, for graphing in roachperf. Like writeCDCBenchStats, it overwrites any
+// metrics written before, so benchmarks that record several metrics must
+// write them together with writeCDCBenchStats.
+//
+//lint:ignore U1000 the benchmarks in this file all record several metrics.
+func writeCDCBenchStat(
 	ctx context.Context,
 	t test.Test,
 	c cluster.Cluster,
@@ -588,18 +4013,56 @@ func writeCDCBenchStats(
 	metric string,
 	value int64,
 ) error {
-	// The easiest way to record a precise metric for roachperf is to cast it as a
-	// duration in seconds in the histogram's upper bound.
-	valueS := time.Duration(value) * time.Second
-	reg := histogram.NewRegistry(valueS, histogram.MockWorkloadName)
-	bytesBuf := bytes.NewBuffer([]byte{})
-	jsonEnc := json.NewEncoder(bytesBuf)
+	return writeCDCBenchStats(ctx, t, c, node, map[string]int64{metric: value})
+}
 
-	var err error
-	reg.GetHandle().Get(metric).Record(valueS)
-	reg.Tick(func(tick histogram.Tick) {
-		err = jsonEnc.Encode(tick.Snapshot())
-	})
+// makeCDCBenchStatsRegistries returns a histogram registry for each of the
+// given perf metrics, ordered by metric name, that records the metric as a
+// single value.
+//
+// The easiest way to record a precise metric for roachperf is to cast it as a
+// duration in seconds in the histogram's upper bound, which is why each metric
+// needs its own registry. The upper bound can't be below 1s, so a value of 0 is
+// recorded with an upper bound of 1s instead, and can be told apart from a
+// value of 1 by the recorded value, which is below 1s. Negative values can't be
+// recorded at all, and return an error.
+func makeCDCBenchStatsRegistries(metrics map[string]int64) ([]*histogram.Registry, error) {
+	names := make([]string, 0, len(metrics))
+	for metric := range metrics {
+		names = append(names, metric)
+	}
+	sort.Strings(names)
+	regs := make([]*histogram.Registry, 0, len(names))
+	for _, metric := range names {
+		value := metrics[metric]
+		if value < 0 {
+			return nil, errors.Errorf("metric %s has negative value %d, which can't be recorded",
+				metric, value)
+		}
+		valueS := time.Duration(value) * time.Second
+		upperBound := valueS
+		if upperBound < time.Second {
+			upperBound = time.Second
+		}
+		reg := histogram.NewRegistry(upperBound, histogram.MockWorkloadName)
+		reg.GetHandle().Get(metric).Record(valueS)
+		regs = append(regs, reg)
+	}
+	return regs, nil
+}
+
+// writeCDCBenchHistogram writes the histograms of the given registries into
+// stats.json on the given node, for graphing in roachperf. Unlike
+// writeCDCBenchStats, the histograms keep the distribution of the recorded
+// values, e.g. of latency samples, so that roachperf can plot percentiles.
+func writeCDCBenchHistogram(
+	ctx context.Context,
+	t test.Test,
+	c cluster.Cluster,
+	node option.NodeListOption,
+	regs ...*histogram.Registry,
+) error {
+	stats, err := encodeCDCBenchHistograms(regs...)
 	if err != nil {
 		return err
 	}
@@ -609,8 +4072,27 @@ func writeCDCBenchStats(
 	if err := c.RunE(ctx, option.WithNodes(node), "mkdir -p "+filepath.Dir(path)); err != nil {
 		return err
 	}
-	if err := c.PutString(ctx, bytesBuf.String(), path, 0755, node); err != nil {
+	if err := c.PutString(ctx, stats, path, 0755, node); err != nil {
 		return err
 	}
 	return nil
 }
+
+// encodeCDCBenchHistograms ticks the given registries and encodes a snapshot of
+// each of their histograms as a line of JSON, in the format of stats.json.
+func encodeCDCBenchHistograms(regs ...*histogram.Registry) (string, error) {
+	bytesBuf := bytes.NewBuffer([]byte{})
+	jsonEnc := json.NewEncoder(bytesBuf)
+	var err error
+	for _, reg := range regs {
+		reg.Tick(func(tick histogram.Tick) {
+			if err == nil {
+				err = jsonEnc.Encode(tick.Snapshot())
+			}
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return bytesBuf.String(), nil
+}
@@ -0,0 +1,456 @@
+// Copyright 2024 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/roachprod/logger"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/workload/histogram"
+	"github.com/codahale/hdrhistogram"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatSI(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for num, expected := range map[int64]string{
+		0:             "0",
+		100:           "100",
+		999:           "999",
+		1000:          "1k",
+		1500:          "1.5k",
+		100000:        "100k",
+		1_000_000_000: "1G",
+	} {
+		require.Equal(t, expected, formatSI(num), "formatSI(%d)", num)
+	}
+}
+
+func TestCheckCDCBenchDataset(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	requested := cdcBenchDataset{
+		rows:     1_000_000,
+		ranges:   100,
+		columns:  cdcBenchDatasetColumns(cdcBenchSchemaJSONB, 2),
+		families: 2,
+		replicas: 3,
+	}
+	with := func(f func(d *cdcBenchDataset)) cdcBenchDataset {
+		d := requested
+		d.ranges = 101
+		f(&d)
+		return d
+	}
+	for _, tc := range []struct {
+		name     string
+		existing cdcBenchDataset
+		reuse    bool
+		err      string
+	}{
+		{name: "empty"},
+		{name: "match", existing: with(func(d *cdcBenchDataset) {}), reuse: true},
+		{name: "further splits", existing: with(func(d *cdcBenchDataset) { d.ranges = 111 }), reuse: true},
+		{name: "fewer rows", existing: with(func(d *cdcBenchDataset) { d.rows = 999_999 }),
+			err: "999,999 rows in 101 ranges"},
+		{name: "fewer ranges", existing: with(func(d *cdcBenchDataset) { d.ranges = 99 }),
+			err: "1,000,000 rows in 99 ranges"},
+		{name: "more ranges", existing: with(func(d *cdcBenchDataset) { d.ranges = 1001 }),
+			err: "1,000,000 rows in 1,001 ranges"},
+		{name: "no JSONB column", existing: with(func(d *cdcBenchDataset) {
+			d.columns = cdcBenchDatasetColumns(cdcBenchSchemaKV, 2)
+		}), err: "existing dataset has columns [k INT8 v BYTES f1 STRING], but [k INT8 v BYTES j JSONB f1 STRING] were requested"},
+		{name: "fewer families", existing: with(func(d *cdcBenchDataset) { d.families = 1 }),
+			err: "existing dataset has 1 column families, but 2 were requested"},
+		{name: "replication", existing: with(func(d *cdcBenchDataset) { d.replicas = 5 }),
+			err: "existing dataset has 5x replication, but 3x was requested"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			reuse, err := checkCDCBenchDataset(tc.existing, requested)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.reuse, reuse)
+		})
+	}
+}
+
+func TestAssertNoRangesOnNode(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ranges := []rangeReplicas{
+		{rangeID: 1, replicas: []int64{1, 2, 3}},
+		{rangeID: 2, replicas: []int64{2, 3, 4}},
+		{rangeID: 3, replicas: []int64{1, 3, 4}},
+	}
+	require.NoError(t, assertNoRangesOnNode(ranges, 5))
+	require.NoError(t, assertNoRangesOnNode(nil, 1))
+	require.EqualError(t, assertNoRangesOnNode(ranges, 1),
+		"2 ranges have replicas on node 1, including ranges [1 3]")
+	require.EqualError(t, assertNoRangesOnNode(ranges, 4),
+		"2 ranges have replicas on node 4, including ranges [2 3]")
+}
+
+func TestCDCBenchScanWithOptions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	endTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		compression cdcBenchCompression
+		expected    string
+		err         string
+	}{
+		{
+			expected: "format = 'json', end_time = '2024-01-01T00:00:00Z', initial_scan = 'yes'",
+		},
+		{
+			compression: cdcBenchCompressionNone,
+			expected:    "format = 'json', end_time = '2024-01-01T00:00:00Z', initial_scan = 'yes'",
+		},
+		{
+			compression: cdcBenchCompressionGzip,
+			expected: "format = 'json', end_time = '2024-01-01T00:00:00Z', initial_scan = 'yes', " +
+				"compression = 'gzip'",
+		},
+		{compression: "zstd", err: `unknown compression "zstd"`},
+	} {
+		t.Run(string(tc.compression), func(t *testing.T) {
+			with, err := cdcBenchScanWithOptions(cdcBenchInitialScan, cdcBenchFormatJSON, endTime, time.Time{},
+				cdcBenchClusterOpts{sink: cdcBenchSinkCloudStorage, compression: tc.compression})
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, with)
+		})
+	}
+}
+
+func TestHighWaterAtLeast(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	cursor := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	payloadBytes, err := protoutil.Marshal(&jobspb.Payload{
+		Details: jobspb.WrapPayloadDetails(jobspb.ChangefeedDetails{}),
+	})
+	require.NoError(t, err)
+
+	// info returns the changefeed info of a job row with the given status, and
+	// a progress payload with the given high-water, if any.
+	info := func(status jobs.Status, highWater time.Time) changefeedInfo {
+		var progress jobspb.Progress
+		if !highWater.IsZero() {
+			progress.Progress = &jobspb.Progress_HighWater{
+				HighWater: &hlc.Timestamp{WallTime: highWater.UnixNano()},
+			}
+		}
+		progressBytes, err := protoutil.Marshal(&progress)
+		require.NoError(t, err)
+		info, err := parseChangefeedInfo(string(status), payloadBytes, progressBytes)
+		require.NoError(t, err)
+		return *info
+	}
+
+	for _, tc := range []struct {
+		status    jobs.Status
+		highWater time.Time
+		reached   bool
+		err       string
+	}{
+		{status: jobs.StatusRunning},
+		{status: jobs.StatusRunning, highWater: cursor.Add(-time.Second)},
+		{status: jobs.StatusRunning, highWater: cursor, reached: true},
+		{status: jobs.StatusPending, highWater: cursor.Add(time.Second), reached: true},
+		{status: jobs.StatusSucceeded, highWater: cursor.Add(time.Second), reached: true},
+		{status: jobs.StatusSucceeded, highWater: cursor.Add(-time.Second), err: "below"},
+		{status: jobs.StatusFailed, highWater: cursor, err: "unexpected changefeed status"},
+	} {
+		t.Run(string(tc.status), func(t *testing.T) {
+			reached, err := highWaterAtLeast(cursor)(info(tc.status, tc.highWater))
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.reached, reached)
+		})
+	}
+}
+
+func TestRunningStatusContains(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	payloadBytes, err := protoutil.Marshal(&jobspb.Payload{
+		Details: jobspb.WrapPayloadDetails(jobspb.ChangefeedDetails{}),
+	})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		status        jobs.Status
+		runningStatus string
+		reached       bool
+		err           string
+	}{
+		{status: jobs.StatusPending},
+		{status: jobs.StatusRunning, runningStatus: "running: resolved=1704067200.000000000,0"},
+		{status: jobs.StatusRunning, runningStatus: "initial scan", reached: true},
+		{status: jobs.StatusSucceeded, runningStatus: "initial scan", err: "unexpected changefeed status"},
+	} {
+		t.Run(fmt.Sprintf("%s/%s", tc.status, tc.runningStatus), func(t *testing.T) {
+			progressBytes, err := protoutil.Marshal(&jobspb.Progress{RunningStatus: tc.runningStatus})
+			require.NoError(t, err)
+			info, err := parseChangefeedInfo(string(tc.status), payloadBytes, progressBytes)
+			require.NoError(t, err)
+			require.Equal(t, tc.runningStatus, info.runningStatus)
+
+			reached, err := runningStatusContains("initial scan")(*info)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.reached, reached)
+		})
+	}
+}
+
+// changefeedInfoSequence returns a waitForChangefeedWithOpts info loader that
+// returns the given changefeed infos in order, and then keeps returning the
+// last one.
+func changefeedInfoSequence(infos ...changefeedInfo) func() (*changefeedInfo, error) {
+	return func() (*changefeedInfo, error) {
+		info := infos[0]
+		if len(infos) > 1 {
+			infos = infos[1:]
+		}
+		return &info, nil
+	}
+}
+
+func TestWaitForChangefeedPaused(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	l, err := logger.RootLogger(filepath.Join(t.TempDir(), "test.log"), logger.NoTee)
+	require.NoError(t, err)
+	cursor := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	infos := []changefeedInfo{
+		{status: string(jobs.StatusRunning), highwaterTime: cursor.Add(-time.Minute)},
+		{status: string(jobs.StatusPauseRequested), highwaterTime: cursor.Add(-time.Minute)},
+		{status: string(jobs.StatusPaused), highwaterTime: cursor.Add(-time.Minute)},
+		{status: string(jobs.StatusRunning), highwaterTime: cursor},
+	}
+
+	t.Run("waiting", func(t *testing.T) {
+		info, err := waitForChangefeedWithOpts(ctx, nil, 0, l, changefeedWaitOpts{
+			pausePolicy:  changefeedPausedIsWaiting,
+			pollInterval: time.Millisecond,
+			getInfo:      changefeedInfoSequence(infos...),
+		}, highWaterAtLeast(cursor))
+		require.NoError(t, err)
+		require.Equal(t, cursor, info.highwaterTime)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, err := waitForChangefeedWithOpts(ctx, nil, 0, l, changefeedWaitOpts{
+			pausePolicy:  changefeedPausedIsError,
+			pollInterval: time.Millisecond,
+			getInfo:      changefeedInfoSequence(infos...),
+		}, highWaterAtLeast(cursor))
+		require.ErrorContains(t, err, "changefeed is pause-requested")
+	})
+}
+
+func TestWaitForChangefeedRetryableError(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	l, err := logger.RootLogger(filepath.Join(t.TempDir(), "test.log"), logger.NoTee)
+	require.NoError(t, err)
+	cursor := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("retryable", func(t *testing.T) {
+		// The closure observes the progress of the changefeed while it retries.
+		var observed []time.Time
+		info, err := waitForChangefeedWithOpts(ctx, nil, 0, l, changefeedWaitOpts{
+			pollInterval: time.Millisecond,
+			getInfo: changefeedInfoSequence(
+				changefeedInfo{status: string(jobs.StatusRunning), highwaterTime: cursor.Add(-time.Minute)},
+				changefeedInfo{
+					status:        string(jobs.StatusRunning),
+					errMsg:        "retryable changefeed error: connection reset",
+					highwaterTime: cursor.Add(-time.Second),
+				},
+				changefeedInfo{status: string(jobs.StatusSucceeded), highwaterTime: cursor},
+			),
+		}, func(info changefeedInfo) (bool, error) {
+			observed = append(observed, info.highwaterTime)
+			return highWaterAtLeast(cursor)(info)
+		})
+		require.NoError(t, err)
+		require.Equal(t, cursor, info.highwaterTime)
+		require.Equal(t, []time.Time{cursor.Add(-time.Minute), cursor.Add(-time.Second), cursor}, observed)
+	})
+
+	t.Run("terminal", func(t *testing.T) {
+		_, err := waitForChangefeedWithOpts(ctx, nil, 0, l, changefeedWaitOpts{
+			pollInterval: time.Millisecond,
+			getInfo: changefeedInfoSequence(
+				changefeedInfo{status: string(jobs.StatusFailed), errMsg: "boom", highwaterTime: cursor},
+			),
+		}, highWaterAtLeast(cursor))
+		require.ErrorContains(t, err, "changefeed error: boom")
+	})
+}
+
+func TestCDCBenchStallDetector(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := cdcBenchStallDetector{timeout: 10 * time.Minute}
+	observe := func(elapsed time.Duration, fraction float32, highWater time.Time) error {
+		return d.observe(start.Add(elapsed), changefeedInfo{
+			status:            string(jobs.StatusRunning),
+			fractionCompleted: fraction,
+			highwaterTime:     highWater,
+		})
+	}
+
+	require.NoError(t, observe(0, 0, time.Time{}))
+	// The fraction advances, then stalls for less than the timeout.
+	require.NoError(t, observe(5*time.Minute, 0.5, time.Time{}))
+	require.NoError(t, observe(14*time.Minute, 0.5, time.Time{}))
+	// A high-water advance is also progress.
+	require.NoError(t, observe(15*time.Minute, 0, start))
+	require.NoError(t, observe(24*time.Minute, 0, start))
+	// No progress for the timeout fails.
+	err := observe(25*time.Minute, 0, start)
+	require.ErrorContains(t, err, "no progress in 10 minutes")
+}
+
+func TestEncodeCDCBenchHistograms(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// Record latencies of 1ms to 100ms, along with a scalar metric.
+	lagReg := histogram.NewRegistry(time.Minute, histogram.MockWorkloadName)
+	lagHist := lagReg.GetHandle().Get("changefeed-lag")
+	for i := 1; i <= 100; i++ {
+		lagHist.Record(time.Duration(i) * time.Millisecond)
+	}
+	stats, err := makeCDCBenchStatsRegistries(map[string]int64{"emit-rate": 5000})
+	require.NoError(t, err)
+
+	encoded, err := encodeCDCBenchHistograms(append(stats, lagReg)...)
+	require.NoError(t, err)
+
+	ticks := make(map[string]histogram.SnapshotTick)
+	dec := json.NewDecoder(strings.NewReader(encoded))
+	for {
+		var tick histogram.SnapshotTick
+		if err := dec.Decode(&tick); err == io.EOF {
+			break
+		} else {
+			require.NoError(t, err)
+		}
+		ticks[tick.Name] = tick
+	}
+	require.Len(t, ticks, 2)
+
+	// The histograms only have a single significant figure, so the recorded
+	// values are approximate.
+	lag := hdrhistogram.Import(ticks["changefeed-lag"].Hist)
+	require.EqualValues(t, 100, lag.TotalCount())
+	require.InEpsilon(t, 50*time.Millisecond, lag.ValueAtQuantile(50), 0.1)
+	require.InEpsilon(t, 99*time.Millisecond, lag.ValueAtQuantile(99), 0.1)
+
+	// Scalar metrics are recorded as a single value, and their exact value in
+	// seconds is the upper bound of their histogram.
+	rate := hdrhistogram.Import(ticks["emit-rate"].Hist)
+	require.EqualValues(t, 1, rate.TotalCount())
+	require.EqualValues(t, 5000*time.Second, rate.HighestTrackableValue())
+}
+
+func TestCDCBenchNodeCPUUtilization(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// Over 10s on nodes with 4 CPUs, the nodes use 10s, 20s and 40s of CPU
+	// time, i.e. 25%, 50% and 100% of their CPUs.
+	const cpus = 4
+	before := []int64{int64(time.Hour), 0, int64(time.Minute)}
+	after := []int64{
+		int64(time.Hour + 10*time.Second),
+		int64(20 * time.Second),
+		int64(time.Minute + 40*time.Second),
+	}
+	maxPct, meanPct := cdcBenchNodeCPUUtilization(before, after, 10*time.Second, cpus)
+	require.EqualValues(t, 100, maxPct)
+	require.EqualValues(t, 58, meanPct)
+
+	maxPct, meanPct = cdcBenchNodeCPUUtilization(nil, nil, 10*time.Second, cpus)
+	require.Zero(t, maxPct)
+	require.Zero(t, meanPct)
+}
+
+func TestCDCBenchStatsRoundTrip(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	metrics := map[string]int64{
+		"scan-rate":        1_234_567,
+		"node-cpu-max-pct": 87,
+		"catchup-lag-ms":   3,
+		"downtime-seconds": 1,
+		"budget-blocked":   0,
+	}
+	regs, err := makeCDCBenchStatsRegistries(metrics)
+	require.NoError(t, err)
+	encoded, err := encodeCDCBenchHistograms(regs...)
+	require.NoError(t, err)
+
+	// Every metric is encoded as its own tick in the same stream, with its
+	// exact value in seconds as the upper bound of its histogram, regardless
+	// of the other metrics. The upper bound of a value of 0 is 1s too, but the
+	// recorded value is below 1s.
+	decoded := make(map[string]int64)
+	dec := json.NewDecoder(strings.NewReader(encoded))
+	for dec.More() {
+		var tick histogram.SnapshotTick
+		require.NoError(t, dec.Decode(&tick))
+		hist := hdrhistogram.Import(tick.Hist)
+		require.EqualValues(t, 1, hist.TotalCount(), "metric %s", tick.Name)
+		if hist.Max() < int64(time.Second) {
+			decoded[tick.Name] = 0
+		} else {
+			decoded[tick.Name] = hist.HighestTrackableValue() / int64(time.Second)
+		}
+	}
+	require.Equal(t, metrics, decoded)
+
+	// Negative values can't be recorded.
+	_, err = makeCDCBenchStatsRegistries(map[string]int64{"budget-blocked": -1})
+	require.Error(t, err)
+}
@@ -753,7 +753,7 @@ func (n *alterDatabaseDropRegionNode) startExec(params runParams) error {
 		// TABLE table is explicitly homed in that region or a row in a REGIONAL BY
 		// ROW table is homed in that region. The type schema changer is responsible
 		// for all the requisite validation.
-		if err := params.p.dropEnumValue(params.ctx, typeDesc, tree.EnumValue(n.n.Region)); err != nil {
+		if err := params.p.dropEnumValue(params.ctx, typeDesc, tree.EnumValue(n.n.Region), nil /* replacement */); err != nil {
 			if pgerror.GetPGCode(err) == pgcode.UndefinedObject {
 				if n.n.IfExists {
 					params.p.BufferClientNotice(
@@ -11,40 +11,121 @@
 package sql
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/security/username"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/decodeusername"
+	"github.com/cockroachdb/cockroach/pkg/sql/enum"
+	"github.com/cockroachdb/cockroach/pkg/sql/oidext"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/catconstants"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/catid"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/volatility"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/intsets"
+	"github.com/cockroachdb/cockroach/pkg/util/iterutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/errors"
+	"github.com/lib/pq/oid"
+)
+
+// maxEnumValues is the maximum number of values that ALTER TYPE ... ADD VALUE
+// allows an enum to have. Enums with thousands of values perform poorly and
+// usually indicate that a table would be a better fit.
+var maxEnumValues = settings.RegisterIntSetting(
+	settings.ApplicationLevel,
+	"sql.enum.max_values",
+	"the maximum number of values an enum can have after ALTER TYPE ... ADD VALUE; "+
+		"0 means unlimited",
+	0,
+	settings.NonNegativeInt,
+)
+
+// maxEnumLabelLength is the maximum length of the labels that enum values are
+// added or renamed to, which is the same as in Postgres by default.
+var maxEnumLabelLength = settings.RegisterIntSetting(
+	settings.ApplicationLevel,
+	"sql.enum.max_label_length",
+	"the maximum length in bytes of the labels of the values of an enum that are "+
+		"added or renamed with ALTER TYPE; 0 means unlimited",
+	63,
+	settings.NonNegativeInt,
 )
 
 type alterTypeNode struct {
 	n      *tree.AlterType
 	prefix catalog.ResolvedObjectPrefix
 	desc   *typedesc.Mutable
+
+	// columns and rows are set by commands that return results, such as
+	// ALTER TYPE ... CHECK.
+	columns colinfo.ResultColumns
+	rows    []tree.Datums
+	rowIdx  int
+
+	// explain is set when the node is run by EXPLAIN ALTER TYPE ... DROP VALUE,
+	// which reports what would block the drop instead of executing it.
+	explain bool
+}
+
+// setExplainDropValue makes the node report the usages of the enum value
+// that would block ALTER TYPE ... DROP VALUE from executing rather than
+// dropping it, with the result columns of EXPLAIN. It returns false if the
+// command isn't a DROP VALUE without a replacement, which has no such usages.
+func (n *alterTypeNode) setExplainDropValue() bool {
+	if t, ok := n.n.Cmd.(*tree.AlterTypeDropValue); !ok || t.Replacement != nil {
+		return false
+	}
+	n.explain = true
+	n.columns = colinfo.ExplainPlanColumns
+	return true
 }
 
 // alterTypeNode implements planNode. We set n here to satisfy the linter.
 var _ planNode = &alterTypeNode{n: nil}
 
 func (p *planner) AlterType(ctx context.Context, n *tree.AlterType) (planNode, error) {
-	if err := checkSchemaChangeEnabled(
-		ctx,
-		p.ExecCfg(),
-		"ALTER TYPE",
-	); err != nil {
-		return nil, err
+	// CHECK and VALIDATE DATA only read the type and its usages.
+	var isReadOnly bool
+	switch n.Cmd.(type) {
+	case *tree.AlterTypeCheck, *tree.AlterTypeValidateData:
+		isReadOnly = true
+	}
+	if !isReadOnly {
+		if err := checkSchemaChangeEnabled(
+			ctx,
+			p.ExecCfg(),
+			"ALTER TYPE",
+		); err != nil {
+			return nil, err
+		}
 	}
 
 	// Resolve the type.
@@ -58,6 +139,12 @@ func (p *planner) AlterType(ctx context.Context, n *tree.AlterType) (planNode, e
 		return nil, err
 	}
 
+	if !isReadOnly {
+		if err := checkNoConcurrentTypeSchemaChange(desc, n.Cmd); err != nil {
+			return nil, err
+		}
+	}
+
 	switch desc.Kind {
 	case descpb.TypeDescriptor_ALIAS:
 		// The implicit array types are not modifiable.
@@ -67,8 +154,9 @@ func (p *planner) AlterType(ctx context.Context, n *tree.AlterType) (planNode, e
 			tree.AsStringWithFQNames(n.Type, &p.semaCtx.Annotations),
 		)
 	case descpb.TypeDescriptor_MULTIREGION_ENUM:
-		// Multi-region enums can't be directly modified except for OWNER TO.
-		if _, isAlterTypeOwner := n.Cmd.(*tree.AlterTypeOwner); !isAlterTypeOwner {
+		// Multi-region enums can't be directly modified except for OWNER TO. They
+		// can still be checked, since that doesn't modify them.
+		if _, isAlterTypeOwner := n.Cmd.(*tree.AlterTypeOwner); !isAlterTypeOwner && !isReadOnly {
 			return nil, errors.WithHint(
 				pgerror.Newf(
 					pgcode.WrongObjectType,
@@ -86,24 +174,74 @@ func (p *planner) AlterType(ctx context.Context, n *tree.AlterType) (planNode, e
 		)
 	}
 
-	return &alterTypeNode{
+	node := &alterTypeNode{
 		n:      n,
 		prefix: prefix,
 		desc:   desc,
-	}, nil
+	}
+	switch n.Cmd.(type) {
+	case *tree.AlterTypeCheck:
+		node.columns = colinfo.AlterTypeCheckColumns
+	case *tree.AlterTypeValidateData:
+		node.columns = colinfo.AlterTypeValidateDataColumns
+	}
+	return node, nil
 }
 
-func (n *alterTypeNode) startExec(params runParams) error {
-	telemetry.Inc(sqltelemetry.SchemaChangeAlterCounterWithExtra("type", n.n.Cmd.TelemetryName()))
+func (n *alterTypeNode) startExec(params runParams) (retErr error) {
+	if n.explain {
+		var err error
+		n.rows, err = params.p.explainDropEnumValue(
+			params.ctx, n.desc, n.n.Cmd.(*tree.AlterTypeDropValue).Val,
+		)
+		return err
+	}
+
+	// Only commands that succeed are counted.
+	defer func() {
+		if retErr == nil {
+			telemetry.Inc(sqltelemetry.SchemaChangeAlterCounterWithExtra("type", n.n.Cmd.TelemetryName()))
+		}
+	}()
+
+	// CHECK and VALIDATE DATA only report findings and don't modify the type,
+	// so there is no event to log.
+	switch n.n.Cmd.(type) {
+	case *tree.AlterTypeCheck:
+		var err error
+		n.rows, err = params.p.checkEnum(params.ctx, n.desc)
+		return err
+	case *tree.AlterTypeValidateData:
+		var err error
+		n.rows, err = params.p.validateEnumData(params.ctx, n.desc)
+		return err
+	}
 
 	typeName := tree.AsStringWithFQNames(n.n.Type, params.p.Ann())
+	event := &eventpb.AlterType{
+		TypeName: typeName,
+		Command:  n.n.Cmd.TelemetryName(),
+	}
 	eventLogDone := false
 	var err error
 	switch t := n.n.Cmd.(type) {
 	case *tree.AlterTypeAddValue:
+		event.NewValue = string(t.NewVal)
 		err = params.p.addEnumValue(params.ctx, n.desc, t, tree.AsStringWithFQNames(n.n, params.p.Ann()))
+	case *tree.AlterTypeAddValues:
+		newValues := make([]string, len(t.Values))
+		for i, v := range t.Values {
+			newValues[i] = string(v.NewVal)
+		}
+		event.NewValue = strings.Join(newValues, ", ")
+		err = params.p.addEnumValues(
+			params.ctx, n.desc, t.Values, t.UsageGrantees, tree.AsStringWithFQNames(n.n, params.p.Ann()),
+		)
 	case *tree.AlterTypeRenameValue:
-		err = params.p.renameTypeValue(params.ctx, n, string(t.OldVal), string(t.NewVal))
+		event.OldValue, event.NewValue = string(t.OldVal), string(t.NewVal)
+		err = params.p.renameTypeValue(
+			params.ctx, n, string(t.OldVal), string(t.NewVal), t.IfExists, t.RefreshViews, t.ExpectedRows,
+		)
 	case *tree.AlterTypeRename:
 		if err = params.p.renameType(params.ctx, n, string(t.NewName)); err != nil {
 			return err
@@ -114,9 +252,11 @@ func (n *alterTypeNode) startExec(params runParams) error {
 		})
 		eventLogDone = true
 	case *tree.AlterTypeSetSchema:
-		// TODO(knz): this is missing dedicated logging,
-		// See https://github.com/cockroachdb/cockroach/issues/57741
-		err = params.p.setTypeSchema(params.ctx, n, string(t.Schema))
+		// setTypeSchema logs a set_schema event with the old and new names.
+		// The alter_type event is still logged below, as it always has been.
+		err = params.p.setTypeSchema(params.ctx, n, string(t.Schema), t.Cascade)
+	case *tree.AlterTypeSetOID:
+		err = params.p.setTypeOID(params.ctx, n, t.OID, tree.AsStringWithFQNames(n.n, params.p.Ann()))
 	case *tree.AlterTypeOwner:
 		owner, err := decodeusername.FromRoleSpec(
 			params.SessionData(), username.PurposeValidation, t.Owner,
@@ -129,7 +269,32 @@ func (n *alterTypeNode) startExec(params runParams) error {
 		}
 		eventLogDone = true // done inside alterTypeOwner().
 	case *tree.AlterTypeDropValue:
-		err = params.p.dropEnumValue(params.ctx, n.desc, t.Val)
+		event.OldValue = string(t.Val)
+		if t.Replacement != nil {
+			event.NewValue = string(*t.Replacement)
+		}
+		err = params.p.dropEnumValue(params.ctx, n.desc, t.Val, t.Replacement)
+	case *tree.AlterTypeDedupValues:
+		err = params.p.dedupEnumValues(params.ctx, n.desc)
+	case *tree.AlterTypeCompact:
+		err = params.p.compactEnumValues(params.ctx, n.desc)
+	case *tree.AlterTypeNormalizeRepresentation:
+		err = params.p.normalizeEnumRepresentation(params.ctx, n.desc)
+	case *tree.AlterTypePromoteValue:
+		event.NewValue = string(t.Val)
+		err = params.p.promoteEnumValue(params.ctx, n.desc, t.Val, tree.AsStringWithFQNames(n.n, params.p.Ann()))
+	case *tree.AlterTypeSetValueCode:
+		event.NewValue = string(t.Val)
+		err = params.p.setEnumValueCode(params.ctx, n.desc, t.Val, t.Code, tree.AsStringWithFQNames(n.n, params.p.Ann()))
+	case *tree.AlterTypeRestrictValues:
+		err = params.p.restrictEnumValues(params.ctx, n.desc, t, tree.AsStringWithFQNames(n.n, params.p.Ann()))
+	case *tree.AlterTypeAddConstraint:
+		err = params.p.addTypeConstraint(params.ctx, n.desc, t, tree.AsStringWithFQNames(n.n, params.p.Ann()))
+	case *tree.AlterTypeDropConstraint:
+		err = params.p.dropTypeConstraint(params.ctx, n.desc, t, tree.AsStringWithFQNames(n.n, params.p.Ann()))
+	case *tree.AlterTypeAddAttribute:
+		event.NewValue = string(t.Name)
+		err = params.p.addTypeAttribute(params.ctx, n.desc, t, tree.AsStringWithFQNames(n.n, params.p.Ann()))
 	default:
 		err = errors.AssertionFailedf("unknown alter type cmd %s", t)
 	}
@@ -139,11 +304,7 @@ func (n *alterTypeNode) startExec(params runParams) error {
 
 	if !eventLogDone {
 		// Write a log event.
-		if err := params.p.logEvent(params.ctx,
-			n.desc.ID,
-			&eventpb.AlterType{
-				TypeName: typeName,
-			}); err != nil {
+		if err := params.p.logEvent(params.ctx, n.desc.ID, event); err != nil {
 			return err
 		}
 	}
@@ -161,63 +322,1088 @@ func findEnumMemberByName(
 	return false, nil
 }
 
+// addEnumValue adds a value to the enum and queues a type schema change job to
+// make it writable.
+//
+// If the value is added between two values whose physical representations
+// are adjacent, the job re-spaces the representations of the values, which
+// rewrites the rows that use them, see queueEnumRespacing. The number of rows
+// to rewrite and how long that takes are then estimated from the statistics of
+// the tables that use the type, and reported in a notice and in the details of
+// the job.
+//
+// It doesn't retry conflicts with concurrent schema changes itself, since the
+// conflicting writes to the type descriptor can only be resolved by restarting
+// the transaction. They are surfaced as retryable errors, and implicit
+// transactions are then retried by the connExecutor, which resolves the
+// descriptor again when planning the statement. Explicit transactions are not
+// retried transparently, since their earlier statements may have observed the
+// previous version of the type.
+//
+// If the add_enum_value_max_pending_jobs session variable is set, the
+// statement fails fast with a retryable error rather than queuing a job while
+// too many schema change jobs are pending.
 func (p *planner) addEnumValue(
 	ctx context.Context, desc *typedesc.Mutable, node *tree.AlterTypeAddValue, jobDesc string,
+) error {
+	return p.addEnumValues(ctx, desc, []*tree.AlterTypeAddValue{node}, node.UsageGrantees, jobDesc)
+}
+
+// addEnumValues adds values to the enum like addEnumValue, for ALTER TYPE ...
+// ADD VALUE ..., ADD VALUE .... All the values are added to the descriptor
+// before it is written, so that a single job makes them writable together.
+// Values that already exist, including ones added earlier in the same
+// statement, are duplicates.
+func (p *planner) addEnumValues(
+	ctx context.Context,
+	desc *typedesc.Mutable,
+	nodes []*tree.AlterTypeAddValue,
+	usageGrantees tree.RoleSpecList,
+	jobDesc string,
 ) error {
 	if desc.Kind != descpb.TypeDescriptor_ENUM &&
 		desc.Kind != descpb.TypeDescriptor_MULTIREGION_ENUM {
 		return pgerror.Newf(pgcode.WrongObjectType, "%q is not an enum", desc.Name)
 	}
-	// See if the value already exists in the enum or not.
-	found, member := findEnumMemberByName(desc, node.NewVal)
-	if found {
-		if enumMemberIsRemoving(member) {
-			return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
-				"enum value %q is being dropped, try again later", node.NewVal)
+	grantees, err := decodeusername.FromRoleSpecList(
+		p.SessionData(), username.PurposeValidation, usageGrantees,
+	)
+	if err != nil {
+		return err
+	}
+	if err := p.preChangePrivilegesValidation(
+		ctx, grantees, false /* withGrantOption */, true, /* isGrant */
+	); err != nil {
+		return err
+	}
+
+	if fn := p.ExecCfg().TypeSchemaChangerTestingKnobs.RunBeforeAddEnumValue; fn != nil {
+		if err := fn(ctx, desc.ID); err != nil {
+			return err
 		}
-		if node.IfNotExists {
-			p.BufferClientNotice(
-				ctx,
-				pgnotice.Newf("enum value %q already exists, skipping", node.NewVal),
+	}
+
+	// See if the values already exist in the enum or not. The membership is
+	// checked against the current version of the descriptor in this
+	// transaction rather than the copy that the caller resolved. If a value
+	// is added concurrently, e.g. by the same migration running on another
+	// node during an upgrade, writing the descriptor below fails with a
+	// retryable error, and the retry then finds the value, which makes
+	// IF NOT EXISTS a no-op.
+	desc, err = p.Descriptors().MutableByID(p.txn).Type(ctx, desc.ID)
+	if err != nil {
+		return err
+	}
+	var added bool
+	var respaced tree.EnumValue
+	for _, node := range nodes {
+		ok, err := p.addEnumValueToDesc(ctx, desc, node)
+		if err != nil {
+			return err
+		}
+		added = added || ok
+		if ok && respaced == "" && addedEnumValueIsPacked(desc, node.NewVal) {
+			respaced = node.NewVal
+		}
+	}
+
+	// The grants still apply if the values already exist, so that the
+	// statement can be safely retried.
+	if !added && len(grantees) == 0 {
+		return nil
+	}
+	if err := p.grantTypeUsage(ctx, desc, grantees); err != nil {
+		return err
+	}
+	if err := p.writeTypeSchemaChange(ctx, desc, jobDesc); err != nil {
+		return err
+	}
+	if respaced == "" || !respacePackedEnumValues.Get(&p.ExecCfg().Settings.SV) {
+		return nil
+	}
+	return p.estimateEnumRespacing(ctx, desc, respaced)
+}
+
+// addedEnumValueIsPacked returns whether the value was added to a regular enum
+// without room left between the physical representations of its neighbours,
+// which makes the type schema change job re-space the values of the enum.
+func addedEnumValueIsPacked(desc *typedesc.Mutable, val tree.EnumValue) bool {
+	if desc.Kind != descpb.TypeDescriptor_ENUM {
+		return false
+	}
+	for i := range desc.EnumMembers {
+		if desc.EnumMembers[i].LogicalRepresentation == string(val) {
+			return typedesc.IsEnumMemberPacked(desc.EnumMembers, i)
+		}
+	}
+	return false
+}
+
+// enumRewriteRowsPerSecond is the rate at which the rows that use the values
+// of an enum are assumed to be rewritten when estimating how long re-spacing
+// the values takes. The rows are rewritten in batches of
+// enumValueRewriteBatchSize rows, one transaction at a time.
+const enumRewriteRowsPerSecond = 5000
+
+// estimateEnumRespacing estimates how many rows are rewritten when the values
+// of the enum are re-spaced after val was added, and how long that takes. The
+// row counts come from the most recent statistics of the tables that use the
+// type or its array type, and tables without statistics aren't counted. The
+// estimate is reported in a notice and in the details of the job queued for
+// the type.
+func (p *planner) estimateEnumRespacing(
+	ctx context.Context, desc *typedesc.Mutable, val tree.EnumValue,
+) error {
+	arrayTypeDesc, err := p.Descriptors().ByIDWithLeased(p.txn).WithoutNonPublic().Get().Type(
+		ctx, desc.ArrayTypeID,
+	)
+	if err != nil {
+		return err
+	}
+	ids := catalog.MakeDescriptorIDSet(desc.ReferencingDescriptorIDs...)
+	for i := 0; i < arrayTypeDesc.NumReferencingDescriptors(); i++ {
+		ids.Add(arrayTypeDesc.GetReferencingDescriptorID(i))
+	}
+	var rows int64
+	for _, id := range ids.Ordered() {
+		tableDesc, err := p.Descriptors().ByIDWithLeased(p.txn).WithoutNonPublic().Get().Table(ctx, id)
+		if err != nil {
+			return err
+		}
+		if tableDesc.IsView() {
+			continue
+		}
+		tableStats, err := p.ExecCfg().TableStatsCache.GetTableStats(ctx, tableDesc)
+		if err != nil {
+			return err
+		}
+		// The statistics are ordered from newest to oldest.
+		for _, stat := range tableStats {
+			if !stat.IsPartial() && !stat.IsForecast() {
+				rows += int64(stat.RowCount)
+				break
+			}
+		}
+	}
+	duration := (time.Duration(rows) * time.Second / enumRewriteRowsPerSecond).Round(time.Second)
+
+	record, ok := p.extendedEvalCtx.jobs.uniqueToCreate[desc.ID]
+	if !ok {
+		return errors.AssertionFailedf("no type schema change job queued for type %d", desc.ID)
+	}
+	details := record.Details.(jobspb.TypeSchemaChangeDetails)
+	details.EstimatedRewriteRows = rows
+	details.EstimatedRewriteDuration = duration
+	record.Details = details
+
+	p.BufferClientNotice(ctx, pgnotice.Newf(
+		"adding value %q re-spaces the values of type %q, which rewrites an estimated %d rows "+
+			"and is estimated to take %s", val, desc.Name, rows, duration,
+	))
+	return nil
+}
+
+// addEnumValueToDesc adds a value to the descriptor of the enum for
+// addEnumValues. It returns false if the value already exists and IF NOT
+// EXISTS was specified.
+func (p *planner) addEnumValueToDesc(
+	ctx context.Context, desc *typedesc.Mutable, node *tree.AlterTypeAddValue,
+) (bool, error) {
+	if found, member := findEnumMemberByName(desc, node.NewVal); found && enumMemberIsRemoving(member) {
+		return false, pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"enum value %q is being dropped, try again later", node.NewVal)
+	}
+
+	// Values that are being dropped still count towards the limit, since the
+	// drop may yet fail and leave them in place.
+	numValues := int64(len(desc.EnumMembers))
+	if err := desc.AddEnumValue(node, maxEnumLabelLength.Get(&p.ExecCfg().Settings.SV)); err != nil {
+		if !node.IfNotExists || !errors.Is(err, typedesc.ErrEnumValueExists) {
+			return false, err
+		}
+		sqltelemetry.IncrementEnumCounter(sqltelemetry.EnumAddValueSkipped)
+		p.BufferClientNotice(
+			ctx,
+			pgnotice.Newf("enum value %q already exists, skipping", node.NewVal),
+		)
+		return false, nil
+	}
+
+	// The checks below run after the value is added to the descriptor, so that
+	// IF NOT EXISTS is a no-op for an existing value regardless of them. The
+	// modified descriptor is discarded along with the transaction if any of
+	// them fail.
+	if limit := maxEnumValues.Get(&p.ExecCfg().Settings.SV); limit > 0 && numValues >= limit {
+		return false, errors.WithHintf(
+			pgerror.Newf(pgcode.ProgramLimitExceeded,
+				"cannot add value %q to enum %q: enum already has %d values, the maximum allowed is %d",
+				node.NewVal, desc.Name, numValues, limit),
+			"the maximum is configured by the %s cluster setting", maxEnumValues.Name())
+	}
+
+	if err := p.checkSchemaChangeJobQueue(ctx, desc); err != nil {
+		return false, err
+	}
+
+	// Rows with the new value won't belong to any partition of a table that is
+	// partitioned by list on the enum, so they would silently be placed outside
+	// of the zone configurations of its partitions. Region enums are skipped,
+	// since adding a region repartitions REGIONAL BY ROW tables.
+	if desc.Kind == descpb.TypeDescriptor_ENUM {
+		if err := p.checkEnumListPartitionsHaveDefault(ctx, desc, node.NewVal); err != nil {
+			return false, err
+		}
+	}
+
+	// The new value stays READ_ONLY until the schema change job promotes it,
+	// but the transaction that added it can use it right away, like in
+	// Postgres. The uncommitted descriptor that makes it usable is discarded
+	// if the transaction rolls back. Staged values are only promoted
+	// explicitly, so they stay unusable.
+	if !node.Staged {
+		_, member := findEnumMemberByName(desc, node.NewVal)
+		desc.MarkEnumMemberUsableInTxn(member.PhysicalRepresentation)
+	}
+	return true, nil
+}
+
+// pendingSchemaChangeJobsQuery counts the schema change jobs that have not
+// reached a terminal status, including paused ones.
+var pendingSchemaChangeJobsQuery = fmt.Sprintf(
+	`SELECT count(*) FROM system.jobs WHERE job_type IN ('%s', '%s', '%s') AND status IN %s`,
+	jobspb.TypeSchemaChange, jobspb.TypeTypeSchemaChange, jobspb.TypeNewSchemaChange,
+	jobs.NonTerminalStatusTupleString,
+)
+
+// checkSchemaChangeJobQueue returns a retryable error if the
+// add_enum_value_max_pending_jobs session variable is set and the number of
+// pending schema change jobs has reached it, so that clients adding values at
+// a high rate back off instead of piling more jobs onto the queue. Nothing is
+// checked if the transaction has already queued a job for the type, since the
+// statement then only extends that job.
+func (p *planner) checkSchemaChangeJobQueue(ctx context.Context, desc *typedesc.Mutable) error {
+	limit := p.SessionData().AddEnumValueMaxPendingJobs
+	if limit <= 0 {
+		return nil
+	}
+	if _, ok := p.extendedEvalCtx.jobs.uniqueToCreate[desc.ID]; ok {
+		return nil
+	}
+	row, err := p.InternalSQLTxn().QueryRowEx(
+		ctx, "count-pending-schema-change-jobs", p.txn, sessiondata.NodeUserSessionDataOverride,
+		pendingSchemaChangeJobsQuery,
+	)
+	if err != nil {
+		return err
+	}
+	if n := int64(tree.MustBeDInt(row[0])); n >= limit {
+		return errors.WithHint(
+			pgerror.Newf(pgcode.SerializationFailure,
+				"schema change job queue is saturated: %d jobs are pending, the maximum is %d", n, limit),
+			"back off and retry the transaction, or raise add_enum_value_max_pending_jobs",
+		)
+	}
+	return nil
+}
+
+// checkEnumListPartitionsHaveDefault returns an error if a column of the enum
+// is used in the list partitioning of an index of a referencing table, and the
+// partitioning has no DEFAULT partition for it that would hold rows with the
+// new value. Range partitionings are not checked, since new values sort
+// between existing ones and so usually fall within an existing range.
+func (p *planner) checkEnumListPartitionsHaveDefault(
+	ctx context.Context, desc *typedesc.Mutable, newVal string,
+) error {
+	for _, id := range desc.ReferencingDescriptorIDs {
+		tbl, err := p.Descriptors().ByIDWithLeased(p.txn).WithoutNonPublic().Get().Table(ctx, id)
+		if err != nil {
+			return err
+		}
+		if tbl.IsView() {
+			continue
+		}
+		for _, idx := range tbl.NonDropIndexes() {
+			keyColumns := make([]catalog.Column, 0, idx.NumKeyColumns())
+			for i := 0; i < idx.NumKeyColumns(); i++ {
+				col, err := catalog.MustFindColumnByID(tbl, idx.GetKeyColumnID(i))
+				if err != nil {
+					return errors.WithAssertionFailure(err)
+				}
+				keyColumns = append(keyColumns, col)
+			}
+			lacksDefault, err := listPartitioningLacksEnumDefault(
+				idx.GetPartitioning(), p.ExecCfg().Codec, keyColumns, tbl, idx, nil /* fakePrefixDatums */, desc.ID,
 			)
+			if err != nil {
+				return err
+			}
+			if lacksDefault {
+				return errors.WithHint(
+					pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+						"cannot add value %q to enum %q as it is used in the list partitioning of index %s, "+
+							"which has no DEFAULT partition to hold the new value",
+						newVal, desc.Name, &tree.TableIndexName{
+							Table: tree.MakeUnqualifiedTableName(tree.Name(tbl.GetName())),
+							Index: tree.UnrestrictedName(idx.GetName()),
+						}),
+					"add a partition with VALUES IN (DEFAULT) to the index first; once the value "+
+						"is added, the index can be repartitioned to give it its own partition")
+			}
+		}
+	}
+	return nil
+}
+
+// listPartitioningLacksEnumDefault reports whether the list partitioning, or
+// one of its subpartitionings, is on a column of the given enum type and has
+// no partition that is DEFAULT for that column. The fakePrefixDatums are used
+// as in findUsagesOfEnumValueInPartitioning.
+func listPartitioningLacksEnumDefault(
+	partitioning catalog.Partitioning,
+	codec keys.SQLCodec,
+	columns []catalog.Column,
+	table catalog.TableDescriptor,
+	index catalog.Index,
+	fakePrefixDatums []tree.Datum,
+	typeID descpb.ID,
+) (lacksDefault bool, _ error) {
+	if partitioning == nil || partitioning.NumLists() == 0 {
+		return false, nil
+	}
+
+	var colsToCheck intsets.Fast
+	for i, c := range columns[:partitioning.NumColumns()] {
+		if typ := c.GetType(); typ.UserDefined() && typedesc.GetUserDefinedTypeDescID(typ) == typeID {
+			colsToCheck.Add(i)
+		}
+	}
+	subPrefixDatums := append([]tree.Datum(nil), fakePrefixDatums...)
+	for i := 0; i < partitioning.NumColumns(); i++ {
+		subPrefixDatums = append(subPrefixDatums, tree.DNull)
+	}
+
+	// A column is DEFAULT in a partition's tuple if the tuple has fewer datums
+	// than the column's position, since DEFAULT can only be followed by DEFAULT.
+	var defaultCols intsets.Fast
+	var a tree.DatumAlloc
+	if err := partitioning.ForEachList(func(
+		name string, values [][]byte, subPartitioning catalog.Partitioning,
+	) (err error) {
+		for _, v := range values {
+			tuple, _, err := rowenc.DecodePartitionTuple(
+				&a, codec, table, index, partitioning, v, fakePrefixDatums,
+			)
+			if err != nil {
+				return err
+			}
+			colsToCheck.ForEach(func(i int) {
+				if i >= len(tuple.Datums) {
+					defaultCols.Add(i)
+				}
+			})
+		}
+		lacksDefault, err = listPartitioningLacksEnumDefault(
+			subPartitioning, codec, columns[partitioning.NumColumns():],
+			table, index, subPrefixDatums, typeID)
+		if err == nil && lacksDefault {
+			err = iterutil.StopIteration()
+		}
+		return err
+	}); err != nil || lacksDefault {
+		return lacksDefault, err
+	}
+	return !colsToCheck.SubsetOf(defaultCols), nil
+}
+
+// grantTypeUsage grants the USAGE privilege on the type to the given roles,
+// for ALTER TYPE ... ADD VALUE ... GRANT USAGE TO. The caller is responsible
+// for writing the descriptor.
+func (p *planner) grantTypeUsage(
+	ctx context.Context, desc *typedesc.Mutable, grantees []username.SQLUsername,
+) error {
+	privs := privilege.List{privilege.USAGE}
+	for _, grantee := range grantees {
+		desc.Privileges.Grant(grantee, privs, false /* withGrantOption */)
+		if err := p.logEvent(ctx, desc.ID, &eventpb.ChangeTypePrivilege{
+			CommonSQLPrivilegeEventDetails: eventpb.CommonSQLPrivilegeEventDetails{
+				Grantee:           grantee.Normalized(),
+				GrantedPrivileges: privs.SortedDisplayNames(),
+			},
+			TypeName: desc.Name,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// promoteEnumValue makes a value that was added WITH (staged) writable. The
+// value was already made readable by the job that added it, so the job
+// queued here only has to wait for the new version of the type to be leased.
+func (p *planner) promoteEnumValue(
+	ctx context.Context, desc *typedesc.Mutable, val tree.EnumValue, jobDesc string,
+) error {
+	if desc.Kind != descpb.TypeDescriptor_ENUM {
+		return pgerror.Newf(pgcode.WrongObjectType, "%q is not an enum", desc.Name)
+	}
+	found, member := findEnumMemberByName(desc, val)
+	if !found {
+		return pgerror.Newf(pgcode.UndefinedObject, "enum value %q does not exist", val)
+	}
+	if !member.Staged {
+		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"enum value %q is not staged", val)
+	}
+	// The value must be known to every node before it can be written, so it
+	// can't be promoted in the transaction that added it.
+	committed := false
+	if desc.ClusterVersion != nil {
+		for i := range desc.ClusterVersion.EnumMembers {
+			if desc.ClusterVersion.EnumMembers[i].LogicalRepresentation == string(val) {
+				committed = true
+				break
+			}
+		}
+	}
+	if !committed {
+		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"enum value %q was added in the current transaction and cannot be promoted until it commits", val)
+	}
+	desc.PromoteEnumValue(val)
+	return p.writeTypeSchemaChange(ctx, desc, jobDesc)
+}
+
+// setEnumValueCode sets the code of an enum value, which is used to map the
+// value to external systems. Codes must be unique within the enum.
+func (p *planner) setEnumValueCode(
+	ctx context.Context, desc *typedesc.Mutable, val tree.EnumValue, code int64, jobDesc string,
+) error {
+	if desc.Kind != descpb.TypeDescriptor_ENUM {
+		return pgerror.Newf(pgcode.WrongObjectType, "%q is not an enum", desc.Name)
+	}
+	found, member := findEnumMemberByName(desc, val)
+	if !found {
+		return pgerror.Newf(pgcode.UndefinedObject, "enum value %q does not exist", val)
+	}
+	if enumMemberIsRemoving(member) {
+		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"enum value %q is being dropped", val)
+	}
+	if member.Code != nil && *member.Code == code {
+		return nil
+	}
+	for i := range desc.EnumMembers {
+		other := &desc.EnumMembers[i]
+		if other.Code != nil && *other.Code == code {
+			return pgerror.Newf(pgcode.DuplicateObject,
+				"code %d is already used by enum value %q", code, other.LogicalRepresentation)
+		}
+	}
+	desc.SetEnumValueCode(val, code)
+	return p.writeTypeSchemaChange(ctx, desc, jobDesc)
+}
+
+// restrictEnumValues limits the values of the enum that a role, and the roles
+// that are members of it, can write. The restriction is enforced when rows are
+// inserted, updated or upserted, see enumWriteRestrictions. Reads are not
+// restricted, and neither are writes by roles that the restriction doesn't
+// apply to. Restricted roles can still write NULL.
+func (p *planner) restrictEnumValues(
+	ctx context.Context, desc *typedesc.Mutable, node *tree.AlterTypeRestrictValues, jobDesc string,
+) error {
+	// Restrictions limit what other roles can write, so they are managed by
+	// admins rather than by the owner of the type.
+	hasAdmin, err := p.HasAdminRole(ctx)
+	if err != nil {
+		return err
+	}
+	if !hasAdmin {
+		return pgerror.New(pgcode.InsufficientPrivilege,
+			"only users with the admin role are allowed to ALTER TYPE ... RESTRICT VALUES")
+	}
+	if desc.Kind != descpb.TypeDescriptor_ENUM {
+		return pgerror.Newf(pgcode.WrongObjectType, "%q is not an enum", desc.Name)
+	}
+	role, err := decodeusername.FromRoleSpec(
+		p.SessionData(), username.PurposeValidation, node.Role,
+	)
+	if err != nil {
+		return err
+	}
+	if err := p.CheckRoleExists(ctx, role); err != nil {
+		return err
+	}
+	var values [][]byte
+	if node.Values != nil {
+		values = make([][]byte, 0, len(node.Values))
+		seen := make(map[tree.EnumValue]struct{}, len(node.Values))
+		for _, val := range node.Values {
+			found, member := findEnumMemberByName(desc, val)
+			if !found {
+				return pgerror.Newf(pgcode.UndefinedObject, "enum value %q does not exist", val)
+			}
+			if enumMemberIsRemoving(member) {
+				return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+					"enum value %q is being dropped", val)
+			}
+			if _, ok := seen[val]; ok {
+				continue
+			}
+			seen[val] = struct{}{}
+			values = append(values, member.PhysicalRepresentation)
+		}
+	}
+	desc.SetRoleValueRestriction(role, values)
+	return p.writeTypeSchemaChange(ctx, desc, jobDesc)
+}
+
+// addTypeConstraint adds a CHECK constraint to the type, which the values of
+// the type must satisfy when they are written. This is a first step towards
+// domains: only enums can have constraints, and they are enforced when rows
+// are inserted, updated or upserted, see typeConstraintChecker. Like CHECK
+// constraints of tables, the constraint is added in the Validating state and
+// enforced on writes right away. The type schema change job validates the
+// values that are already stored once all nodes have leased the new version of
+// the type, and drops the constraint if that fails.
+func (p *planner) addTypeConstraint(
+	ctx context.Context, desc *typedesc.Mutable, node *tree.AlterTypeAddConstraint, jobDesc string,
+) error {
+	if desc.Kind != descpb.TypeDescriptor_ENUM {
+		return pgerror.Newf(pgcode.WrongObjectType, "%q is not an enum", desc.Name)
+	}
+	for i := range desc.CheckConstraints {
+		if desc.CheckConstraints[i].Name == string(node.Name) {
+			return pgerror.Newf(pgcode.DuplicateObject,
+				"constraint %q for type %q already exists", node.Name, desc.Name)
+		}
+	}
+	// Subqueries can't be type checked outside of the optimizer, and the
+	// constraints are evaluated outside of it.
+	if _, err := tree.SimpleVisit(node.Expr, func(e tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
+		if _, ok := e.(*tree.Subquery); ok {
+			return false, nil, pgerror.New(pgcode.FeatureNotSupported,
+				"subqueries are not allowed in type constraints")
+		}
+		return true, e, nil
+	}); err != nil {
+		return err
+	}
+	typ, err := p.ResolveTypeByOID(ctx, catid.TypeIDToOID(desc.ID))
+	if err != nil {
+		return err
+	}
+	replaced, _, err := schemaexpr.ReplaceColumnVars(
+		node.Expr,
+		func(columnName tree.Name) (exists bool, accessible bool, id catid.ColumnID, _ *types.T) {
+			if columnName != typeConstraintValueName {
+				return false, false, 0, nil
+			}
+			return true, true, 1, typ
+		},
+	)
+	if err != nil {
+		return errors.WithHint(err, "refer to the value of the type as VALUE")
+	}
+	typedExpr, err := schemaexpr.SanitizeVarFreeExpr(
+		ctx, replaced, types.Bool, tree.CheckConstraintExpr, p.SemaCtx(), volatility.Immutable,
+		false, /* allowAssignmentCast */
+	)
+	if err != nil {
+		return err
+	}
+	// The functions that the constraints use aren't tracked as dependencies of
+	// the type.
+	udfVisitor := &tree.UDFDisallowanceVisitor{}
+	tree.WalkExpr(udfVisitor, typedExpr)
+	if udfVisitor.FoundUDF {
+		return pgerror.New(pgcode.FeatureNotSupported,
+			"user-defined functions are not allowed in type constraints")
+	}
+	desc.AddCheckConstraint(string(node.Name), tree.Serialize(typedExpr))
+	return p.writeTypeSchemaChange(ctx, desc, jobDesc)
+}
+
+// dropTypeConstraint drops a CHECK constraint of the type.
+func (p *planner) dropTypeConstraint(
+	ctx context.Context, desc *typedesc.Mutable, node *tree.AlterTypeDropConstraint, jobDesc string,
+) error {
+	if !desc.DropCheckConstraint(string(node.Name)) {
+		if node.IfExists {
+			p.BufferClientNotice(ctx, pgnotice.Newf(
+				"constraint %q of type %q does not exist, skipping", node.Name, desc.Name))
 			return nil
 		}
-		return pgerror.Newf(pgcode.DuplicateObject, "enum value %q already exists", node.NewVal)
+		return pgerror.Newf(pgcode.UndefinedObject,
+			"constraint %q of type %q does not exist", node.Name, desc.Name)
+	}
+	return p.writeTypeSchemaChange(ctx, desc, jobDesc)
+}
+
+// addTypeAttribute appends an attribute to the composite type. Values of the
+// type that are already stored have NULL for it, see decodeTuple. The implicit
+// array type embeds the composite type, so it's rewritten as well.
+func (p *planner) addTypeAttribute(
+	ctx context.Context, desc *typedesc.Mutable, node *tree.AlterTypeAddAttribute, jobDesc string,
+) error {
+	if desc.Kind != descpb.TypeDescriptor_COMPOSITE {
+		return pgerror.Newf(pgcode.WrongObjectType, "%q is not a composite type", desc.Name)
+	}
+	for i := range desc.Composite.Elements {
+		if desc.Composite.Elements[i].ElementLabel == string(node.Name) {
+			return pgerror.Newf(pgcode.DuplicateColumn,
+				"attribute %q of type %q already exists", node.Name, desc.Name)
+		}
+	}
+	typ, err := p.resolveCompositeElementType(ctx, node.Type)
+	if err != nil {
+		return err
+	}
+	if node.Collation != "" {
+		if !types.IsStringType(typ) {
+			return pgerror.New(pgcode.Syntax, "COLLATE can only be used with string types")
+		}
+		typ = types.MakeCollatedString(typ, node.Collation)
+	}
+	desc.Composite.Elements = append(desc.Composite.Elements, descpb.TypeDescriptor_Composite_CompositeElement{
+		ElementType:  typ,
+		ElementLabel: string(node.Name),
+	})
+	if err := p.writeTypeSchemaChange(ctx, desc, jobDesc); err != nil {
+		return err
+	}
+
+	arrayDesc, err := p.Descriptors().MutableByID(p.txn).Type(ctx, desc.ArrayTypeID)
+	if err != nil {
+		return err
+	}
+	arrayDesc.Alias = types.MakeArray(desc.AsTypesT())
+	return p.writeTypeSchemaChange(ctx, arrayDesc, jobDesc)
+}
+
+// checkEnumValueUnused returns an error if the enum value is in use by the
+// tables and views that refer to the type, or by the rows of their columns, so
+// that dropping a value that is in use fails right away rather than once its
+// job runs. The error lists all the tables and views that use the value. The
+// job validates the drop again once the value can no longer be written, and
+// the usages of tables with schema changes in progress are left to it, since
+// they may change before it runs.
+func (p *planner) checkEnumValueUnused(
+	ctx context.Context, desc *typedesc.Mutable, member *descpb.TypeDescriptor_EnumMember,
+) error {
+	usages, usedBy, err := p.enumValueTableUsages(ctx, desc, member)
+	if err != nil {
+		return err
+	}
+	if len(usages) == 0 {
+		return p.checkEnumValueUnusedByArrays(ctx, desc, member)
+	}
+	return errors.WithDetailf(usages[0],
+		"enum value %q is used by %s", member.LogicalRepresentation, strings.Join(usedBy, ", "))
+}
+
+// enumValueTableUsages returns the errors that dropping the enum value would
+// fail with because of the tables and views that refer to the type, along with
+// their quoted names. Like in checkEnumValueUnused, tables with schema changes
+// in progress are skipped.
+func (p *planner) enumValueTableUsages(
+	ctx context.Context, desc *typedesc.Mutable, member *descpb.TypeDescriptor_EnumMember,
+) (usages []error, usedBy []string, _ error) {
+	sc := &typeSchemaChanger{typeID: desc.ID, execCfg: p.ExecCfg()}
+	txn := p.InternalSQLTxn()
+	for _, id := range desc.ReferencingDescriptorIDs {
+		tableDesc, err := p.Descriptors().ByIDWithLeased(p.txn).WithoutNonPublic().Get().Table(ctx, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if tableDesc.GetDeclarativeSchemaChangerState() != nil || len(tableDesc.AllMutations()) > 0 {
+			continue
+		}
+		if err := sc.canRemoveEnumValueFromTable(
+			ctx, desc, tableDesc, txn, member, p.Descriptors(), true /* checkRowUsages */, false, /* isMerge */
+		); err != nil {
+			if pgerror.GetPGCode(err) != pgcode.DependentObjectsStillExist {
+				return nil, nil, err
+			}
+			usages = append(usages, err)
+			usedBy = append(usedBy, strconv.Quote(tableDesc.GetName()))
+		}
+	}
+	return usages, usedBy, nil
+}
+
+// checkEnumValueUnusedByArrays returns an error if the enum value is used by
+// the rows of columns of the array type of the enum.
+func (p *planner) checkEnumValueUnusedByArrays(
+	ctx context.Context, desc *typedesc.Mutable, member *descpb.TypeDescriptor_EnumMember,
+) error {
+	sc := &typeSchemaChanger{typeID: desc.ID, execCfg: p.ExecCfg()}
+	arrayTypeDesc, err := p.Descriptors().ByIDWithLeased(p.txn).WithoutNonPublic().Get().Type(ctx, desc.ArrayTypeID)
+	if err != nil {
+		return err
+	}
+	return sc.canRemoveEnumValueFromArrayUsages(ctx, arrayTypeDesc, member, p.InternalSQLTxn(), p.Descriptors())
+}
+
+// checkEnumValueNotInTypeConstraints returns an error if a constraint of the
+// type refers to the enum value, since the constraint would no longer type
+// check once the value is dropped.
+func checkEnumValueNotInTypeConstraints(
+	desc *typedesc.Mutable, member *descpb.TypeDescriptor_EnumMember,
+) error {
+	for i := range desc.CheckConstraints {
+		ck := &desc.CheckConstraints[i]
+		referenced, err := findUsagesOfEnumValue(ck.Expr, member, desc.ID)
+		if err != nil {
+			return err
+		}
+		if referenced {
+			return errors.WithHint(pgerror.Newf(pgcode.DependentObjectsStillExist,
+				"cannot drop enum value %q: check constraint %q of type %q refers to it",
+				member.LogicalRepresentation, ck.Name, desc.Name),
+				"use ALTER TYPE ... DROP CONSTRAINT to drop the constraint first")
+		}
+	}
+	return nil
+}
+
+// explainDropEnumValue returns the rows of EXPLAIN ALTER TYPE ... DROP VALUE,
+// one for each of the usages of the enum value that would make the drop fail.
+// Only the checks that run when the statement executes are made, so the type
+// is left unchanged and no job is created.
+func (p *planner) explainDropEnumValue(
+	ctx context.Context, desc *typedesc.Mutable, val tree.EnumValue,
+) ([]tree.Datums, error) {
+	found, member := findEnumMemberByName(desc, val)
+	if !found {
+		return nil, pgerror.Newf(pgcode.UndefinedObject, "enum value %q does not exist", val)
+	}
+	var usages []error
+	if err := checkEnumValueNotInTypeConstraints(desc, member); err != nil {
+		if pgerror.GetPGCode(err) != pgcode.DependentObjectsStillExist {
+			return nil, err
+		}
+		usages = append(usages, err)
+	}
+	tableUsages, _, err := p.enumValueTableUsages(ctx, desc, member)
+	if err != nil {
+		return nil, err
+	}
+	usages = append(usages, tableUsages...)
+	if err := p.checkEnumValueUnusedByArrays(ctx, desc, member); err != nil {
+		if pgerror.GetPGCode(err) != pgcode.DependentObjectsStillExist {
+			return nil, err
+		}
+		usages = append(usages, err)
+	}
+	if len(usages) == 0 {
+		return []tree.Datums{
+			{tree.NewDString(fmt.Sprintf("enum value %q is not used and can be dropped", val))},
+		}, nil
+	}
+	rows := make([]tree.Datums, len(usages))
+	for i, usage := range usages {
+		rows[i] = tree.Datums{tree.NewDString(usage.Error())}
+	}
+	return rows, nil
+}
+
+// dropEnumValue marks the given enum value for removal by a type schema change
+// job. If replacement is non-nil, the job rewrites all rows using the value to
+// the replacement before removing it.
+func (p *planner) dropEnumValue(
+	ctx context.Context, desc *typedesc.Mutable, val tree.EnumValue, replacement *tree.EnumValue,
+) error {
+	if desc.Kind != descpb.TypeDescriptor_ENUM &&
+		desc.Kind != descpb.TypeDescriptor_MULTIREGION_ENUM {
+		return pgerror.Newf(pgcode.WrongObjectType, "%q is not an enum", desc.Name)
+	}
+
+	found, member := findEnumMemberByName(desc, val)
+	if !found {
+		return pgerror.Newf(pgcode.UndefinedObject, "enum value %q does not exist", val)
+	}
+	// Do not allow drops if the enum value isn't public yet.
+	if enumMemberIsRemoving(member) {
+		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"enum value %q is already being dropped", val)
+	}
+	if member.Staged {
+		return errors.WithHint(pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"enum value %q is staged", val),
+			"use ALTER TYPE ... PROMOTE VALUE to make the value usable before dropping it")
+	}
+	if enumMemberIsAdding(member) {
+		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"enum value %q is being added, try again later", val)
+	}
+	// Do not allow drops of a value that an in-progress drop is replacing
+	// rows with.
+	for i := range desc.EnumMembers {
+		if bytes.Equal(desc.EnumMembers[i].ReplacementPhysicalRepresentation, member.PhysicalRepresentation) {
+			return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+				"enum value %q is the replacement for dropped value %q, try again later",
+				val, desc.EnumMembers[i].LogicalRepresentation)
+		}
+	}
+	if err := checkEnumValueNotInTypeConstraints(desc, member); err != nil {
+		return err
+	}
+
+	if replacement == nil {
+		if desc.Kind == descpb.TypeDescriptor_ENUM {
+			if err := p.checkEnumValueUnused(ctx, desc, member); err != nil {
+				return err
+			}
+		}
+		if err := desc.RemoveEnumValue(val); err != nil {
+			return err
+		}
+		return p.writeTypeSchemaChange(ctx, desc, desc.Name)
+	}
+
+	if desc.Kind == descpb.TypeDescriptor_MULTIREGION_ENUM {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"REPLACE WITH is not supported for multi-region enums")
+	}
+	if *replacement == val {
+		return pgerror.Newf(pgcode.InvalidParameterValue,
+			"enum value %q can not be replaced with itself", val)
+	}
+	found, replacementMember := findEnumMemberByName(desc, *replacement)
+	if !found {
+		return pgerror.Newf(pgcode.UndefinedObject, "enum value %q does not exist", *replacement)
+	}
+	if enumMemberIsRemoving(replacementMember) {
+		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"enum value %q is being dropped", *replacement)
+	}
+	if enumMemberIsAdding(replacementMember) {
+		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"enum value %q is being added, try again later", *replacement)
+	}
+
+	desc.DropEnumValueWithReplacement(val, *replacement)
+	return p.writeTypeSchemaChange(ctx, desc, desc.Name)
+}
+
+// dedupEnumValues merges enum values that share a label, which can only
+// happen if the type descriptor was corrupted. The first value with each label
+// in physical order is kept, and a type schema change job rewrites all rows
+// using the others to it before removing them.
+func (p *planner) dedupEnumValues(ctx context.Context, desc *typedesc.Mutable) error {
+	hasAdmin, err := p.HasAdminRole(ctx)
+	if err != nil {
+		return err
+	}
+	if !hasAdmin {
+		return pgerror.New(pgcode.InsufficientPrivilege,
+			"only users with the admin role are allowed to ALTER TYPE ... DEDUP VALUES")
+	}
+	if desc.Kind != descpb.TypeDescriptor_ENUM {
+		return pgerror.Newf(pgcode.WrongObjectType, "%q is not an enum", desc.Name)
+	}
+	// Values that are being added or dropped may themselves be duplicates, so
+	// wait for those schema changes to finish first. Duplicates left over from
+	// a failed merge are merged again.
+	for i := range desc.EnumMembers {
+		member := &desc.EnumMembers[i]
+		if typedesc.IsEnumMemberMerge(desc.EnumMembers, member) {
+			continue
+		}
+		if enumMemberIsRemoving(member) {
+			return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+				"enum value %q is being dropped, try again later", member.LogicalRepresentation)
+		}
+		if enumMemberIsAdding(member) {
+			return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+				"enum value %q is being added, try again later", member.LogicalRepresentation)
+		}
+	}
+
+	merged := desc.MergeDuplicateEnumValues()
+	if len(merged) == 0 {
+		p.BufferClientNotice(ctx, pgnotice.Newf("type %q has no duplicate enum values", desc.Name))
+		return nil
+	}
+	for _, label := range merged {
+		p.BufferClientNotice(ctx, pgnotice.Newf("merging duplicates of enum value %q", label))
+	}
+	return p.writeTypeSchemaChange(ctx, desc, desc.Name)
+}
+
+// compactEnumValues shortens the physical representations of enum values,
+// which grow as values are repeatedly added next to each other. Each value
+// that can be shortened is merged into a copy of itself with a shorter
+// representation, and a type schema change job rewrites all rows using it
+// before removing the original. The order of the values is preserved.
+//
+// Like with ALTER TYPE ... DEDUP VALUES, rows that are yet to be rewritten
+// aren't matched by equality filters on their value until the job completes.
+func (p *planner) compactEnumValues(ctx context.Context, desc *typedesc.Mutable) error {
+	hasAdmin, err := p.HasAdminRole(ctx)
+	if err != nil {
+		return err
 	}
-
-	if err := desc.AddEnumValue(node); err != nil {
+	if !hasAdmin {
+		return pgerror.New(pgcode.InsufficientPrivilege,
+			"only users with the admin role are allowed to ALTER TYPE ... COMPACT")
+	}
+	if desc.Kind != descpb.TypeDescriptor_ENUM {
+		return pgerror.Newf(pgcode.WrongObjectType, "%q is not an enum", desc.Name)
+	}
+	// The new representations must sort between those of the neighbouring
+	// values, so wait for values that are being added or dropped first.
+	if err := checkEnumMembersSettled(desc, "compacting"); err != nil {
 		return err
 	}
-	return p.writeTypeSchemaChange(ctx, desc, jobDesc)
+
+	compacted := desc.CompactEnumValues()
+	if len(compacted) == 0 {
+		p.BufferClientNotice(ctx, pgnotice.Newf("type %q is already compact", desc.Name))
+		return nil
+	}
+	for _, label := range compacted {
+		p.BufferClientNotice(ctx, pgnotice.Newf("compacting enum value %q", label))
+	}
+	return p.writeTypeSchemaChange(ctx, desc, desc.Name)
 }
 
-func (p *planner) dropEnumValue(
-	ctx context.Context, desc *typedesc.Mutable, val tree.EnumValue,
-) error {
-	if desc.Kind != descpb.TypeDescriptor_ENUM &&
-		desc.Kind != descpb.TypeDescriptor_MULTIREGION_ENUM {
+// normalizeEnumRepresentation rewrites the physical representations of enum
+// values to the integers 0, 1, 2, ... in logical order, for tools that expect
+// enums to be encoded that way. Values are moved in the same way that
+// ALTER TYPE ... COMPACT moves them, so the order of the values is preserved
+// throughout, but this may take several rounds: each statement moves as many
+// values as it can, and tells the user to run it again once its schema change
+// job completes if more values remain to be moved.
+func (p *planner) normalizeEnumRepresentation(ctx context.Context, desc *typedesc.Mutable) error {
+	hasAdmin, err := p.HasAdminRole(ctx)
+	if err != nil {
+		return err
+	}
+	if !hasAdmin {
+		return pgerror.New(pgcode.InsufficientPrivilege,
+			"only users with the admin role are allowed to ALTER TYPE ... NORMALIZE REPRESENTATION")
+	}
+	if desc.Kind != descpb.TypeDescriptor_ENUM {
 		return pgerror.Newf(pgcode.WrongObjectType, "%q is not an enum", desc.Name)
 	}
+	if len(desc.EnumMembers) > enum.MaxIntegerRepresentations {
+		return pgerror.Newf(pgcode.ProgramLimitExceeded,
+			"cannot normalize the representation of enum %q with more than %d values",
+			desc.Name, enum.MaxIntegerRepresentations)
+	}
+	if err := checkEnumMembersSettled(desc, "normalizing"); err != nil {
+		return err
+	}
 
-	found, member := findEnumMemberByName(desc, val)
-	if !found {
-		return pgerror.Newf(pgcode.UndefinedObject, "enum value %q does not exist", val)
+	normalized, remaining := desc.NormalizeEnumValues()
+	if len(normalized) == 0 {
+		p.BufferClientNotice(ctx, pgnotice.Newf("type %q is already normalized", desc.Name))
+		return nil
 	}
-	// Do not allow drops if the enum value isn't public yet.
-	if enumMemberIsRemoving(member) {
-		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
-			"enum value %q is already being dropped", val)
+	for _, label := range normalized {
+		p.BufferClientNotice(ctx, pgnotice.Newf("normalizing enum value %q", label))
 	}
-	if enumMemberIsAdding(member) {
+	if remaining > 0 {
+		p.BufferClientNotice(ctx, pgnotice.Newf(
+			"not all enum values could be normalized in this round (%d remaining); run "+
+				"ALTER TYPE ... NORMALIZE REPRESENTATION again once this schema change completes", remaining))
+	}
+	return p.writeTypeSchemaChange(ctx, desc, desc.Name)
+}
+
+// checkNoConcurrentTypeSchemaChange returns an error if a schema change that
+// was started by another transaction is in progress on the type, so that
+// concurrent ALTER TYPE statements fail up front rather than once their job
+// finds the type in an unexpected state. Values can still be added while other
+// values are being added, since the jobs that add them don't interfere.
+// Staged values and leftovers from failed merges aren't changed by any job, so
+// they don't count.
+func checkNoConcurrentTypeSchemaChange(desc *typedesc.Mutable, cmd tree.AlterTypeCmd) error {
+	if catalog.HasConcurrentDeclarativeSchemaChange(desc) {
 		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
-			"enum value %q is being added, try again later", val)
+			"type %q is being modified by a concurrent schema change, try again later", desc.Name)
+	}
+	// Types created by the transaction can't be changed by other ones.
+	if desc.ClusterVersion == nil {
+		return nil
+	}
+	var isAdd bool
+	switch cmd.(type) {
+	case *tree.AlterTypeAddValue, *tree.AlterTypeAddValues:
+		isAdd = true
+	}
+	members := desc.ClusterVersion.EnumMembers
+	for i := range members {
+		member := &members[i]
+		if typedesc.IsEnumMemberMerge(members, member) {
+			continue
+		}
+		var transition string
+		switch {
+		case enumMemberIsAdding(member) && !member.Staged:
+			if isAdd {
+				continue
+			}
+			transition = "added"
+		case enumMemberIsRemoving(member):
+			transition = "dropped"
+		default:
+			continue
+		}
+		return errors.WithDetailf(
+			pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+				"type %q has a schema change in progress, try again later", desc.Name),
+			"enum value %q is being %s", member.LogicalRepresentation, transition,
+		)
+	}
+	if isAdd {
+		return nil
+	}
+	for _, ck := range desc.ClusterVersion.CheckConstraints {
+		if ck.Validity == descpb.ConstraintValidity_Validating {
+			return errors.WithDetailf(
+				pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+					"type %q has a schema change in progress, try again later", desc.Name),
+				"constraint %q is being validated", ck.Name,
+			)
+		}
 	}
+	return nil
+}
 
-	desc.DropEnumValue(val)
-	return p.writeTypeSchemaChange(ctx, desc, desc.Name)
+// checkEnumMembersSettled returns an error if any member of the enum is being
+// added, dropped or merged, or is staged. op describes the operation that
+// requires it, for the hint.
+func checkEnumMembersSettled(desc *typedesc.Mutable, op string) error {
+	for i := range desc.EnumMembers {
+		member := &desc.EnumMembers[i]
+		if typedesc.IsEnumMemberMerge(desc.EnumMembers, member) {
+			return errors.WithHint(pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+				"enum value %q is being merged", member.LogicalRepresentation),
+				"use ALTER TYPE ... DEDUP VALUES to finish merging it")
+		}
+		if enumMemberIsRemoving(member) {
+			return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+				"enum value %q is being dropped, try again later", member.LogicalRepresentation)
+		}
+		if member.Staged {
+			return errors.WithHintf(pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+				"enum value %q is staged", member.LogicalRepresentation),
+				"use ALTER TYPE ... PROMOTE VALUE to make the value usable before %s the type", op)
+		}
+		if enumMemberIsAdding(member) {
+			return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+				"enum value %q is being added, try again later", member.LogicalRepresentation)
+		}
+	}
+	return nil
 }
 
 func (p *planner) renameType(ctx context.Context, n *alterTypeNode, newName string) error {
+	if err := p.checkTypeRenameTarget(ctx, n, newName); err != nil {
+		return err
+	}
 	err := descs.CheckObjectNameCollision(
 		ctx,
 		p.Descriptors(),
@@ -269,6 +1455,54 @@ func (p *planner) renameType(ctx context.Context, n *alterTypeNode, newName stri
 	return nil
 }
 
+// checkTypeRenameTarget returns an error if a type can't be renamed to the
+// given name because the name is reserved for a built-in type, or because it
+// is the name of the implicit array type of an existing type. Array types are
+// named by findFreeArrayTypeName, so taking over one of their names is
+// reported separately from a generic name collision.
+func (p *planner) checkTypeRenameTarget(
+	ctx context.Context, n *alterTypeNode, newName string,
+) error {
+	// Like CREATE TYPE, don't allow shadowing the built-in types that are
+	// extension types on the public schema in PostgreSQL.
+	if n.prefix.Schema != nil && n.prefix.Schema.GetName() == catconstants.PublicSchemaName {
+		if _, ok := types.PublicSchemaAliases[newName]; ok {
+			return pgerror.Newf(pgcode.ReservedName,
+				"type name %q is reserved for a built-in type", newName)
+		}
+	}
+	if !strings.HasPrefix(newName, "_") {
+		return nil
+	}
+	id, err := p.Descriptors().LookupObjectID(
+		ctx, p.txn, n.desc.ParentID, n.desc.ParentSchemaID, newName,
+	)
+	if err != nil || id == descpb.InvalidID {
+		return err
+	}
+	desc, err := p.Descriptors().ByID(p.txn).Get().Desc(ctx, id)
+	if err != nil {
+		return err
+	}
+	arrayDesc, ok := desc.(catalog.TypeDescriptor)
+	if !ok || arrayDesc.GetKind() != descpb.TypeDescriptor_ALIAS {
+		// Other collisions are reported by CheckObjectNameCollision.
+		return nil
+	}
+	elemDesc, err := p.Descriptors().ByID(p.txn).Get().Type(
+		ctx, typedesc.GetUserDefinedTypeDescID(arrayDesc.TypeDesc().Alias.ArrayContents()),
+	)
+	if err != nil {
+		return err
+	}
+	return errors.WithHint(
+		pgerror.Newf(pgcode.DuplicateObject,
+			"type %q already exists as the implicit array type of type %q",
+			newName, elemDesc.GetName()),
+		"implicit array types are named after their element type with a leading underscore",
+	)
+}
+
 // performRenameTypeDesc renames and/or sets the schema of a type descriptor.
 // newName and newSchemaID may be the same as the current name and schemaid.
 func (p *planner) performRenameTypeDesc(
@@ -303,9 +1537,25 @@ func (p *planner) performRenameTypeDesc(
 	return p.txn.Run(ctx, b)
 }
 
+// renameTypeValue renames an enum value. If ifExists is set, a missing oldVal
+// is reported with a notice instead of an error, but newVal must still not
+// exist. If expectedRows is non-nil, the rename fails unless exactly that many
+// rows use the value, which guards against renaming a value in the wrong
+// database. The rows are counted in the statement's transaction, which scans
+// every table that uses the type.
 func (p *planner) renameTypeValue(
-	ctx context.Context, n *alterTypeNode, oldVal string, newVal string,
+	ctx context.Context,
+	n *alterTypeNode,
+	oldVal string,
+	newVal string,
+	ifExists bool,
+	refreshViews bool,
+	expectedRows *int64,
 ) error {
+	if err := typedesc.ValidateEnumLabel(newVal, maxEnumLabelLength.Get(&p.ExecCfg().Settings.SV)); err != nil {
+		return err
+	}
+
 	enumMemberIndex := -1
 
 	// Do one pass to verify that the oldVal exists and there isn't already
@@ -322,6 +1572,13 @@ func (p *planner) renameTypeValue(
 
 	// An enum member with the name oldVal was not found.
 	if enumMemberIndex == -1 {
+		if ifExists {
+			p.BufferClientNotice(
+				ctx,
+				pgnotice.Newf("enum value %q does not exist, skipping", oldVal),
+			)
+			return nil
+		}
 		return pgerror.Newf(pgcode.InvalidParameterValue,
 			"%s is not an existing enum value", oldVal)
 	}
@@ -330,22 +1587,243 @@ func (p *planner) renameTypeValue(
 		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
 			"enum value %q is being dropped", oldVal)
 	}
+	if n.desc.EnumMembers[enumMemberIndex].Staged {
+		return errors.WithHint(pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"enum value %q is staged", oldVal),
+			"use ALTER TYPE ... PROMOTE VALUE to make the value usable before renaming it")
+	}
 	if enumMemberIsAdding(&n.desc.EnumMembers[enumMemberIndex]) {
 		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
 			"enum value %q is being added, try again later", oldVal)
 
 	}
 
+	if expectedRows != nil {
+		rows, err := p.countEnumValueRows(ctx, n.desc, &n.desc.EnumMembers[enumMemberIndex])
+		if err != nil {
+			return err
+		}
+		if rows != *expectedRows {
+			return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+				"cannot rename enum value %q: %d rows use the value, expected %d",
+				oldVal, rows, *expectedRows)
+		}
+	}
+
+	// Expressions that compare columns of the type to the old value as a string
+	// would silently stop matching it, so refuse the rename until they are
+	// rewritten.
+	exprs, err := p.findExprsWithEnumLabel(ctx, n.desc, oldVal)
+	if err != nil {
+		return err
+	}
+	if len(exprs) > 0 {
+		return errors.WithHint(
+			errors.WithDetail(
+				pgerror.Newf(pgcode.DependentObjectsStillExist,
+					"cannot rename enum value %q: stored expressions refer to it as a string", oldVal),
+				strings.Join(exprs, "\n"),
+			),
+			"values of the type in expressions are renamed along with the value, but strings "+
+				"aren't; rewrite the expressions to compare to values of the type instead",
+		)
+	}
+
+	// Materialized views store the results of their queries, which may include
+	// the string form of the old value, so the rename could leave them stale.
+	// Unless asked to refresh them once the rename is complete, refuse the
+	// rename.
+	views, err := p.findDependentMaterializedViews(ctx, n.desc)
+	if err != nil {
+		return err
+	}
+	if len(views) > 0 && !refreshViews {
+		return errors.WithHint(
+			pgerror.Newf(pgcode.DependentObjectsStillExist,
+				"cannot rename enum value %q: materialized view %q depends on type %q",
+				oldVal, views[0].GetName(), n.desc.Name),
+			"use ALTER TYPE ... RENAME VALUE ... REFRESH to refresh the dependent "+
+				"materialized views once the value is renamed")
+	}
+
+	// Stored expressions, such as column defaults that combine the value with a
+	// sequence and the constraints of the type, view queries and the
+	// restrictions on the values that roles can write refer to enum values by
+	// their physical representation, so they don't need to be rewritten. They pick up the new name once the new version of the type is
+	// leased.
 	n.desc.EnumMembers[enumMemberIndex].LogicalRepresentation = newVal
 
-	return p.writeTypeSchemaChange(
+	if err := p.writeTypeSchemaChange(
 		ctx,
 		n.desc,
 		tree.AsStringWithFQNames(n.n, p.Ann()),
-	)
+	); err != nil {
+		return err
+	}
+	if len(views) == 0 {
+		return nil
+	}
+	viewIDs := make([]descpb.ID, len(views))
+	for i, view := range views {
+		viewIDs[i] = view.GetID()
+	}
+	return p.refreshViewsAfterTypeSchemaChange(n.desc.ID, viewIDs)
+}
+
+// findDependentMaterializedViews returns the materialized views that depend on
+// the type or its array type, either directly or through the tables and views
+// they select from.
+func (p *planner) findDependentMaterializedViews(
+	ctx context.Context, desc *typedesc.Mutable,
+) ([]catalog.TableDescriptor, error) {
+	g := p.Descriptors().ByIDWithLeased(p.txn).WithoutNonPublic().Get()
+	arrayTypeDesc, err := g.Type(ctx, desc.ArrayTypeID)
+	if err != nil {
+		return nil, err
+	}
+	toVisit := append([]descpb.ID(nil), desc.ReferencingDescriptorIDs...)
+	for i := 0; i < arrayTypeDesc.NumReferencingDescriptors(); i++ {
+		toVisit = append(toVisit, arrayTypeDesc.GetReferencingDescriptorID(i))
+	}
+
+	var visited catalog.DescriptorIDSet
+	var views []catalog.TableDescriptor
+	for len(toVisit) > 0 {
+		id := toVisit[0]
+		toVisit = toVisit[1:]
+		if visited.Contains(id) {
+			continue
+		}
+		visited.Add(id)
+		// Types and tables can also be referenced by functions, which don't
+		// store any rows.
+		referencing, err := g.Desc(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		tbl, ok := referencing.(catalog.TableDescriptor)
+		if !ok {
+			continue
+		}
+		if tbl.MaterializedView() {
+			views = append(views, tbl)
+		}
+		for _, ref := range tbl.GetDependedOnBy() {
+			toVisit = append(toVisit, ref.ID)
+		}
+	}
+	return views, nil
+}
+
+// findExprsWithEnumLabel returns descriptions of the stored expressions of the
+// tables that use the type or its array type which refer to a column of either
+// type and contain the given label as a string. Values of the type are stored
+// by their physical representation, so they are renamed along with the value,
+// but strings like the one in `status::STRING = 'label'` aren't.
+func (p *planner) findExprsWithEnumLabel(
+	ctx context.Context, desc *typedesc.Mutable, label string,
+) ([]string, error) {
+	g := p.Descriptors().ByIDWithLeased(p.txn).WithoutNonPublic().Get()
+	arrayTypeDesc, err := g.Type(ctx, desc.ArrayTypeID)
+	if err != nil {
+		return nil, err
+	}
+	// A table may use both the type and its array type.
+	ids := catalog.MakeDescriptorIDSet(desc.ReferencingDescriptorIDs...)
+	for i := 0; i < arrayTypeDesc.NumReferencingDescriptors(); i++ {
+		ids.Add(arrayTypeDesc.GetReferencingDescriptorID(i))
+	}
+	var found []string
+	for _, id := range ids.Ordered() {
+		tableDesc, err := g.Table(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if tableDesc.IsView() {
+			continue
+		}
+		var typeCols catalog.TableColSet
+		for _, col := range tableDesc.PublicColumns() {
+			if !col.GetType().UserDefined() {
+				continue
+			}
+			switch typedesc.GetUserDefinedTypeDescID(col.GetType()) {
+			case desc.ID, arrayTypeDesc.GetID():
+				typeCols.Add(col.GetID())
+			}
+		}
+		if typeCols.Empty() {
+			continue
+		}
+		check := func(what string, exprStr string) error {
+			expr, err := parser.ParseExpr(exprStr)
+			if err != nil {
+				return err
+			}
+			cols, err := schemaexpr.ExtractColumnIDs(tableDesc, expr)
+			if err != nil {
+				return err
+			}
+			if !cols.Intersects(typeCols) {
+				return nil
+			}
+			hasLabel := false
+			if _, err := tree.SimpleVisit(expr, func(e tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
+				if s, ok := e.(*tree.StrVal); ok && s.RawString() == label {
+					hasLabel = true
+				}
+				return !hasLabel, e, nil
+			}); err != nil || !hasLabel {
+				return err
+			}
+			formatted, err := schemaexpr.FormatExprForDisplay(
+				ctx, tableDesc, exprStr, p.SemaCtx(), p.SessionData(), tree.FmtParsable,
+			)
+			if err != nil {
+				return err
+			}
+			found = append(found, fmt.Sprintf("%s of table %q: %s", what, tableDesc.GetName(), formatted))
+			return nil
+		}
+		for _, ck := range tableDesc.CheckConstraints() {
+			if err := check(fmt.Sprintf("check constraint %q", ck.GetName()), ck.GetExpr()); err != nil {
+				return nil, err
+			}
+		}
+		for _, col := range tableDesc.PublicColumns() {
+			if col.IsComputed() {
+				if err := check(fmt.Sprintf("computed column %q", col.GetName()), col.GetComputeExpr()); err != nil {
+					return nil, err
+				}
+			}
+			if col.HasDefault() {
+				if err := check(fmt.Sprintf("default of column %q", col.GetName()), col.GetDefaultExpr()); err != nil {
+					return nil, err
+				}
+			}
+			if col.HasOnUpdate() {
+				if err := check(fmt.Sprintf("ON UPDATE expression of column %q", col.GetName()), col.GetOnUpdateExpr()); err != nil {
+					return nil, err
+				}
+			}
+		}
+		for _, idx := range tableDesc.PartialIndexes() {
+			if err := check(fmt.Sprintf("predicate of index %q", idx.GetName()), idx.GetPredicate()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return found, nil
 }
 
-func (p *planner) setTypeSchema(ctx context.Context, n *alterTypeNode, schema string) error {
+// setTypeSchema moves the type and its array type to the given schema, and
+// refreshes the names that the tables that use them refer to them by. It fails
+// if one of those tables is being dropped, rather than leaving it with a
+// reference to the moved type. With cascade, the tables are reported with
+// notices once they refer to the type by its new name.
+func (p *planner) setTypeSchema(
+	ctx context.Context, n *alterTypeNode, schema string, cascade bool,
+) error {
 	typeDesc := n.desc
 	schemaID := typeDesc.GetParentSchemaID()
 
@@ -365,6 +1843,15 @@ func (p *planner) setTypeSchema(ctx context.Context, n *alterTypeNode, schema st
 		return nil
 	}
 
+	dependents, err := p.getTypeSetSchemaDependents(ctx, typeDesc)
+	if err != nil {
+		return err
+	}
+
+	// Renaming the type and its array type bumps both of their versions. Cached
+	// plans and hydrated descriptors check the versions of the types they
+	// reference, so this also invalidates any that still refer to the type by
+	// its old qualified name.
 	err = p.performRenameTypeDesc(
 		ctx, typeDesc, typeDesc.Name, desiredSchemaID, tree.AsStringWithFQNames(n.n, p.Ann()),
 	)
@@ -384,10 +1871,20 @@ func (p *planner) setTypeSchema(ctx context.Context, n *alterTypeNode, schema st
 		return err
 	}
 
+	if err := p.refreshReferencingTableTypeNames(ctx, typeDesc, arrayDesc); err != nil {
+		return err
+	}
+
 	newName, err := p.getQualifiedTypeName(ctx, typeDesc)
 	if err != nil {
 		return err
 	}
+	if cascade {
+		for _, dependent := range dependents {
+			p.BufferClientNotice(ctx, pgnotice.Newf(
+				"relation %s now refers to type %s", dependent.FQString(), newName.FQString()))
+		}
+	}
 
 	return p.logEvent(ctx,
 		desiredSchemaID,
@@ -399,6 +1896,237 @@ func (p *planner) setTypeSchema(ctx context.Context, n *alterTypeNode, schema st
 	)
 }
 
+// getTypeSetSchemaDependents returns the names of the tables that use the type
+// or its array type, or an error if one of them, or its schema, is being
+// dropped.
+func (p *planner) getTypeSetSchemaDependents(
+	ctx context.Context, desc *typedesc.Mutable,
+) ([]tree.TableName, error) {
+	arrayDesc, err := p.Descriptors().ByID(p.txn).Get().Type(ctx, desc.ArrayTypeID)
+	if err != nil {
+		return nil, err
+	}
+	ids := catalog.MakeDescriptorIDSet(desc.ReferencingDescriptorIDs...)
+	for i := 0; i < arrayDesc.NumReferencingDescriptors(); i++ {
+		ids.Add(arrayDesc.GetReferencingDescriptorID(i))
+	}
+	var dependents []tree.TableName
+	for _, id := range ids.Ordered() {
+		// Dropped tables and schemas are looked up too, so that the error
+		// names them.
+		tableDesc, err := p.Descriptors().ByID(p.txn).Get().Table(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		schemaDesc, err := p.Descriptors().ByID(p.txn).Get().Schema(ctx, tableDesc.GetParentSchemaID())
+		if err != nil {
+			return nil, err
+		}
+		if tableDesc.Dropped() {
+			return nil, pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+				"cannot set schema of type %q: relation %q that uses it is being dropped",
+				desc.Name, tableDesc.GetName())
+		}
+		if schemaDesc.Dropped() {
+			return nil, pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+				"cannot set schema of type %q: relation %q that uses it is in schema %q, which is being dropped",
+				desc.Name, tableDesc.GetName(), schemaDesc.GetName())
+		}
+		tn, err := p.getQualifiedTableName(ctx, tableDesc)
+		if err != nil {
+			return nil, err
+		}
+		dependents = append(dependents, *tn)
+	}
+	return dependents, nil
+}
+
+// refreshReferencingTableTypeNames rehydrates the columns of the tables that
+// use the type or its array type, so that they refer to the type by its new
+// qualified name. Hydration skips types that are hydrated with the current
+// version of their descriptor, and the mutable descriptors of tables that were
+// resolved earlier in the transaction may have been hydrated after the type
+// was already modified by it, so their types would otherwise keep the old
+// name.
+func (p *planner) refreshReferencingTableTypeNames(
+	ctx context.Context, desc *typedesc.Mutable, arrayDesc *typedesc.Mutable,
+) error {
+	ids := catalog.MakeDescriptorIDSet(desc.ReferencingDescriptorIDs...)
+	for _, id := range arrayDesc.ReferencingDescriptorIDs {
+		ids.Add(id)
+	}
+	for _, id := range ids.Ordered() {
+		tableDesc, err := p.Descriptors().MutableByID(p.txn).Table(ctx, id)
+		if err != nil {
+			return err
+		}
+		for i := range tableDesc.Columns {
+			resetTypeMetadata(tableDesc.Columns[i].Type, desc.ID, arrayDesc.ID)
+		}
+		for _, m := range tableDesc.Mutations {
+			if col := m.GetColumn(); col != nil {
+				resetTypeMetadata(col.Type, desc.ID, arrayDesc.ID)
+			}
+		}
+		if err := typedesc.HydrateTypesInDescriptor(ctx, tableDesc, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resetTypeMetadata clears the metadata of typ if it is the type with the
+// given ID or the array type with the given array type ID, so that it is
+// hydrated again.
+func resetTypeMetadata(typ *types.T, typeID, arrayTypeID descpb.ID) {
+	if !typ.UserDefined() {
+		return
+	}
+	switch typedesc.GetUserDefinedTypeDescID(typ) {
+	case typeID:
+		typ.TypeMeta = types.UserDefinedTypeMetadata{}
+	case arrayTypeID:
+		typ.TypeMeta = types.UserDefinedTypeMetadata{}
+		typ.ArrayContents().TypeMeta = types.UserDefinedTypeMetadata{}
+	}
+}
+
+// maxSetTypeOIDIDGap is how far past the next descriptor ID the ID for the OID
+// given to ALTER TYPE ... SET OID can be.
+const maxSetTypeOIDIDGap = 1000
+
+// setTypeOID assigns the given OID to an enum, for clients that map types to
+// client-side types by OID. The OIDs of user-defined types are derived from
+// their descriptor IDs, so this moves the type to a copy of its descriptor
+// with the ID for the OID, and drops the original descriptor like DROP TYPE
+// would. The implicit array type keeps its ID, and is updated to refer to the
+// copy. Objects that depend on a type store its OID, so only types that
+// nothing depends on can be assigned a new one.
+//
+// The ID must never have been allocated, since zone and span configs, GC
+// jobs, the data of dropped tables and backups may still refer to an ID after
+// its descriptor is gone. The descriptor ID counter is advanced past it, so
+// that it is never allocated to another descriptor, which is why the ID can be
+// at most maxSetTypeOIDIDGap past the counter: otherwise, a large OID could
+// use up the ID space. Types can't have comments or zone configs, so nothing
+// else is keyed by the original descriptor's ID.
+func (p *planner) setTypeOID(
+	ctx context.Context, n *alterTypeNode, newOID int64, jobDesc string,
+) error {
+	hasAdmin, err := p.HasAdminRole(ctx)
+	if err != nil {
+		return err
+	}
+	if !hasAdmin {
+		return pgerror.New(pgcode.InsufficientPrivilege,
+			"only users with the admin role are allowed to ALTER TYPE ... SET OID")
+	}
+	desc := n.desc
+	if desc.Kind != descpb.TypeDescriptor_ENUM {
+		return pgerror.Newf(pgcode.WrongObjectType, "%q is not an enum", desc.Name)
+	}
+	if newOID <= int64(oidext.CockroachPredefinedOIDMax) || newOID > math.MaxUint32 ||
+		descpb.IsVirtualTable(catid.UserDefinedOIDToID(oid.Oid(newOID))) {
+		return pgerror.Newf(pgcode.InvalidParameterValue,
+			"OID %d is not in the range of user-defined type OIDs", newOID)
+	}
+	newID := catid.UserDefinedOIDToID(oid.Oid(newOID))
+	if newID == desc.ID {
+		return nil
+	}
+
+	arrayDesc, err := p.Descriptors().MutableByID(p.txn).Type(ctx, desc.ArrayTypeID)
+	if err != nil {
+		return err
+	}
+	refs := append(
+		append([]descpb.ID(nil), desc.GetReferencingDescriptorIDs()...),
+		arrayDesc.GetReferencingDescriptorIDs()...,
+	)
+	if len(refs) > 0 {
+		dependentNames, err := p.getFullyQualifiedNamesFromIDs(ctx, refs)
+		if err != nil {
+			return errors.Wrapf(err, "type %q has dependent objects", desc.Name)
+		}
+		return pgerror.Newf(pgcode.DependentObjectsStillExist,
+			"cannot change the OID of type %q because other objects (%v) depend on it",
+			desc.Name, dependentNames)
+	}
+	if err := checkEnumMembersSettled(desc, "changing the OID of"); err != nil {
+		return err
+	}
+
+	// Check that the ID has never been allocated, and reserve it. The counter
+	// isn't transactional, so it stays advanced if the transaction aborts,
+	// which only skips some IDs. Descriptors are written with a conditional
+	// put, so a concurrent transaction writing a descriptor with the same ID
+	// fails one of the two.
+	if existing, err := p.Descriptors().ByID(p.txn).Get().Desc(ctx, newID); err == nil {
+		return pgerror.Newf(pgcode.DuplicateObject,
+			"OID %d is already in use by %s %q", newOID, existing.DescriptorType(), existing.GetName())
+	} else if !errors.Is(err, catalog.ErrDescriptorNotFound) &&
+		!errors.Is(err, catalog.ErrDescriptorDropped) {
+		return err
+	}
+	nextID, err := p.ExecCfg().DescIDGenerator.PeekNextUniqueDescID(ctx)
+	if err != nil {
+		return err
+	}
+	if newID < nextID {
+		return errors.WithHint(
+			pgerror.Newf(pgcode.InvalidParameterValue,
+				"OID %d has already been allocated and can't be reused", newOID),
+			"choose an OID whose descriptor ID hasn't been allocated yet",
+		)
+	}
+	if newID-nextID > maxSetTypeOIDIDGap {
+		return errors.WithHintf(
+			pgerror.Newf(pgcode.InvalidParameterValue,
+				"OID %d is too far past the next descriptor ID %d", newOID, nextID),
+			"choose an OID whose descriptor ID is at most %d past the next descriptor ID",
+			maxSetTypeOIDIDGap,
+		)
+	}
+	if _, err := p.ExecCfg().DescIDGenerator.IncrementDescID(ctx, int64(newID-nextID+1)); err != nil {
+		return err
+	}
+
+	// Drop the original descriptor, which also removes its namespace entry so
+	// that the copy can take it over.
+	desc.SetDropped()
+	b := p.txn.NewBatch()
+	if err := p.dropNamespaceEntry(ctx, b, desc); err != nil {
+		return err
+	}
+	if err := p.txn.Run(ctx, b); err != nil {
+		return err
+	}
+	if err := p.writeTypeSchemaChange(ctx, desc, jobDesc); err != nil {
+		return err
+	}
+
+	typDesc := protoutil.Clone(desc.TypeDesc()).(*descpb.TypeDescriptor)
+	typDesc.ID = newID
+	typDesc.Version = 1
+	typDesc.ModificationTime = hlc.Timestamp{}
+	typDesc.State = descpb.DescriptorState_PUBLIC
+	newDesc := typedesc.NewBuilder(typDesc).BuildCreatedMutableType()
+	if err := p.createDescriptor(ctx, newDesc, jobDesc); err != nil {
+		return err
+	}
+
+	arrayDesc.Alias = types.MakeArray(types.MakeEnum(
+		catid.TypeIDToOID(newID), catid.TypeIDToOID(arrayDesc.ID),
+	))
+	if err := p.writeTypeSchemaChange(ctx, arrayDesc, jobDesc); err != nil {
+		return err
+	}
+
+	// Log the event under the type's new ID.
+	n.desc = newDesc
+	return nil
+}
+
 func (p *planner) alterTypeOwner(
 	ctx context.Context, n *alterTypeNode, newOwner username.SQLUsername,
 ) error {
@@ -477,10 +2205,23 @@ func (p *planner) setNewTypeOwner(
 		})
 }
 
-func (n *alterTypeNode) Next(params runParams) (bool, error) { return false, nil }
-func (n *alterTypeNode) Values() tree.Datums                 { return tree.Datums{} }
-func (n *alterTypeNode) Close(ctx context.Context)           {}
-func (n *alterTypeNode) ReadingOwnWrites()                   {}
+func (n *alterTypeNode) Next(params runParams) (bool, error) {
+	if n.rowIdx >= len(n.rows) {
+		return false, nil
+	}
+	n.rowIdx++
+	return true, nil
+}
+
+func (n *alterTypeNode) Values() tree.Datums {
+	if n.rowIdx == 0 {
+		return tree.Datums{}
+	}
+	return n.rows[n.rowIdx-1]
+}
+
+func (n *alterTypeNode) Close(ctx context.Context) {}
+func (n *alterTypeNode) ReadingOwnWrites()         {}
 
 func (p *planner) canModifyType(ctx context.Context, desc *typedesc.Mutable) error {
 	hasAdmin, err := p.HasAdminRole(ctx)
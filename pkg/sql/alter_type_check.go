@@ -0,0 +1,542 @@
+// Copyright 2024 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/fetchpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/catid"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
+
+// staleReadOnlyEnumValueThreshold is the amount of time an enum value can be
+// in the process of being added or dropped before ALTER TYPE ... CHECK reports
+// it. Type schema changes normally promote or remove values quickly, so values
+// that stay read-only for longer usually indicate a failed or stuck job.
+var staleReadOnlyEnumValueThreshold = settings.RegisterDurationSetting(
+	settings.ApplicationLevel,
+	"sql.alter_type.check.stale_read_only_value_threshold",
+	"the amount of time after which ALTER TYPE ... CHECK reports enum values "+
+		"that are still being added or dropped",
+	time.Hour,
+	settings.NonNegativeDuration,
+)
+
+// longEnumPhysicalRepLength is the length at which ALTER TYPE ... CHECK reports
+// an enum value's physical representation as long. Physical representations
+// grow as values are repeatedly added next to each other, and longer
+// representations make every row using the value larger.
+const longEnumPhysicalRepLength = 8
+
+// The issues reported by ALTER TYPE ... CHECK.
+const (
+	enumCheckLongPhysicalRep  = "long_physical_representation"
+	enumCheckStaleReadOnlyVal = "stale_read_only_value"
+	enumCheckOrphanedName     = "orphaned_name"
+	enumCheckUnusedValue      = "unused_value"
+)
+
+// checkEnum runs ALTER TYPE ... CHECK, which looks for common problems with an
+// enum and returns a row for each of them. It does not modify the type.
+//
+// Finding unused values scans every column of the type or its array type, so
+// this can be expensive for types used by large tables.
+func (p *planner) checkEnum(ctx context.Context, desc *typedesc.Mutable) ([]tree.Datums, error) {
+	if desc.Kind != descpb.TypeDescriptor_ENUM &&
+		desc.Kind != descpb.TypeDescriptor_MULTIREGION_ENUM {
+		return nil, pgerror.Newf(pgcode.WrongObjectType, "%q is not an enum", desc.Name)
+	}
+
+	var rows []tree.Datums
+	report := func(issue string, value tree.Datum, detail string) {
+		rows = append(rows, tree.Datums{tree.NewDString(issue), value, tree.NewDString(detail)})
+	}
+
+	for i := range desc.EnumMembers {
+		member := &desc.EnumMembers[i]
+		if l := len(member.PhysicalRepresentation); l >= longEnumPhysicalRepLength {
+			report(enumCheckLongPhysicalRep, tree.NewDString(member.LogicalRepresentation), fmt.Sprintf(
+				"physical representation is %d bytes long; values added next to it will be longer still", l))
+		}
+	}
+
+	// The descriptor can't have been modified since its modification time, so
+	// any read-only values have been read-only for at least this long.
+	age := p.EvalContext().GetStmtTimestamp().Sub(desc.GetModificationTime().GoTime())
+	if age >= staleReadOnlyEnumValueThreshold.Get(&p.ExecCfg().Settings.SV) {
+		for i := range desc.EnumMembers {
+			member := &desc.EnumMembers[i]
+			var op string
+			switch {
+			case member.Staged:
+				// Staged values are expected to stay read-only until they are
+				// promoted.
+				continue
+			case enumMemberIsAdding(member):
+				op = "added"
+			case enumMemberIsRemoving(member):
+				op = "dropped"
+			default:
+				continue
+			}
+			report(enumCheckStaleReadOnlyVal, tree.NewDString(member.LogicalRepresentation), fmt.Sprintf(
+				"value has been in the process of being %s for at least %s; the schema change job may have failed",
+				op, age.Round(time.Second)))
+		}
+	}
+
+	arrayTypeDesc, err := p.Descriptors().ByIDWithLeased(p.txn).WithoutNonPublic().Get().Type(ctx, desc.ArrayTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	orphanedNames, err := p.findOrphanedTypeNames(ctx, desc, arrayTypeDesc)
+	if err != nil {
+		return nil, err
+	}
+	for _, detail := range orphanedNames {
+		report(enumCheckOrphanedName, tree.DNull, detail)
+	}
+
+	used, err := p.findUsedEnumValues(ctx, desc, arrayTypeDesc)
+	if err != nil {
+		return nil, err
+	}
+	for i := range desc.EnumMembers {
+		member := &desc.EnumMembers[i]
+		if member.Capability == descpb.TypeDescriptor_EnumMember_READ_ONLY {
+			continue
+		}
+		if _, ok := used[string(member.PhysicalRepresentation)]; !ok {
+			report(enumCheckUnusedValue, tree.NewDString(member.LogicalRepresentation),
+				"value is not used by any table or view")
+		}
+	}
+	return rows, nil
+}
+
+// findOrphanedTypeNames returns a description of every namespace entry that
+// refers to the type or its array type under a name other than its current
+// one. These are usually left behind by failed renames or schema changes.
+func (p *planner) findOrphanedTypeNames(
+	ctx context.Context, typeDesc *typedesc.Mutable, arrayTypeDesc catalog.TypeDescriptor,
+) ([]string, error) {
+	rows, err := p.InternalSQLTxn().QueryBufferedEx(
+		ctx,
+		"check-type-names",
+		p.txn,
+		sessiondata.NodeUserSessionDataOverride,
+		`SELECT "parentID", "parentSchemaID", name, id FROM system.namespace WHERE id IN ($1, $2) ORDER BY name`,
+		typeDesc.GetID(), arrayTypeDesc.GetID(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var orphaned []string
+	for _, row := range rows {
+		parentID := descpb.ID(tree.MustBeDInt(row[0]))
+		parentSchemaID := descpb.ID(tree.MustBeDInt(row[1]))
+		name := string(tree.MustBeDString(row[2]))
+		desc := catalog.TypeDescriptor(typeDesc)
+		if descpb.ID(tree.MustBeDInt(row[3])) == arrayTypeDesc.GetID() {
+			desc = arrayTypeDesc
+		}
+		if parentID == desc.GetParentID() && parentSchemaID == desc.GetParentSchemaID() &&
+			name == desc.GetName() {
+			continue
+		}
+		orphaned = append(orphaned, fmt.Sprintf(
+			"name %q in database %d schema %d refers to type %q, which is not its current name",
+			name, parentID, parentSchemaID, desc.GetName()))
+	}
+	return orphaned, nil
+}
+
+// findUsedEnumValues returns the physical representations of all values of
+// the enum that are stored in a column of the type or its array type, or that
+// are referenced by a view or by an expression of a referencing table. Values
+// only used in partitioning are not considered.
+func (p *planner) findUsedEnumValues(
+	ctx context.Context, typeDesc *typedesc.Mutable, arrayTypeDesc catalog.TypeDescriptor,
+) (map[string]struct{}, error) {
+	used := make(map[string]struct{})
+	markExprUsages := func(expr string) error {
+		for i := range typeDesc.EnumMembers {
+			member := &typeDesc.EnumMembers[i]
+			foundUsage, err := findUsagesOfEnumValue(expr, member, typeDesc.ID)
+			if err != nil {
+				return err
+			}
+			if foundUsage {
+				used[string(member.PhysicalRepresentation)] = struct{}{}
+			}
+		}
+		return nil
+	}
+
+	checkTable := func(id descpb.ID, colTypeID descpb.ID, isArray bool) error {
+		desc, err := p.Descriptors().ByIDWithLeased(p.txn).WithoutNonPublic().Get().Table(ctx, id)
+		if err != nil {
+			return err
+		}
+		if desc.IsView() {
+			for i := range typeDesc.EnumMembers {
+				member := &typeDesc.EnumMembers[i]
+				foundUsage, err := findUsagesOfEnumValueInViewQuery(desc.GetViewQuery(), member, typeDesc.ID)
+				if err != nil {
+					return err
+				}
+				if foundUsage {
+					used[string(member.PhysicalRepresentation)] = struct{}{}
+				}
+			}
+			return nil
+		}
+
+		for _, idx := range desc.AllIndexes() {
+			if pred := idx.GetPredicate(); pred != "" {
+				if err := markExprUsages(pred); err != nil {
+					return err
+				}
+			}
+		}
+		for _, chk := range desc.CheckConstraints() {
+			if err := markExprUsages(chk.GetExpr()); err != nil {
+				return err
+			}
+		}
+		for _, col := range desc.PublicColumns() {
+			for _, expr := range []string{col.GetDefaultExpr(), col.GetComputeExpr(), col.GetOnUpdateExpr()} {
+				if expr == "" {
+					continue
+				}
+				if err := markExprUsages(expr); err != nil {
+					return err
+				}
+			}
+
+			if !col.GetType().UserDefined() || typedesc.GetUserDefinedTypeDescID(col.GetType()) != colTypeID {
+				continue
+			}
+			colName := col.ColName()
+			query := fmt.Sprintf("SELECT DISTINCT t.%s FROM [%d AS t] WHERE t.%s IS NOT NULL",
+				colName.String(), id, colName.String())
+			if isArray {
+				query = fmt.Sprintf("SELECT DISTINCT unnest(t.%s) FROM [%d AS t]", colName.String(), id)
+			}
+			rows, err := p.InternalSQLTxn().QueryBufferedEx(
+				ctx, "check-enum-value-usage", p.txn, sessiondata.NodeUserSessionDataOverride, query,
+			)
+			if err != nil {
+				return err
+			}
+			for _, row := range rows {
+				if e, ok := tree.UnwrapDOidWrapper(row[0]).(*tree.DEnum); ok {
+					used[string(e.PhysicalRep)] = struct{}{}
+				}
+			}
+		}
+		return nil
+	}
+
+	for _, id := range typeDesc.ReferencingDescriptorIDs {
+		if err := checkTable(id, typeDesc.ID, false /* isArray */); err != nil {
+			return nil, err
+		}
+	}
+	for i := 0; i < arrayTypeDesc.NumReferencingDescriptors(); i++ {
+		if err := checkTable(
+			arrayTypeDesc.GetReferencingDescriptorID(i), arrayTypeDesc.GetID(), true, /* isArray */
+		); err != nil {
+			return nil, err
+		}
+	}
+	return used, nil
+}
+
+// countEnumValueRows returns the number of rows that store the given member of
+// the enum in a column of the type or its array type. Rows that store it in
+// several columns are counted once. Like findUsedEnumValues, this scans every
+// table that uses the type.
+func (p *planner) countEnumValueRows(
+	ctx context.Context, typeDesc *typedesc.Mutable, member *descpb.TypeDescriptor_EnumMember,
+) (int64, error) {
+	arrayTypeDesc, err := p.Descriptors().ByIDWithLeased(p.txn).WithoutNonPublic().Get().Type(ctx, typeDesc.ArrayTypeID)
+	if err != nil {
+		return 0, err
+	}
+	rep, err := convertToSQLStringRepresentation(member.PhysicalRepresentation)
+	if err != nil {
+		return 0, err
+	}
+	typeOID := catid.TypeIDToOID(typeDesc.ID)
+
+	// A table may use both the type and its array type.
+	ids := catalog.MakeDescriptorIDSet(typeDesc.ReferencingDescriptorIDs...)
+	for i := 0; i < arrayTypeDesc.NumReferencingDescriptors(); i++ {
+		ids.Add(arrayTypeDesc.GetReferencingDescriptorID(i))
+	}
+	var count int64
+	for _, id := range ids.Ordered() {
+		desc, err := p.Descriptors().ByIDWithLeased(p.txn).WithoutNonPublic().Get().Table(ctx, id)
+		if err != nil {
+			return 0, err
+		}
+		if desc.IsView() {
+			continue
+		}
+		var preds []string
+		for _, col := range desc.PublicColumns() {
+			if !col.GetType().UserDefined() {
+				continue
+			}
+			colName := col.ColName()
+			switch typedesc.GetUserDefinedTypeDescID(col.GetType()) {
+			case typeDesc.ID:
+				preds = append(preds, fmt.Sprintf("t.%s = %s", colName.String(), rep))
+			case arrayTypeDesc.GetID():
+				preds = append(preds, fmt.Sprintf("%s::@%d = ANY (t.%s)", rep, typeOID, colName.String()))
+			}
+		}
+		if len(preds) == 0 {
+			continue
+		}
+		row, err := p.InternalSQLTxn().QueryRowEx(
+			ctx, "count-enum-value-rows", p.txn, sessiondata.NodeUserSessionDataOverride,
+			fmt.Sprintf("SELECT count(*) FROM [%d AS t] WHERE %s", id, strings.Join(preds, " OR ")),
+		)
+		if err != nil {
+			return 0, err
+		}
+		count += int64(tree.MustBeDInt(row[0]))
+	}
+	return count, nil
+}
+
+// validateEnumData runs ALTER TYPE ... VALIDATE DATA, which scans every column
+// of the type or its array type and returns a row for each stored physical
+// representation that doesn't belong to any member of the enum, along with the
+// number of rows that store it. A notice is sent as each table is finished,
+// since this reads every row of every table that uses the type.
+func (p *planner) validateEnumData(
+	ctx context.Context, desc *typedesc.Mutable,
+) ([]tree.Datums, error) {
+	if desc.Kind != descpb.TypeDescriptor_ENUM &&
+		desc.Kind != descpb.TypeDescriptor_MULTIREGION_ENUM {
+		return nil, pgerror.Newf(pgcode.WrongObjectType, "%q is not an enum", desc.Name)
+	}
+	arrayTypeDesc, err := p.Descriptors().ByIDWithLeased(p.txn).WithoutNonPublic().Get().Type(ctx, desc.ArrayTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Values that are being added or dropped may already be stored, so all
+	// members count, not just the writable ones.
+	members := make(map[string]struct{}, len(desc.EnumMembers))
+	for i := range desc.EnumMembers {
+		members[string(desc.EnumMembers[i].PhysicalRepresentation)] = struct{}{}
+	}
+
+	// A table may use both the type and its array type.
+	ids := catalog.MakeDescriptorIDSet(desc.ReferencingDescriptorIDs...)
+	for i := 0; i < arrayTypeDesc.NumReferencingDescriptors(); i++ {
+		ids.Add(arrayTypeDesc.GetReferencingDescriptorID(i))
+	}
+	var tables []catalog.TableDescriptor
+	for _, id := range ids.Ordered() {
+		tableDesc, err := p.Descriptors().ByIDWithLeased(p.txn).WithoutNonPublic().Get().Table(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if tableDesc.IsView() {
+			continue
+		}
+		tables = append(tables, tableDesc)
+	}
+
+	var rows []tree.Datums
+	for i, tableDesc := range tables {
+		tn, err := p.getQualifiedTableName(ctx, tableDesc)
+		if err != nil {
+			return nil, err
+		}
+		orphaned, err := p.findOrphanedEnumReps(ctx, tableDesc, desc.ID, arrayTypeDesc.GetID(), members)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range orphaned {
+			rows = append(rows, tree.Datums{
+				tree.NewDString(tn.FQString()),
+				tree.NewDString(o.column),
+				tree.NewDBytes(tree.DBytes(o.rep)),
+				tree.NewDInt(tree.DInt(o.count)),
+			})
+		}
+		p.BufferClientNotice(ctx, pgnotice.Newf(
+			"validated data in table %s (%d of %d)", tn.FQString(), i+1, len(tables)))
+	}
+	return rows, nil
+}
+
+// orphanedEnumRep is a physical representation stored in a column that doesn't
+// belong to any member of the column's enum.
+type orphanedEnumRep struct {
+	column string
+	rep    string
+	count  int64
+}
+
+// findOrphanedEnumReps scans the primary index of the table and returns the
+// physical representations stored in its columns of the given type or array
+// type that aren't in members, ordered by column and then by representation.
+// A row that stores a representation several times in an array is counted
+// once.
+//
+// Orphaned representations can't be decoded as enum values, so the table is
+// read directly rather than through SQL, and the columns are decoded as bytes.
+// This is possible because enums are encoded like bytes in both keys and
+// values.
+func (p *planner) findOrphanedEnumReps(
+	ctx context.Context,
+	tableDesc catalog.TableDescriptor,
+	typeID, arrayTypeID descpb.ID,
+	members map[string]struct{},
+) ([]orphanedEnumRep, error) {
+	var colIDs []descpb.ColumnID
+	var colNames []string
+	colTypes := make(map[descpb.ColumnID]*types.T)
+	for _, col := range tableDesc.PublicColumns() {
+		// Virtual columns aren't stored, and their expressions are checked when
+		// they are evaluated.
+		if col.IsVirtual() || !col.GetType().UserDefined() {
+			continue
+		}
+		switch typedesc.GetUserDefinedTypeDescID(col.GetType()) {
+		case typeID:
+			colTypes[col.GetID()] = types.Bytes
+		case arrayTypeID:
+			colTypes[col.GetID()] = types.BytesArray
+		default:
+			continue
+		}
+		colIDs = append(colIDs, col.GetID())
+		colNames = append(colNames, col.GetName())
+	}
+	if len(colIDs) == 0 {
+		return nil, nil
+	}
+
+	codec := p.ExecCfg().Codec
+	var spec fetchpb.IndexFetchSpec
+	if err := rowenc.InitIndexFetchSpec(
+		&spec, codec, tableDesc, tableDesc.GetPrimaryIndex(), colIDs,
+	); err != nil {
+		return nil, err
+	}
+	for i := range spec.KeyAndSuffixColumns {
+		if typ, ok := colTypes[spec.KeyAndSuffixColumns[i].ColumnID]; ok {
+			spec.KeyAndSuffixColumns[i].Type = typ
+		}
+	}
+	for i := range spec.FetchedColumns {
+		spec.FetchedColumns[i].Type = colTypes[spec.FetchedColumns[i].ColumnID]
+	}
+
+	var fetcher row.Fetcher
+	if err := fetcher.Init(
+		ctx,
+		row.FetcherInitArgs{
+			Txn:   p.txn,
+			Alloc: &tree.DatumAlloc{},
+			Spec:  &spec,
+		},
+	); err != nil {
+		return nil, err
+	}
+	defer fetcher.Close(ctx)
+	if err := fetcher.StartScan(
+		ctx, roachpb.Spans{tableDesc.PrimaryIndexSpan(codec)}, nil, /* spanIDs */
+		rowinfra.GetDefaultBatchBytesLimit(false /* forceProductionValue */), rowinfra.NoRowLimit,
+	); err != nil {
+		return nil, err
+	}
+
+	counts := make([]map[string]int64, len(colIDs))
+	var reps []string
+	for {
+		datums, err := fetcher.NextRowDecoded(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if datums == nil {
+			break
+		}
+		for i, d := range datums {
+			reps = reps[:0]
+			switch t := d.(type) {
+			case *tree.DBytes:
+				reps = append(reps, string(*t))
+			case *tree.DArray:
+				for _, elem := range t.Array {
+					if b, ok := elem.(*tree.DBytes); ok {
+						reps = append(reps, string(*b))
+					}
+				}
+			}
+		outer:
+			for j, rep := range reps {
+				if _, ok := members[rep]; ok {
+					continue
+				}
+				for _, prev := range reps[:j] {
+					if prev == rep {
+						continue outer
+					}
+				}
+				if counts[i] == nil {
+					counts[i] = make(map[string]int64)
+				}
+				counts[i][rep]++
+			}
+		}
+	}
+
+	var orphaned []orphanedEnumRep
+	for i, colCounts := range counts {
+		sorted := make([]string, 0, len(colCounts))
+		for rep := range colCounts {
+			sorted = append(sorted, rep)
+		}
+		sort.Strings(sorted)
+		for _, rep := range sorted {
+			orphaned = append(orphaned, orphanedEnumRep{column: colNames[i], rep: rep, count: colCounts[rep]})
+		}
+	}
+	return orphaned, nil
+}
@@ -224,6 +224,23 @@ var AlterTableSplitColumns = ResultColumns{
 	{Name: "split_enforced_until", Typ: types.Timestamp},
 }
 
+// AlterTypeCheckColumns are the result columns of an
+// ALTER TYPE .. CHECK statement.
+var AlterTypeCheckColumns = ResultColumns{
+	{Name: "issue", Typ: types.String},
+	{Name: "value", Typ: types.String},
+	{Name: "detail", Typ: types.String},
+}
+
+// AlterTypeValidateDataColumns are the result columns of an
+// ALTER TYPE .. VALIDATE DATA statement.
+var AlterTypeValidateDataColumns = ResultColumns{
+	{Name: "table_name", Typ: types.String},
+	{Name: "column_name", Typ: types.String},
+	{Name: "physical_representation", Typ: types.Bytes},
+	{Name: "row_count", Typ: types.Int},
+}
+
 // AlterTableUnsplitColumns are the result columns of an
 // ALTER TABLE/INDEX .. UNSPLIT statement.
 var AlterTableUnsplitColumns = ResultColumns{
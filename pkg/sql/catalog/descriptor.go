@@ -852,6 +852,9 @@ type EnumTypeDescriptor interface {
 	// IsMemberReadOnly returns true iff the enum member at ordinal
 	// enumMemberOrdinal is read-only.
 	IsMemberReadOnly(enumMemberOrdinal int) bool
+	// GetMemberCode returns the code of the enum member at ordinal
+	// enumMemberOrdinal, or nil if it has none.
+	GetMemberCode(enumMemberOrdinal int) *int64
 }
 
 // RegionEnumTypeDescriptor is the TypeDescriptor subtype for multi-region enums.
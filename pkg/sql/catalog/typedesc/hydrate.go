@@ -102,11 +102,39 @@ func ensureTypeIsHydratedRecursive(
 			}
 			maybeName = &name
 		}
+		if c := maybeDesc.AsCompositeTypeDescriptor(); c != nil && t.Family() == types.TupleFamily {
+			ensureCompositeElementsAreHydrated(t, c)
+		}
 	}
 	ensureTypeMetadataIsHydrated(&t.TypeMeta, maybeName, maybeDesc)
 	return nil
 }
 
+// ensureCompositeElementsAreHydrated makes sure that the composite type t has
+// all the elements of its descriptor. Types are serialized along with their
+// elements, for example in the columns of tables, so they miss the attributes
+// that were added with ALTER TYPE ... ADD ATTRIBUTE since. Attributes can't be
+// dropped or reordered, so the elements only need to be rebuilt if there are
+// fewer of them than in the descriptor. The added elements can't refer to
+// user-defined types, so they don't need to be hydrated themselves.
+func ensureCompositeElementsAreHydrated(t *types.T, c catalog.CompositeTypeDescriptor) {
+	n := c.NumElements()
+	if len(t.TupleContents()) >= n {
+		return
+	}
+	contents := make([]*types.T, n)
+	labels := make([]string, n)
+	copy(contents, t.TupleContents())
+	for i := 0; i < n; i++ {
+		if i >= len(t.TupleContents()) {
+			contents[i] = c.GetElementType(i)
+		}
+		labels[i] = c.GetElementLabel(i)
+	}
+	t.InternalType.TupleContents = contents
+	t.InternalType.TupleLabels = labels
+}
+
 func ensureTypeMetadataIsHydrated(
 	tm *types.UserDefinedTypeMetadata, maybeName *tree.TypeName, maybeDesc catalog.TypeDescriptor,
 ) {
@@ -144,6 +172,7 @@ func ensureTypeMetadataIsHydrated(
 				LogicalRepresentations:  imm.logicalReps,
 				PhysicalRepresentations: imm.physicalReps,
 				IsMemberReadOnly:        imm.readOnlyMembers,
+				Codes:                   imm.codes,
 			}
 		} else {
 			n := e.NumEnumMembers()
@@ -151,11 +180,13 @@ func ensureTypeMetadataIsHydrated(
 				LogicalRepresentations:  make([]string, n),
 				PhysicalRepresentations: make([][]byte, n),
 				IsMemberReadOnly:        make([]bool, n),
+				Codes:                   make([]*int64, n),
 			}
 			for i := 0; i < n; i++ {
 				tm.EnumData.LogicalRepresentations[i] = e.GetMemberLogicalRepresentation(i)
 				tm.EnumData.PhysicalRepresentations[i] = e.GetMemberPhysicalRepresentation(i)
 				tm.EnumData.IsMemberReadOnly[i] = e.IsMemberReadOnly(i)
+				tm.EnumData.Codes[i] = e.GetMemberCode(i)
 			}
 		}
 	}
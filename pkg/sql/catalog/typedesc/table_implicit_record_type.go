@@ -265,6 +265,9 @@ func (v *tableImplicitRecordType) GetMemberLogicalRepresentation(_ int) string {
 // IsMemberReadOnly implements the catalog.TypeDescriptor interface.
 func (v *tableImplicitRecordType) IsMemberReadOnly(_ int) bool { return false }
 
+// GetMemberCode implements the catalog.TypeDescriptor interface.
+func (v *tableImplicitRecordType) GetMemberCode(_ int) *int64 { return nil }
+
 // NumReferencingDescriptors implements the catalog.TypeDescriptor interface.
 func (v *tableImplicitRecordType) NumReferencingDescriptors() int { return 0 }
 
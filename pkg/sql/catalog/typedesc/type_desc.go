@@ -19,6 +19,7 @@ import (
 	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catprivilege"
@@ -94,6 +95,7 @@ type immutable struct {
 	logicalReps     []string
 	physicalReps    [][]byte
 	readOnlyMembers []bool
+	codes           []*int64
 
 	// isUncommittedVersion is set to true if this descriptor was created from
 	// a copy of a Mutable with an uncommitted version.
@@ -105,6 +107,15 @@ type immutable struct {
 
 	// This is the raw bytes (tag + data) of the type descriptor in storage.
 	rawBytesInStorage []byte
+
+	// usableInTxnMembers contains the physical representations of the members
+	// that were added by the transaction that this uncommitted version of the
+	// descriptor belongs to, and which that transaction can use even though
+	// they are still READ_ONLY. It is never persisted, so the members remain
+	// unusable for all other transactions until the schema change promotes
+	// them, and it is discarded along with the descriptor if the transaction
+	// rolls back.
+	usableInTxnMembers map[string]struct{}
 }
 
 // UpdateCachedFieldsOnModifiedMutable refreshes the immutable field by
@@ -210,14 +221,18 @@ func (desc *immutable) GetDeclarativeSchemaChangerState() *scpb.DescriptorState
 // It overrides the wrapper's implementation to deal with the fact that
 // mutable has overridden the definition of IsUncommittedVersion.
 func (desc *Mutable) NewBuilder() catalog.DescriptorBuilder {
-	b := newBuilder(desc.TypeDesc(), hlc.Timestamp{}, desc.IsUncommittedVersion(), desc.changes)
+	b := newBuilder(
+		desc.TypeDesc(), hlc.Timestamp{}, desc.IsUncommittedVersion(), desc.changes, desc.usableInTxnMembers,
+	)
 	b.SetRawBytesInStorage(desc.GetRawBytesInStorage())
 	return b
 }
 
 // NewBuilder implements the catalog.Descriptor interface.
 func (desc *immutable) NewBuilder() catalog.DescriptorBuilder {
-	b := newBuilder(desc.TypeDesc(), hlc.Timestamp{}, desc.IsUncommittedVersion(), desc.changes)
+	b := newBuilder(
+		desc.TypeDesc(), hlc.Timestamp{}, desc.IsUncommittedVersion(), desc.changes, desc.usableInTxnMembers,
+	)
 	b.SetRawBytesInStorage(desc.GetRawBytesInStorage())
 	return b
 }
@@ -329,24 +344,355 @@ func (desc *Mutable) SetOffline(reason string) {
 	desc.OfflineReason = reason
 }
 
-// DropEnumValue removes an enum member from the type.
-// DropEnumValue assumes that the type is an enum and that the value being
-// removed is in the prerequisite state to remove.
-func (desc *Mutable) DropEnumValue(value tree.EnumValue) {
+// RemoveEnumValue marks an enum member for removal from the type. It returns
+// an error if no other member would be left once the members that are being
+// removed are gone. RemoveEnumValue assumes that the type is an enum and that
+// the value being removed is in the prerequisite state to remove.
+func (desc *Mutable) RemoveEnumValue(value tree.EnumValue) error {
+	var toRemove *descpb.TypeDescriptor_EnumMember
+	remaining := 0
+	for i := range desc.EnumMembers {
+		member := &desc.EnumMembers[i]
+		if member.LogicalRepresentation == string(value) {
+			toRemove = member
+		} else if member.Direction != descpb.TypeDescriptor_EnumMember_REMOVE {
+			remaining++
+		}
+	}
+	if toRemove == nil {
+		return pgerror.Newf(pgcode.UndefinedObject, "enum value %q does not exist", value)
+	}
+	if remaining == 0 {
+		return errors.WithHint(pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"cannot drop enum value %q: it is the last value of type %q", value, desc.Name),
+			"use DROP TYPE to drop the type instead")
+	}
+	toRemove.Capability = descpb.TypeDescriptor_EnumMember_READ_ONLY
+	toRemove.Direction = descpb.TypeDescriptor_EnumMember_REMOVE
+	return nil
+}
+
+// PromoteEnumValue makes a staged enum member writable. PromoteEnumValue
+// assumes that the type is an enum and that the value is staged. Nodes have
+// been able to read the member since it was staged, so unlike values that are
+// being added, it can be made writable right away.
+func (desc *Mutable) PromoteEnumValue(value tree.EnumValue) {
+	for i := range desc.EnumMembers {
+		member := &desc.EnumMembers[i]
+		if member.LogicalRepresentation == string(value) {
+			member.Capability = descpb.TypeDescriptor_EnumMember_ALL
+			member.Direction = descpb.TypeDescriptor_EnumMember_NONE
+			member.Staged = false
+			break
+		}
+	}
+}
+
+// SetEnumValueCode sets the code of an enum member. SetEnumValueCode assumes
+// that the type is an enum, that the value exists and that no other member
+// has the code.
+func (desc *Mutable) SetEnumValueCode(value tree.EnumValue, code int64) {
+	for i := range desc.EnumMembers {
+		member := &desc.EnumMembers[i]
+		if member.LogicalRepresentation == string(value) {
+			member.Code = &code
+			break
+		}
+	}
+}
+
+// SetRoleValueRestriction limits the enum members that the given role can
+// write to the members with the given physical representations, replacing any
+// existing restriction for the role. A nil values removes the restriction.
+func (desc *Mutable) SetRoleValueRestriction(role username.SQLUsername, values [][]byte) {
+	restrictions := desc.RoleValueRestrictions[:0]
+	for _, r := range desc.RoleValueRestrictions {
+		if r.RoleProto.Decode() != role {
+			restrictions = append(restrictions, r)
+		}
+	}
+	if values != nil {
+		restrictions = append(restrictions, descpb.TypeDescriptor_RoleValueRestriction{
+			RoleProto:     role.EncodeProto(),
+			AllowedValues: values,
+		})
+	}
+	desc.RoleValueRestrictions = restrictions
+}
+
+// AddCheckConstraint adds a constraint with the given name and serialized
+// expression to the type, in the Validating state. AddCheckConstraint assumes
+// that no constraint of the type already has the name.
+func (desc *Mutable) AddCheckConstraint(name, expr string) {
+	desc.CheckConstraints = append(desc.CheckConstraints, descpb.TypeDescriptor_CheckConstraint{
+		Name:     name,
+		Expr:     expr,
+		Validity: descpb.ConstraintValidity_Validating,
+	})
+}
+
+// DropCheckConstraint removes the constraint with the given name from the
+// type, and returns whether it existed.
+func (desc *Mutable) DropCheckConstraint(name string) bool {
+	for i := range desc.CheckConstraints {
+		if desc.CheckConstraints[i].Name == name {
+			desc.CheckConstraints = append(desc.CheckConstraints[:i], desc.CheckConstraints[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// DropEnumValueWithReplacement marks the given enum value for removal, and
+// records the value that rows using it should be rewritten to before it is
+// removed. DropEnumValueWithReplacement assumes that the type is an enum, and
+// that both values exist in the enum.
+func (desc *Mutable) DropEnumValueWithReplacement(value, replacement tree.EnumValue) {
+	var replacementRep []byte
+	for i := range desc.EnumMembers {
+		if desc.EnumMembers[i].LogicalRepresentation == string(replacement) {
+			replacementRep = desc.EnumMembers[i].PhysicalRepresentation
+			break
+		}
+	}
 	for i := range desc.EnumMembers {
 		member := &desc.EnumMembers[i]
 		if member.LogicalRepresentation == string(value) {
 			member.Capability = descpb.TypeDescriptor_EnumMember_READ_ONLY
 			member.Direction = descpb.TypeDescriptor_EnumMember_REMOVE
+			member.ReplacementPhysicalRepresentation = replacementRep
 			break
 		}
 	}
 }
 
+// MergeDuplicateEnumValues marks every member that shares its logical
+// representation with a member earlier in physical order for removal, with the
+// earlier member as its replacement. Members that are already being merged
+// keep their replacement. It returns the logical representations that had
+// duplicates. MergeDuplicateEnumValues assumes that the type is an enum.
+func (desc *Mutable) MergeDuplicateEnumValues() []string {
+	var merged []string
+	canonical := make(map[string][]byte, len(desc.EnumMembers))
+	reported := make(map[string]struct{})
+	for i := range desc.EnumMembers {
+		member := &desc.EnumMembers[i]
+		if IsEnumMemberMerge(desc.EnumMembers, member) {
+			if _, ok := reported[member.LogicalRepresentation]; !ok {
+				reported[member.LogicalRepresentation] = struct{}{}
+				merged = append(merged, member.LogicalRepresentation)
+			}
+			continue
+		}
+		rep, ok := canonical[member.LogicalRepresentation]
+		if !ok {
+			canonical[member.LogicalRepresentation] = member.PhysicalRepresentation
+			continue
+		}
+		member.Capability = descpb.TypeDescriptor_EnumMember_READ_ONLY
+		member.Direction = descpb.TypeDescriptor_EnumMember_REMOVE
+		member.ReplacementPhysicalRepresentation = rep
+		if _, ok := reported[member.LogicalRepresentation]; !ok {
+			reported[member.LogicalRepresentation] = struct{}{}
+			merged = append(merged, member.LogicalRepresentation)
+		}
+	}
+	return merged
+}
+
+// CompactEnumValues gives every member whose physical representation can be
+// shortened a shorter one. The member is copied with the new representation,
+// and the original is marked for removal with the copy as its replacement, in
+// the same way that MergeDuplicateEnumValues merges duplicates. The copy is
+// added read-only, like any other new member. Its representation sorts
+// between the representations of the neighbouring members, both original and
+// new, so that the order of the values is preserved while rows are being
+// rewritten and afterwards. It returns the logical representations of the
+// compacted members. CompactEnumValues assumes that the type is an enum
+// without members that are being added or removed.
+func (desc *Mutable) CompactEnumValues() []string {
+	var compacted []string
+	members := make([]descpb.TypeDescriptor_EnumMember, 0, len(desc.EnumMembers))
+	// lo is the greatest representation used by the previous member.
+	var lo []byte
+	for i, member := range desc.EnumMembers {
+		var hi []byte
+		if i+1 < len(desc.EnumMembers) {
+			hi = desc.EnumMembers[i+1].PhysicalRepresentation
+		}
+		rep := enum.GenByteStringBetween(lo, hi, enum.SpreadSpacing)
+		if len(rep) >= len(member.PhysicalRepresentation) {
+			members = append(members, member)
+			lo = member.PhysicalRepresentation
+			continue
+		}
+		compacted = append(compacted, member.LogicalRepresentation)
+		compact := member
+		compact.PhysicalRepresentation = rep
+		compact.Capability = descpb.TypeDescriptor_EnumMember_READ_ONLY
+		compact.Direction = descpb.TypeDescriptor_EnumMember_ADD
+		member.Capability = descpb.TypeDescriptor_EnumMember_READ_ONLY
+		member.Direction = descpb.TypeDescriptor_EnumMember_REMOVE
+		member.ReplacementPhysicalRepresentation = rep
+		if bytes.Compare(rep, member.PhysicalRepresentation) < 0 {
+			members = append(members, compact, member)
+			lo = member.PhysicalRepresentation
+		} else {
+			members = append(members, member, compact)
+			lo = rep
+		}
+	}
+	desc.EnumMembers = members
+	return compacted
+}
+
+// NormalizeEnumValues moves members towards representations that are the
+// integers 0, 1, 2, ... in logical order, as given by enum.IntegerRepresentation.
+// Members are moved like CompactEnumValues compacts them, so both the original
+// and the new representation of a moved member must sort between the
+// representations of its neighbours. This isn't possible for all members at
+// once in general, so the members are moved in rounds, each of which moves as
+// many members as possible from left to right, and at least one. It returns
+// the logical representations of the members moved in this round, and the
+// number of members that still need to be moved in later rounds.
+// NormalizeEnumValues assumes that the type is an enum without members that
+// are being added or removed, and with at most enum.MaxIntegerRepresentations
+// members.
+func (desc *Mutable) NormalizeEnumValues() (normalized []string, remaining int) {
+	targets := make([][]byte, len(desc.EnumMembers))
+	for i := range targets {
+		targets[i] = enum.IntegerRepresentation(i)
+	}
+	return desc.moveEnumValues(targets)
+}
+
+// RespaceEnumValues moves members towards evenly spaced representations, as
+// given by enum.GenerateNEvenlySpacedBytes, so that values can be added between
+// any two of them again without their representations growing. Like
+// NormalizeEnumValues, it moves the members in rounds, and returns the members
+// moved in this round and the number of members that remain to be moved.
+// RespaceEnumValues assumes that the type is an enum without members that are
+// being added or removed.
+func (desc *Mutable) RespaceEnumValues() (respaced []string, remaining int) {
+	return desc.moveEnumValues(enum.GenerateNEvenlySpacedBytes(len(desc.EnumMembers)))
+}
+
+// moveEnumValues moves as many members as it can towards the representation at
+// the same index of targets, for NormalizeEnumValues and RespaceEnumValues.
+func (desc *Mutable) moveEnumValues(targets [][]byte) (moved []string, remaining int) {
+	members := make([]descpb.TypeDescriptor_EnumMember, 0, len(desc.EnumMembers))
+	// lo is the greatest representation used by the previous member.
+	var lo []byte
+	for i, member := range desc.EnumMembers {
+		rep := targets[i]
+		if bytes.Equal(rep, member.PhysicalRepresentation) {
+			members = append(members, member)
+			lo = member.PhysicalRepresentation
+			continue
+		}
+		first, last := rep, member.PhysicalRepresentation
+		if bytes.Compare(first, last) > 0 {
+			first, last = last, first
+		}
+		if bytes.Compare(first, lo) <= 0 || (i+1 < len(desc.EnumMembers) &&
+			bytes.Compare(last, desc.EnumMembers[i+1].PhysicalRepresentation) >= 0) {
+			members = append(members, member)
+			lo = member.PhysicalRepresentation
+			remaining++
+			continue
+		}
+		moved = append(moved, member.LogicalRepresentation)
+		cp := member
+		cp.PhysicalRepresentation = rep
+		cp.Capability = descpb.TypeDescriptor_EnumMember_READ_ONLY
+		cp.Direction = descpb.TypeDescriptor_EnumMember_ADD
+		member.Capability = descpb.TypeDescriptor_EnumMember_READ_ONLY
+		member.Direction = descpb.TypeDescriptor_EnumMember_REMOVE
+		member.ReplacementPhysicalRepresentation = rep
+		if bytes.Equal(first, rep) {
+			members = append(members, cp, member)
+		} else {
+			members = append(members, member, cp)
+		}
+		lo = last
+	}
+	desc.EnumMembers = members
+	return moved, remaining
+}
+
+// IsEnumMemberCompactionCopy returns whether the given member is being added
+// by CompactEnumValues, NormalizeEnumValues or RespaceEnumValues as the copy of
+// another member.
+func IsEnumMemberCompactionCopy(
+	members []descpb.TypeDescriptor_EnumMember, member *descpb.TypeDescriptor_EnumMember,
+) bool {
+	if member.Direction != descpb.TypeDescriptor_EnumMember_ADD {
+		return false
+	}
+	for i := range members {
+		if bytes.Equal(members[i].ReplacementPhysicalRepresentation, member.PhysicalRepresentation) {
+			return IsEnumMemberMerge(members, &members[i])
+		}
+	}
+	return false
+}
+
+// IsEnumMemberMerge returns whether the given member is being merged into
+// another member with the same logical representation by
+// MergeDuplicateEnumValues, CompactEnumValues, NormalizeEnumValues or
+// RespaceEnumValues.
+func IsEnumMemberMerge(
+	members []descpb.TypeDescriptor_EnumMember, member *descpb.TypeDescriptor_EnumMember,
+) bool {
+	if member.Direction != descpb.TypeDescriptor_EnumMember_REMOVE ||
+		member.ReplacementPhysicalRepresentation == nil {
+		return false
+	}
+	for i := range members {
+		if bytes.Equal(members[i].PhysicalRepresentation, member.ReplacementPhysicalRepresentation) {
+			return members[i].LogicalRepresentation == member.LogicalRepresentation
+		}
+	}
+	return false
+}
+
+// ErrEnumValueExists is returned by AddEnumValue when the value to add already
+// exists in the enum, so that callers can tell it apart from other failures.
+var ErrEnumValueExists = errors.New("enum value already exists")
+
+// ValidateEnumLabel returns an error if the label is empty or longer than
+// maxLength bytes. A maxLength of 0 leaves the length unlimited.
+func ValidateEnumLabel(label string, maxLength int64) error {
+	if label == "" {
+		return pgerror.New(pgcode.InvalidName, "enum labels cannot be empty")
+	}
+	if maxLength > 0 && int64(len(label)) > maxLength {
+		return errors.WithDetailf(
+			pgerror.Newf(pgcode.NameTooLong, "enum label is too long (%d bytes)", len(label)),
+			"labels must be at most %d bytes long", maxLength,
+		)
+	}
+	return nil
+}
+
 // AddEnumValue adds an enum member to the type.
-// AddEnumValue assumes that the type is an enum, and that the new value
-// doesn't exist already in the enum.
-func (desc *Mutable) AddEnumValue(node *tree.AlterTypeAddValue) error {
+// AddEnumValue assumes that the type is an enum. If the new value already
+// exists in the enum, including as a member that is being dropped, the
+// returned error is marked with ErrEnumValueExists. Otherwise, the new value
+// is validated with ValidateEnumLabel and maxLabelLength.
+func (desc *Mutable) AddEnumValue(node *tree.AlterTypeAddValue, maxLabelLength int64) error {
+	for i := range desc.EnumMembers {
+		if desc.EnumMembers[i].LogicalRepresentation == string(node.NewVal) {
+			return errors.Mark(
+				pgerror.Newf(pgcode.DuplicateObject, "enum value %q already exists", node.NewVal),
+				ErrEnumValueExists,
+			)
+		}
+	}
+	if err := ValidateEnumLabel(string(node.NewVal), maxLabelLength); err != nil {
+		return err
+	}
+
 	getPhysicalRep := func(idx int) []byte {
 		if idx < 0 || idx >= len(desc.EnumMembers) {
 			return nil
@@ -389,6 +735,7 @@ func (desc *Mutable) AddEnumValue(node *tree.AlterTypeAddValue) error {
 		PhysicalRepresentation: newPhysicalRep,
 		Capability:             descpb.TypeDescriptor_EnumMember_READ_ONLY,
 		Direction:              descpb.TypeDescriptor_EnumMember_ADD,
+		Staged:                 node.Staged,
 	}
 
 	// Now, insert the new member.
@@ -408,6 +755,41 @@ func (desc *Mutable) AddEnumValue(node *tree.AlterTypeAddValue) error {
 	return nil
 }
 
+// MarkEnumMemberUsableInTxn marks the member with the given physical
+// representation, which must have been added to the type by the current
+// transaction, as usable by that transaction. This takes effect on the
+// uncommitted version of the descriptor once it is written.
+//
+// Nodes that still hold a lease on a version of the type without the member
+// can't decode the rows with it that the transaction commits until they lease
+// a newer version, which the schema change job waits for.
+func (desc *Mutable) MarkEnumMemberUsableInTxn(physicalRep []byte) {
+	// The set is copied, since it is shared with the descriptors that were
+	// built from this one.
+	usable := make(map[string]struct{}, len(desc.usableInTxnMembers)+1)
+	for k := range desc.usableInTxnMembers {
+		usable[k] = struct{}{}
+	}
+	usable[string(physicalRep)] = struct{}{}
+	desc.usableInTxnMembers = usable
+}
+
+// IsEnumMemberPacked returns whether the member at the given index has a longer
+// physical representation than both of its neighbours, i.e. whether there was
+// no room left between their representations when it was added between them.
+// Values that are added next to it will have still longer representations.
+// Members with single byte representations are never packed.
+func IsEnumMemberPacked(members []descpb.TypeDescriptor_EnumMember, idx int) bool {
+	n := len(members[idx].PhysicalRepresentation)
+	if n <= 1 {
+		return false
+	}
+	if idx > 0 && len(members[idx-1].PhysicalRepresentation) >= n {
+		return false
+	}
+	return idx+1 >= len(members) || len(members[idx+1].PhysicalRepresentation) < n
+}
+
 // AddReferencingDescriptorID adds a new referencing descriptor ID to the
 // TypeDescriptor. It ensures that duplicates are not added.
 func (desc *Mutable) AddReferencingDescriptorID(new descpb.ID) {
@@ -526,6 +908,7 @@ func (desc *immutable) validateEnumMembers(vea catalog.ValidationErrorAccumulato
 	// Ensure there are no duplicate enum physical and logical reps.
 	physicalMap := make(map[string]struct{}, len(desc.EnumMembers))
 	logicalMap := make(map[string]struct{}, len(desc.EnumMembers))
+	codeMap := make(map[int64]string)
 	for _, member := range desc.EnumMembers {
 		// Ensure there are no duplicate enum physical reps.
 		_, duplicatePhysical := physicalMap[string(member.PhysicalRepresentation)]
@@ -533,12 +916,15 @@ func (desc *immutable) validateEnumMembers(vea catalog.ValidationErrorAccumulato
 			vea.Report(errors.AssertionFailedf("duplicate enum physical rep %v", member.PhysicalRepresentation))
 		}
 		physicalMap[string(member.PhysicalRepresentation)] = struct{}{}
-		// Ensure there are no duplicate enum logical reps.
-		_, duplicateLogical := logicalMap[member.LogicalRepresentation]
-		if duplicateLogical {
-			vea.Report(errors.AssertionFailedf("duplicate enum member %q", member.LogicalRepresentation))
+		// Ensure there are no duplicate enum logical reps, other than members
+		// that are being merged into a member with the same logical rep.
+		if !IsEnumMemberMerge(desc.EnumMembers, &member) {
+			_, duplicateLogical := logicalMap[member.LogicalRepresentation]
+			if duplicateLogical {
+				vea.Report(errors.AssertionFailedf("duplicate enum member %q", member.LogicalRepresentation))
+			}
+			logicalMap[member.LogicalRepresentation] = struct{}{}
 		}
-		logicalMap[member.LogicalRepresentation] = struct{}{}
 		// Ensure the sanity of enum capabilities and transition directions.
 		switch member.Capability {
 		case descpb.TypeDescriptor_EnumMember_READ_ONLY:
@@ -553,6 +939,39 @@ func (desc *immutable) validateEnumMembers(vea catalog.ValidationErrorAccumulato
 		default:
 			vea.Report(errors.AssertionFailedf("invalid member capability %s", member.Capability))
 		}
+		if member.Staged && member.Direction != descpb.TypeDescriptor_EnumMember_ADD {
+			vea.Report(errors.AssertionFailedf(
+				"enum member %q is staged but is not being added", member.LogicalRepresentation))
+		}
+		// Ensure there are no duplicate codes. Members that are being merged
+		// may share a code with the member they are merged into.
+		if member.Code != nil && !IsEnumMemberMerge(desc.EnumMembers, &member) {
+			if other, ok := codeMap[*member.Code]; ok {
+				vea.Report(errors.AssertionFailedf(
+					"enum members %q and %q have the same code %d", other, member.LogicalRepresentation, *member.Code))
+			}
+			codeMap[*member.Code] = member.LogicalRepresentation
+		}
+	}
+	// Ensure that any replacement for a member being removed refers to another
+	// member of the enum.
+	for _, member := range desc.EnumMembers {
+		if member.ReplacementPhysicalRepresentation == nil {
+			continue
+		}
+		if member.Direction != descpb.TypeDescriptor_EnumMember_REMOVE {
+			vea.Report(errors.AssertionFailedf(
+				"enum member %q has a replacement but is not being removed", member.LogicalRepresentation))
+		}
+		if bytes.Equal(member.ReplacementPhysicalRepresentation, member.PhysicalRepresentation) {
+			vea.Report(errors.AssertionFailedf(
+				"enum member %q can not be replaced with itself", member.LogicalRepresentation))
+		}
+		if _, ok := physicalMap[string(member.ReplacementPhysicalRepresentation)]; !ok {
+			vea.Report(errors.AssertionFailedf(
+				"enum member %q has unknown replacement %v",
+				member.LogicalRepresentation, member.ReplacementPhysicalRepresentation))
+		}
 	}
 	return isSorted
 }
@@ -792,6 +1211,11 @@ func (desc *immutable) IsMemberReadOnly(enumMemberOrdinal int) bool {
 	return desc.readOnlyMembers[enumMemberOrdinal]
 }
 
+// GetMemberCode implements the catalog.EnumTypeDescriptor interface.
+func (desc *immutable) GetMemberCode(enumMemberOrdinal int) *int64 {
+	return desc.codes[enumMemberOrdinal]
+}
+
 // NumReferencingDescriptors implements the catalog.TypeDescriptor interface.
 func (desc *immutable) NumReferencingDescriptors() int {
 	return len(desc.ReferencingDescriptorIDs)
@@ -40,6 +40,9 @@ type typeDescriptorBuilder struct {
 	changes              catalog.PostDeserializationChanges
 	// This is the raw bytes (tag + data) of the type descriptor in storage.
 	rawBytesInStorage []byte
+	// usableInTxnMembers is carried over from the descriptor that the builder
+	// was created from, see immutable.usableInTxnMembers.
+	usableInTxnMembers map[string]struct{}
 }
 
 var _ TypeDescriptorBuilder = &typeDescriptorBuilder{}
@@ -63,6 +66,7 @@ func NewBuilderWithMVCCTimestamp(
 		mvccTimestamp,
 		false, /* isUncommittedVersion */
 		catalog.PostDeserializationChanges{},
+		nil, /* usableInTxnMembers */
 	)
 }
 
@@ -71,12 +75,14 @@ func newBuilder(
 	mvccTimestamp hlc.Timestamp,
 	isUncommittedVersion bool,
 	changes catalog.PostDeserializationChanges,
+	usableInTxnMembers map[string]struct{},
 ) TypeDescriptorBuilder {
 	b := &typeDescriptorBuilder{
 		original:             protoutil.Clone(desc).(*descpb.TypeDescriptor),
 		mvccTimestamp:        mvccTimestamp,
 		isUncommittedVersion: isUncommittedVersion,
 		changes:              changes,
+		usableInTxnMembers:   usableInTxnMembers,
 	}
 	return b
 }
@@ -165,7 +171,7 @@ func (tdb *typeDescriptorBuilder) BuildImmutableType() catalog.TypeDescriptor {
 	if desc == nil {
 		desc = tdb.original
 	}
-	imm := makeImmutable(desc, tdb.isUncommittedVersion, tdb.changes)
+	imm := makeImmutable(desc, tdb.isUncommittedVersion, tdb.changes, tdb.usableInTxnMembers)
 	imm.rawBytesInStorage = append([]byte(nil), tdb.rawBytesInStorage...) // deep-copy
 	return &imm
 }
@@ -182,10 +188,10 @@ func (tdb *typeDescriptorBuilder) BuildExistingMutableType() *Mutable {
 		tdb.maybeModified = protoutil.Clone(tdb.original).(*descpb.TypeDescriptor)
 	}
 	mutableType := makeImmutable(tdb.maybeModified,
-		false /* isUncommitedVersion */, tdb.changes)
+		false /* isUncommitedVersion */, tdb.changes, nil /* usableInTxnMembers */)
 	mutableType.rawBytesInStorage = append([]byte(nil), tdb.rawBytesInStorage...) // deep-copy
 	clusterVersion := makeImmutable(tdb.original,
-		false /* isUncommitedVersion */, catalog.PostDeserializationChanges{})
+		false /* isUncommitedVersion */, catalog.PostDeserializationChanges{}, nil /* usableInTxnMembers */)
 	return &Mutable{
 		immutable:      mutableType,
 		ClusterVersion: &clusterVersion,
@@ -204,7 +210,7 @@ func (tdb *typeDescriptorBuilder) BuildCreatedMutableType() *Mutable {
 	if desc == nil {
 		desc = tdb.original
 	}
-	createdType := makeImmutable(desc, tdb.isUncommittedVersion, tdb.changes)
+	createdType := makeImmutable(desc, tdb.isUncommittedVersion, tdb.changes, tdb.usableInTxnMembers)
 	createdType.rawBytesInStorage = append([]byte(nil), tdb.rawBytesInStorage...) // deep-copy
 	return &Mutable{
 		immutable: createdType,
@@ -215,12 +221,18 @@ func makeImmutable(
 	desc *descpb.TypeDescriptor,
 	isUncommittedVersion bool,
 	changes catalog.PostDeserializationChanges,
+	usableInTxnMembers map[string]struct{},
 ) immutable {
 	immutDesc := immutable{
 		TypeDescriptor:       *desc,
 		isUncommittedVersion: isUncommittedVersion,
 		changes:              changes,
 	}
+	// Members can only be usable in the transaction that added them if this is
+	// the uncommitted version of the descriptor written by that transaction.
+	if isUncommittedVersion {
+		immutDesc.usableInTxnMembers = usableInTxnMembers
+	}
 
 	// Initialize metadata specific to the TypeDescriptor kind.
 	switch immutDesc.Kind {
@@ -228,12 +240,15 @@ func makeImmutable(
 		immutDesc.logicalReps = make([]string, len(desc.EnumMembers))
 		immutDesc.physicalReps = make([][]byte, len(desc.EnumMembers))
 		immutDesc.readOnlyMembers = make([]bool, len(desc.EnumMembers))
+		immutDesc.codes = make([]*int64, len(desc.EnumMembers))
 		for i := range desc.EnumMembers {
 			member := &desc.EnumMembers[i]
 			immutDesc.logicalReps[i] = member.LogicalRepresentation
 			immutDesc.physicalReps[i] = member.PhysicalRepresentation
+			_, usableInTxn := immutDesc.usableInTxnMembers[string(member.PhysicalRepresentation)]
 			immutDesc.readOnlyMembers[i] =
-				member.Capability == descpb.TypeDescriptor_EnumMember_READ_ONLY
+				member.Capability == descpb.TypeDescriptor_EnumMember_READ_ONLY && !usableInTxn
+			immutDesc.codes[i] = member.Code
 		}
 	}
 
@@ -14,6 +14,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sort"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/clusterversion"
@@ -29,11 +30,16 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/nstree"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemadesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/enum"
 	"github.com/cockroachdb/cockroach/pkg/sql/oidext"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/errors"
 	"github.com/lib/pq/oid"
 	"github.com/stretchr/testify/require"
 )
@@ -374,6 +380,7 @@ func TestValidateTypeDesc(t *testing.T) {
 		typeID          = dbID + 2
 		multiRegionDBID = 2000
 	)
+	enumCode := int64(42)
 
 	var cb nstree.MutableCatalog
 	cb.UpsertDescriptor(dbdesc.NewBuilder(&descpb.DatabaseDescriptor{
@@ -522,6 +529,36 @@ func TestValidateTypeDesc(t *testing.T) {
 				Privileges: defaultPrivileges,
 			},
 		},
+		{
+			// Duplicate members are only allowed if they are being merged into a
+			// member with the same logical representation.
+			`duplicate enum member "a"`,
+			descpb.TypeDescriptor{
+				Name:           "t",
+				ID:             typeDescID,
+				ParentID:       dbID,
+				ParentSchemaID: keys.PublicSchemaID,
+				Kind:           descpb.TypeDescriptor_ENUM,
+				EnumMembers: []descpb.TypeDescriptor_EnumMember{
+					{
+						LogicalRepresentation:  "a",
+						PhysicalRepresentation: []byte{1},
+					},
+					{
+						LogicalRepresentation:             "a",
+						PhysicalRepresentation:            []byte{2},
+						Capability:                        descpb.TypeDescriptor_EnumMember_READ_ONLY,
+						Direction:                         descpb.TypeDescriptor_EnumMember_REMOVE,
+						ReplacementPhysicalRepresentation: []byte{3},
+					},
+					{
+						LogicalRepresentation:  "b",
+						PhysicalRepresentation: []byte{3},
+					},
+				},
+				Privileges: defaultPrivileges,
+			},
+		},
 		{
 			`duplicate enum member "us-east-1"`,
 			descpb.TypeDescriptor{
@@ -543,6 +580,93 @@ func TestValidateTypeDesc(t *testing.T) {
 				Privileges: defaultPrivileges,
 			},
 		},
+		{
+			`enum member "a" has unknown replacement [3]`,
+			descpb.TypeDescriptor{
+				Name:           "t",
+				ID:             typeDescID,
+				ParentID:       dbID,
+				ParentSchemaID: keys.PublicSchemaID,
+				Kind:           descpb.TypeDescriptor_ENUM,
+				EnumMembers: []descpb.TypeDescriptor_EnumMember{
+					{
+						LogicalRepresentation:             "a",
+						PhysicalRepresentation:            []byte{1},
+						Capability:                        descpb.TypeDescriptor_EnumMember_READ_ONLY,
+						Direction:                         descpb.TypeDescriptor_EnumMember_REMOVE,
+						ReplacementPhysicalRepresentation: []byte{3},
+					},
+					{
+						LogicalRepresentation:  "b",
+						PhysicalRepresentation: []byte{2},
+					},
+				},
+				Privileges: defaultPrivileges,
+			},
+		},
+		{
+			`enum member "b" has a replacement but is not being removed`,
+			descpb.TypeDescriptor{
+				Name:           "t",
+				ID:             typeDescID,
+				ParentID:       dbID,
+				ParentSchemaID: keys.PublicSchemaID,
+				Kind:           descpb.TypeDescriptor_ENUM,
+				EnumMembers: []descpb.TypeDescriptor_EnumMember{
+					{
+						LogicalRepresentation:  "a",
+						PhysicalRepresentation: []byte{1},
+					},
+					{
+						LogicalRepresentation:             "b",
+						PhysicalRepresentation:            []byte{2},
+						ReplacementPhysicalRepresentation: []byte{1},
+					},
+				},
+				Privileges: defaultPrivileges,
+			},
+		},
+		{
+			`enum member "a" is staged but is not being added`,
+			descpb.TypeDescriptor{
+				Name:           "t",
+				ID:             typeDescID,
+				ParentID:       dbID,
+				ParentSchemaID: keys.PublicSchemaID,
+				Kind:           descpb.TypeDescriptor_ENUM,
+				EnumMembers: []descpb.TypeDescriptor_EnumMember{
+					{
+						LogicalRepresentation:  "a",
+						PhysicalRepresentation: []byte{1},
+						Staged:                 true,
+					},
+				},
+				Privileges: defaultPrivileges,
+			},
+		},
+		{
+			`enum members "a" and "b" have the same code 42`,
+			descpb.TypeDescriptor{
+				Name:           "t",
+				ID:             typeDescID,
+				ParentID:       dbID,
+				ParentSchemaID: keys.PublicSchemaID,
+				Kind:           descpb.TypeDescriptor_ENUM,
+				EnumMembers: []descpb.TypeDescriptor_EnumMember{
+					{
+						LogicalRepresentation:  "a",
+						PhysicalRepresentation: []byte{1},
+						Code:                   &enumCode,
+					},
+					{
+						LogicalRepresentation:  "b",
+						PhysicalRepresentation: []byte{2},
+						Code:                   &enumCode,
+					},
+				},
+				Privileges: defaultPrivileges,
+			},
+		},
 		{
 			`read only capability member must have transition direction set`,
 			descpb.TypeDescriptor{
@@ -936,3 +1060,355 @@ func TestTableImplicitTypeDescCannotBeSerializedOrValidated(t *testing.T) {
 	err := validate.Self(clusterversion.TestingClusterVersion, desc)
 	require.Contains(t, err.Error(), "kind TABLE_IMPLICIT_RECORD_TYPE should never be serialized")
 }
+
+func TestRemoveEnumValue(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	desc := typedesc.NewBuilder(&descpb.TypeDescriptor{
+		Name: "t",
+		Kind: descpb.TypeDescriptor_ENUM,
+		EnumMembers: []descpb.TypeDescriptor_EnumMember{
+			{LogicalRepresentation: "a", PhysicalRepresentation: []byte{1}},
+			{LogicalRepresentation: "b", PhysicalRepresentation: []byte{2}},
+		},
+	}).BuildCreatedMutableType()
+
+	require.NoError(t, desc.RemoveEnumValue("a"))
+	require.Equal(t, descpb.TypeDescriptor_EnumMember_READ_ONLY, desc.EnumMembers[0].Capability)
+	require.Equal(t, descpb.TypeDescriptor_EnumMember_REMOVE, desc.EnumMembers[0].Direction)
+
+	// The value that is being removed no longer counts, so the enum would be
+	// left empty.
+	require.Regexp(t, `cannot drop enum value "b": it is the last value of type "t"`, desc.RemoveEnumValue("b"))
+	require.Equal(t, descpb.TypeDescriptor_EnumMember_ALL, desc.EnumMembers[1].Capability)
+	require.Equal(t, descpb.TypeDescriptor_EnumMember_NONE, desc.EnumMembers[1].Direction)
+
+	require.Regexp(t, `enum value "c" does not exist`, desc.RemoveEnumValue("c"))
+}
+
+func TestAddEnumValue(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	desc := typedesc.NewBuilder(&descpb.TypeDescriptor{
+		Name: "t",
+		Kind: descpb.TypeDescriptor_ENUM,
+		EnumMembers: []descpb.TypeDescriptor_EnumMember{
+			{LogicalRepresentation: "a", PhysicalRepresentation: []byte{1}},
+			{
+				LogicalRepresentation:  "c",
+				PhysicalRepresentation: []byte{3},
+				Capability:             descpb.TypeDescriptor_EnumMember_READ_ONLY,
+				Direction:              descpb.TypeDescriptor_EnumMember_REMOVE,
+			},
+		},
+	}).BuildCreatedMutableType()
+
+	require.NoError(t, desc.AddEnumValue(&tree.AlterTypeAddValue{NewVal: "b"}, 0 /* maxLabelLength */))
+	require.Len(t, desc.EnumMembers, 3)
+	require.Equal(t, "b", desc.EnumMembers[2].LogicalRepresentation)
+	require.Equal(t, descpb.TypeDescriptor_EnumMember_ADD, desc.EnumMembers[2].Direction)
+
+	// Existing values, including ones that are being removed, are reported
+	// distinctly from other failures.
+	for _, val := range []tree.EnumValue{"a", "b", "c"} {
+		err := desc.AddEnumValue(&tree.AlterTypeAddValue{NewVal: val}, 0 /* maxLabelLength */)
+		require.True(t, errors.Is(err, typedesc.ErrEnumValueExists), err)
+		require.Equal(t, pgcode.DuplicateObject, pgerror.GetPGCode(err))
+	}
+	err := desc.AddEnumValue(&tree.AlterTypeAddValue{
+		NewVal:    "d",
+		Placement: &tree.AlterTypeAddValuePlacement{ExistingVal: "e"},
+	}, 0 /* maxLabelLength */)
+	require.Regexp(t, `"e" is not an existing enum value`, err)
+	require.False(t, errors.Is(err, typedesc.ErrEnumValueExists))
+	require.Len(t, desc.EnumMembers, 3)
+
+	// Labels are limited in length, and can't be empty.
+	err = desc.AddEnumValue(&tree.AlterTypeAddValue{NewVal: "eeee"}, 3 /* maxLabelLength */)
+	require.Equal(t, pgcode.NameTooLong, pgerror.GetPGCode(err))
+	err = desc.AddEnumValue(&tree.AlterTypeAddValue{NewVal: ""}, 3 /* maxLabelLength */)
+	require.Equal(t, pgcode.InvalidName, pgerror.GetPGCode(err))
+	require.Len(t, desc.EnumMembers, 3)
+	require.NoError(t, desc.AddEnumValue(&tree.AlterTypeAddValue{NewVal: "eee"}, 3 /* maxLabelLength */))
+	require.Len(t, desc.EnumMembers, 4)
+}
+
+func TestMarkEnumMemberUsableInTxn(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	desc := typedesc.NewBuilder(&descpb.TypeDescriptor{
+		Name:    "t",
+		Version: 1,
+		Kind:    descpb.TypeDescriptor_ENUM,
+		EnumMembers: []descpb.TypeDescriptor_EnumMember{
+			{LogicalRepresentation: "a", PhysicalRepresentation: []byte{1}},
+		},
+	}).BuildExistingMutableType()
+	require.NoError(t, desc.AddEnumValue(&tree.AlterTypeAddValue{NewVal: "b"}, 0 /* maxLabelLength */))
+	require.NoError(t, desc.AddEnumValue(&tree.AlterTypeAddValue{NewVal: "c"}, 0 /* maxLabelLength */))
+	desc.MarkEnumMemberUsableInTxn(desc.EnumMembers[1].PhysicalRepresentation)
+
+	readOnly := func(d catalog.TypeDescriptor) []bool {
+		e := d.AsEnumTypeDescriptor()
+		res := make([]bool, e.NumEnumMembers())
+		for i := range res {
+			res[i] = e.IsMemberReadOnly(i)
+		}
+		return res
+	}
+
+	// The member only becomes usable in the uncommitted version of the
+	// descriptor.
+	require.Equal(t, []bool{false, true, true}, readOnly(desc.ImmutableCopy().(catalog.TypeDescriptor)))
+	desc.MaybeIncrementVersion()
+	desc, err := typedesc.UpdateCachedFieldsOnModifiedMutable(desc)
+	require.NoError(t, err)
+	require.Equal(t, []bool{false, false, true}, readOnly(desc))
+	require.Equal(t, []bool{false, false, true}, readOnly(desc.ImmutableCopy().(catalog.TypeDescriptor)))
+
+	// The member remains read-only in the descriptor that is persisted.
+	require.Equal(t, descpb.TypeDescriptor_EnumMember_READ_ONLY, desc.EnumMembers[1].Capability)
+	persisted := typedesc.NewBuilder(desc.TypeDesc()).BuildImmutableType()
+	require.Equal(t, []bool{false, true, true}, readOnly(persisted))
+}
+
+func TestAddEnumValuePlacement(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	newDesc := func(reps ...[]byte) *typedesc.Mutable {
+		desc := &descpb.TypeDescriptor{Name: "t", Kind: descpb.TypeDescriptor_ENUM}
+		for i, rep := range reps {
+			desc.EnumMembers = append(desc.EnumMembers, descpb.TypeDescriptor_EnumMember{
+				LogicalRepresentation:  fmt.Sprintf("v%d", i),
+				PhysicalRepresentation: rep,
+			})
+		}
+		return typedesc.NewBuilder(desc).BuildCreatedMutableType()
+	}
+
+	for _, tc := range []struct {
+		name      string
+		reps      [][]byte
+		placement tree.AlterTypeAddValuePlacement
+		idx       int
+		rep       []byte
+		packed    bool
+	}{
+		{
+			name:      "between first two",
+			reps:      [][]byte{{0x40}, {0x80}, {0xc0}},
+			placement: tree.AlterTypeAddValuePlacement{Before: true, ExistingVal: "v1"},
+			idx:       1,
+			rep:       []byte{0x60},
+		},
+		{
+			name:      "between last two",
+			reps:      [][]byte{{0x40}, {0x80}, {0xc0}},
+			placement: tree.AlterTypeAddValuePlacement{ExistingVal: "v1"},
+			idx:       2,
+			rep:       []byte{0xa0},
+		},
+		{
+			name:      "fully packed",
+			reps:      [][]byte{{0x40}, {0x41}, {0x42}},
+			placement: tree.AlterTypeAddValuePlacement{Before: true, ExistingVal: "v2"},
+			idx:       2,
+			rep:       []byte{0x41, 0x80},
+			packed:    true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			desc := newDesc(tc.reps...)
+			placement := tc.placement
+			require.NoError(t, desc.AddEnumValue(
+				&tree.AlterTypeAddValue{NewVal: "new", Placement: &placement}, 0, /* maxLabelLength */
+			))
+			require.Len(t, desc.EnumMembers, len(tc.reps)+1)
+			member := desc.EnumMembers[tc.idx]
+			require.Equal(t, "new", member.LogicalRepresentation)
+			require.Equal(t, tc.rep, member.PhysicalRepresentation)
+			for i := 1; i < len(desc.EnumMembers); i++ {
+				require.Less(t, string(desc.EnumMembers[i-1].PhysicalRepresentation),
+					string(desc.EnumMembers[i].PhysicalRepresentation))
+			}
+			require.Equal(t, tc.packed, typedesc.IsEnumMemberPacked(desc.EnumMembers, tc.idx))
+		})
+	}
+}
+
+func TestRespaceEnumValues(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	desc := typedesc.NewBuilder(&descpb.TypeDescriptor{
+		Name: "t",
+		Kind: descpb.TypeDescriptor_ENUM,
+		EnumMembers: []descpb.TypeDescriptor_EnumMember{
+			{LogicalRepresentation: "a", PhysicalRepresentation: []byte{0x40}},
+			{LogicalRepresentation: "b", PhysicalRepresentation: []byte{0x7f}},
+			{LogicalRepresentation: "c", PhysicalRepresentation: []byte{0x7f, 0x80}},
+			{LogicalRepresentation: "d", PhysicalRepresentation: []byte{0x80}},
+		},
+	}).BuildCreatedMutableType()
+	require.True(t, typedesc.IsEnumMemberPacked(desc.EnumMembers, 2))
+
+	// Run rounds until all the members are evenly spaced, finishing the moves
+	// of each round like the type schema change job does.
+	for rounds := 0; ; rounds++ {
+		require.Less(t, rounds, len(desc.EnumMembers))
+		respaced, remaining := desc.RespaceEnumValues()
+		if len(respaced) == 0 {
+			require.Zero(t, remaining)
+			break
+		}
+		members := desc.EnumMembers[:0]
+		for _, member := range desc.EnumMembers {
+			if member.Direction == descpb.TypeDescriptor_EnumMember_REMOVE {
+				continue
+			}
+			member.Capability = descpb.TypeDescriptor_EnumMember_ALL
+			member.Direction = descpb.TypeDescriptor_EnumMember_NONE
+			members = append(members, member)
+		}
+		desc.EnumMembers = members
+		for i := 1; i < len(desc.EnumMembers); i++ {
+			require.Less(t, string(desc.EnumMembers[i-1].PhysicalRepresentation),
+				string(desc.EnumMembers[i].PhysicalRepresentation))
+		}
+	}
+	expected := enum.GenerateNEvenlySpacedBytes(len(desc.EnumMembers))
+	for i, member := range desc.EnumMembers {
+		require.Equal(t, string(rune('a'+i)), member.LogicalRepresentation)
+		require.Equal(t, expected[i], member.PhysicalRepresentation)
+		require.False(t, typedesc.IsEnumMemberPacked(desc.EnumMembers, i))
+	}
+}
+
+func TestMergeDuplicateEnumValues(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	desc := typedesc.NewBuilder(&descpb.TypeDescriptor{
+		Name: "t",
+		Kind: descpb.TypeDescriptor_ENUM,
+		EnumMembers: []descpb.TypeDescriptor_EnumMember{
+			{LogicalRepresentation: "a", PhysicalRepresentation: []byte{1}},
+			{LogicalRepresentation: "b", PhysicalRepresentation: []byte{2}},
+			{LogicalRepresentation: "a", PhysicalRepresentation: []byte{3}},
+			{LogicalRepresentation: "c", PhysicalRepresentation: []byte{4}},
+			{LogicalRepresentation: "a", PhysicalRepresentation: []byte{5}},
+		},
+	}).BuildCreatedMutableType()
+
+	require.Equal(t, []string{"a"}, desc.MergeDuplicateEnumValues())
+	for i, member := range desc.EnumMembers {
+		isDuplicate := i == 2 || i == 4
+		require.Equal(t, isDuplicate, typedesc.IsEnumMemberMerge(desc.EnumMembers, &member), member)
+		if isDuplicate {
+			require.Equal(t, descpb.TypeDescriptor_EnumMember_READ_ONLY, member.Capability)
+			require.Equal(t, descpb.TypeDescriptor_EnumMember_REMOVE, member.Direction)
+			require.Equal(t, []byte{1}, member.ReplacementPhysicalRepresentation)
+		} else {
+			require.Equal(t, descpb.TypeDescriptor_EnumMember_ALL, member.Capability)
+			require.Nil(t, member.ReplacementPhysicalRepresentation)
+		}
+	}
+}
+
+func TestCompactEnumValues(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	desc := typedesc.NewBuilder(&descpb.TypeDescriptor{
+		Name: "t",
+		Kind: descpb.TypeDescriptor_ENUM,
+		EnumMembers: []descpb.TypeDescriptor_EnumMember{
+			{LogicalRepresentation: "a", PhysicalRepresentation: []byte{0x40}},
+			{LogicalRepresentation: "b", PhysicalRepresentation: []byte{0x7f, 0xf0}},
+			{LogicalRepresentation: "c", PhysicalRepresentation: []byte{0x80}},
+			{LogicalRepresentation: "d", PhysicalRepresentation: []byte{0x80, 0x20}},
+		},
+	}).BuildCreatedMutableType()
+
+	require.Equal(t, []string{"b", "d"}, desc.CompactEnumValues())
+	type expectedMember struct {
+		label    string
+		rep      []byte
+		isCopy   bool
+		isMerged bool
+	}
+	expected := []expectedMember{
+		{label: "a", rep: []byte{0x40}},
+		{label: "b", rep: []byte{0x60}, isCopy: true},
+		{label: "b", rep: []byte{0x7f, 0xf0}, isMerged: true},
+		{label: "c", rep: []byte{0x80}},
+		{label: "d", rep: []byte{0x80, 0x20}, isMerged: true},
+		{label: "d", rep: []byte{0xc0}, isCopy: true},
+	}
+	require.Len(t, desc.EnumMembers, len(expected))
+	for i, member := range desc.EnumMembers {
+		exp := expected[i]
+		require.Equal(t, exp.label, member.LogicalRepresentation, member)
+		require.Equal(t, exp.rep, member.PhysicalRepresentation, member)
+		require.Equal(t, exp.isCopy, typedesc.IsEnumMemberCompactionCopy(desc.EnumMembers, &member), member)
+		require.Equal(t, exp.isMerged, typedesc.IsEnumMemberMerge(desc.EnumMembers, &member), member)
+	}
+	require.True(t, sort.IsSorted(typedesc.EnumMembers(desc.EnumMembers)))
+
+	// The merged members keep their representations, so there is nothing
+	// more to compact.
+	require.Empty(t, desc.CompactEnumValues())
+}
+
+func TestNormalizeEnumValues(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	desc := typedesc.NewBuilder(&descpb.TypeDescriptor{
+		Name: "t",
+		Kind: descpb.TypeDescriptor_ENUM,
+		EnumMembers: []descpb.TypeDescriptor_EnumMember{
+			{LogicalRepresentation: "a", PhysicalRepresentation: []byte{0x40}},
+			{LogicalRepresentation: "b", PhysicalRepresentation: []byte{0x80}},
+			{LogicalRepresentation: "c", PhysicalRepresentation: []byte{0xc0}},
+		},
+	}).BuildCreatedMutableType()
+
+	// finish mimics the type schema change job, which removes the moved
+	// members once their rows have been rewritten, and makes the copies
+	// public.
+	finish := func() {
+		members := desc.EnumMembers[:0]
+		for _, member := range desc.EnumMembers {
+			if member.Direction == descpb.TypeDescriptor_EnumMember_REMOVE {
+				continue
+			}
+			member.Capability = descpb.TypeDescriptor_EnumMember_ALL
+			member.Direction = descpb.TypeDescriptor_EnumMember_NONE
+			members = append(members, member)
+		}
+		desc.EnumMembers = members
+	}
+
+	// The representation of "a" must move past that of "b", so "b" and "c"
+	// move first.
+	normalized, remaining := desc.NormalizeEnumValues()
+	require.Equal(t, []string{"b", "c"}, normalized)
+	require.Equal(t, 1, remaining)
+	for _, member := range desc.EnumMembers {
+		require.Equal(t, member.Direction != descpb.TypeDescriptor_EnumMember_NONE,
+			member.LogicalRepresentation != "a", member)
+	}
+	require.True(t, sort.IsSorted(typedesc.EnumMembers(desc.EnumMembers)))
+	finish()
+
+	normalized, remaining = desc.NormalizeEnumValues()
+	require.Equal(t, []string{"a"}, normalized)
+	require.Equal(t, 0, remaining)
+	require.True(t, sort.IsSorted(typedesc.EnumMembers(desc.EnumMembers)))
+	finish()
+
+	require.Len(t, desc.EnumMembers, 3)
+	for i, member := range desc.EnumMembers {
+		require.Equal(t, enum.IntegerRepresentation(i), member.PhysicalRepresentation, member)
+	}
+	normalized, remaining = desc.NormalizeEnumValues()
+	require.Empty(t, normalized)
+	require.Equal(t, 0, remaining)
+}
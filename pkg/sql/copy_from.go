@@ -365,7 +365,18 @@ func newCopyMachine(
 	// we still have all the encoder allocations to make.
 	c.maxRowMem = kvserverbase.MaxCommandSize.Get(c.p.execCfg.SV()) / 3
 
-	if c.canSupportVectorized(tableDesc) {
+	// The vectorized insert doesn't enforce the restrictions on the enum
+	// values that the user can write, set via ALTER TYPE ... RESTRICT VALUES,
+	// nor the constraints of types, added via ALTER TYPE ... ADD CONSTRAINT.
+	enumRestrictions, err := c.p.makeEnumWriteRestrictions(ctx, cols)
+	if err != nil {
+		return nil, err
+	}
+	typeConstraints, err := c.p.makeTypeConstraintChecker(ctx, cols)
+	if err != nil {
+		return nil, err
+	}
+	if enumRestrictions == nil && typeConstraints == nil && c.canSupportVectorized(tableDesc) {
 		if err := c.initVectorizedCopy(ctx, typs); err != nil {
 			return nil, err
 		}
@@ -188,7 +188,11 @@ func findFreeArrayTypeName(
 ) (string, error) {
 	arrayName := "_" + name
 	for {
-		// See if there is a collision with the current name.
+		// See if there is a collision with the current name. Names are freed
+		// by the transaction that renames or drops their object, rather than
+		// drained after it commits, and the names that the transaction freed
+		// are shadowed in the collection, so only the objects that currently
+		// use the name collide with it.
 		objectID, err := col.LookupObjectID(ctx, txn, parentID, schemaID, arrayName)
 		if err != nil {
 			return "", err
@@ -311,6 +315,9 @@ func (p *planner) createUserDefinedType(params runParams, n *createTypeNode) err
 	}
 	switch n.n.Variety {
 	case tree.Enum:
+		if n.n.EnumSpec != "" {
+			return params.p.createEnumFromSpecWithID(params, id, n.n.EnumSpec, n.dbDesc, n.typeName)
+		}
 		return params.p.createEnumWithID(
 			params, id, n.n.EnumLabels, n.dbDesc, n.typeName, EnumTypeUserDefined,
 		)
@@ -419,22 +426,10 @@ func CreateCompositeTypeDesc(
 				"composite type definition contains duplicate label %q", value)
 		}
 		elts[i].ElementLabel = string(value.Label)
-		typ, err := tree.ResolveType(params.ctx, value.Type, params.p.semaCtx.TypeResolver)
+		typ, err := params.p.resolveCompositeElementType(params.ctx, value.Type)
 		if err != nil {
 			return nil, err
 		}
-		err = tree.CheckUnsupportedType(params.ctx, &params.p.semaCtx, typ)
-		if err != nil {
-			return nil, err
-		}
-		if typ.UserDefined() {
-			return nil, unimplemented.NewWithIssue(91779,
-				"composite types that reference user-defined types not yet supported")
-		}
-		if typ.TypeMeta.ImplicitRecordType {
-			return nil, unimplemented.NewWithIssue(70099,
-				"cannot use table record type as part of composite type")
-		}
 		elts[i].ElementType = typ
 		seenLabels[value.Label] = struct{}{}
 	}
@@ -464,6 +459,29 @@ func CreateCompositeTypeDesc(
 	}).BuildCreatedMutableType(), nil
 }
 
+// resolveCompositeElementType resolves the type of an element of a composite
+// type, and returns an error if it isn't supported in composite types.
+func (p *planner) resolveCompositeElementType(
+	ctx context.Context, ref tree.ResolvableTypeReference,
+) (*types.T, error) {
+	typ, err := tree.ResolveType(ctx, ref, p.semaCtx.TypeResolver)
+	if err != nil {
+		return nil, err
+	}
+	if err := tree.CheckUnsupportedType(ctx, &p.semaCtx, typ); err != nil {
+		return nil, err
+	}
+	if typ.UserDefined() {
+		return nil, unimplemented.NewWithIssue(91779,
+			"composite types that reference user-defined types not yet supported")
+	}
+	if typ.TypeMeta.ImplicitRecordType {
+		return nil, unimplemented.NewWithIssue(70099,
+			"cannot use table record type as part of composite type")
+	}
+	return typ, nil
+}
+
 func (p *planner) createEnumWithID(
 	params runParams,
 	id descpb.ID,
@@ -488,6 +506,45 @@ func (p *planner) createEnumWithID(
 	return p.finishCreateType(params, id, typeName, typeDesc, dbDesc, schema)
 }
 
+// createEnumFromSpecWithID creates an enum type from a spec produced by
+// crdb_internal.export_type. The members of the new type keep the physical
+// representations recorded in the spec, so values encoded with the original
+// type decode identically with the new one.
+func (p *planner) createEnumFromSpecWithID(
+	params runParams,
+	id descpb.ID,
+	encodedSpec string,
+	dbDesc catalog.DatabaseDescriptor,
+	typeName *tree.TypeName,
+) error {
+	spec, physReps, err := enum.ParseSpec(encodedSpec)
+	if err != nil {
+		return err
+	}
+	sqltelemetry.IncrementEnumCounter(sqltelemetry.EnumCreate)
+
+	schema, err := getCreateTypeParams(params, typeName, dbDesc)
+	if err != nil {
+		return err
+	}
+
+	enumLabels := make(tree.EnumValueList, len(spec.Members))
+	for i := range spec.Members {
+		enumLabels[i] = tree.EnumValue(spec.Members[i].Label)
+	}
+	typeDesc, err := CreateEnumTypeDesc(
+		params, id, enumLabels, dbDesc, schema, typeName, EnumTypeUserDefined,
+	)
+	if err != nil {
+		return err
+	}
+	for i := range typeDesc.EnumMembers {
+		typeDesc.EnumMembers[i].PhysicalRepresentation = physReps[i]
+	}
+
+	return p.finishCreateType(params, id, typeName, typeDesc, dbDesc, schema)
+}
+
 func (p *planner) createCompositeWithID(
 	params runParams,
 	id descpb.ID,
@@ -10,7 +10,11 @@
 
 package enum
 
-import "bytes"
+import (
+	"bytes"
+
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+)
 
 // Note that while maxToken is outside the range of a single
 // byte, we never actually insert it in GenByteStringBetween.
@@ -101,6 +105,23 @@ func GenByteStringBetween(prev []byte, next []byte, spacing ByteSpacing) []byte
 // value created in a new Enum.
 var One = []byte{byte(midToken)}
 
+// MaxIntegerRepresentations is the number of positions that
+// IntegerRepresentation supports.
+const MaxIntegerRepresentations = 256
+
+// IntegerRepresentation returns the physical representation of the enum value
+// at the given zero-based position when the representations of an enum are
+// normalized to integers. It is the ascending varint encoding of the position,
+// so representations sort by position and can be decoded with
+// encoding.DecodeUvarintAscending. The encodings of larger positions than
+// MaxIntegerRepresentations-1 may end in minToken, so they aren't supported.
+func IntegerRepresentation(pos int) []byte {
+	if pos < 0 || pos >= MaxIntegerRepresentations {
+		panic("enum position out of range")
+	}
+	return encoding.EncodeUvarintAscending(nil, uint64(pos))
+}
+
 // Utility functions for GenByteStringBetween.
 
 func get(arr []byte, idx int, def int) int {
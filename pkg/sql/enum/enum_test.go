@@ -11,8 +11,12 @@
 package enum
 
 import (
+	"bytes"
 	"testing"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/randutil"
 	"github.com/stretchr/testify/require"
@@ -191,3 +195,66 @@ func TestOne(t *testing.T) {
 	require.Equal(t, One, GenByteStringBetween(nil, nil, PackedSpacing))
 	require.Equal(t, One, GenByteStringBetween(nil, nil, SpreadSpacing))
 }
+
+func TestIntegerRepresentation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var prev []byte
+	for pos := 0; pos < MaxIntegerRepresentations; pos++ {
+		rep := IntegerRepresentation(pos)
+		require.NotEqual(t, byte(minToken), rep[len(rep)-1], "position %d", pos)
+		require.Equal(t, -1, bytes.Compare(prev, rep), "position %d", pos)
+		rest, decoded, err := encoding.DecodeUvarintAscending(rep)
+		require.NoError(t, err)
+		require.Empty(t, rest)
+		require.Equal(t, uint64(pos), decoded)
+		prev = rep
+	}
+	require.Panics(t, func() { IntegerRepresentation(MaxIntegerRepresentations) })
+}
+
+func TestSpec(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	t.Run("round trip", func(t *testing.T) {
+		labels := []string{"a", "b", "c"}
+		physReps := GenerateNEvenlySpacedBytes(len(labels))
+		encoded, err := MakeSpec("typ", labels, physReps).Encode()
+		require.NoError(t, err)
+		spec, decoded, err := ParseSpec(encoded)
+		require.NoError(t, err)
+		require.Equal(t, "typ", spec.Name)
+		require.Equal(t, physReps, decoded)
+		for i := range labels {
+			require.Equal(t, labels[i], spec.Members[i].Label)
+		}
+	})
+
+	for _, tc := range []struct {
+		name    string
+		encoded string
+		code    pgcode.Code
+	}{
+		{"malformed", `{"members": [`, pgcode.InvalidParameterValue},
+		{"unknown field", `{"members": [], "extra": 1}`, pgcode.InvalidParameterValue},
+		{
+			"duplicate label",
+			`{"members": [{"label": "a", "physical_rep": "40"}, {"label": "a", "physical_rep": "80"}]}`,
+			pgcode.InvalidObjectDefinition,
+		},
+		{"bad hex", `{"members": [{"label": "a", "physical_rep": "zz"}]}`, pgcode.InvalidParameterValue},
+		{"empty rep", `{"members": [{"label": "a", "physical_rep": ""}]}`, pgcode.InvalidParameterValue},
+		{"trailing min token", `{"members": [{"label": "a", "physical_rep": "4000"}]}`, pgcode.InvalidParameterValue},
+		{
+			"unsorted",
+			`{"members": [{"label": "a", "physical_rep": "80"}, {"label": "b", "physical_rep": "40"}]}`,
+			pgcode.InvalidParameterValue,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := ParseSpec(tc.encoded)
+			require.Error(t, err)
+			require.Equal(t, tc.code, pgerror.GetPGCode(err))
+		})
+	}
+}
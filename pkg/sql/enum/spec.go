@@ -0,0 +1,106 @@
+// Copyright 2024 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package enum
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// Spec is a portable description of an enum type. It records the logical and
+// physical representation of every member in physical order, so that the type
+// can be recreated elsewhere with byte-for-byte identical encodings.
+type Spec struct {
+	// Name is the name of the type when it was exported. It is informational
+	// only: the name of an imported type comes from the CREATE TYPE statement.
+	Name    string       `json:"name"`
+	Members []SpecMember `json:"members"`
+}
+
+// SpecMember describes a single member of an enum in a Spec.
+type SpecMember struct {
+	Label string `json:"label"`
+	// PhysicalRep is the hex encoding of the member's physical representation.
+	PhysicalRep string `json:"physical_rep"`
+}
+
+// MakeSpec constructs a Spec from parallel slices of logical and physical
+// representations, which must already be sorted by physical representation.
+func MakeSpec(name string, logicalReps []string, physicalReps [][]byte) Spec {
+	spec := Spec{Name: name, Members: make([]SpecMember, len(logicalReps))}
+	for i := range logicalReps {
+		spec.Members[i] = SpecMember{
+			Label:       logicalReps[i],
+			PhysicalRep: hex.EncodeToString(physicalReps[i]),
+		}
+	}
+	return spec
+}
+
+// Encode returns the JSON encoding of the spec.
+func (s Spec) Encode() (string, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// ParseSpec decodes a JSON encoded Spec and validates it. It returns the spec
+// along with the decoded physical representation of each member.
+func ParseSpec(encoded string) (Spec, [][]byte, error) {
+	var s Spec
+	dec := json.NewDecoder(bytes.NewReader([]byte(encoded)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&s); err != nil {
+		return Spec{}, nil, pgerror.Wrap(err, pgcode.InvalidParameterValue, "invalid enum spec")
+	}
+	physicalReps, err := s.validate()
+	if err != nil {
+		return Spec{}, nil, err
+	}
+	return s, physicalReps, nil
+}
+
+// validate checks that the spec describes a well-formed enum: labels must be
+// unique, and physical representations must be non-empty, strictly increasing
+// and must not end in minToken, which GenByteStringBetween relies on to be
+// able to generate values between existing members.
+func (s Spec) validate() ([][]byte, error) {
+	physicalReps := make([][]byte, len(s.Members))
+	seen := make(map[string]struct{}, len(s.Members))
+	for i, m := range s.Members {
+		if _, ok := seen[m.Label]; ok {
+			return nil, pgerror.Newf(pgcode.InvalidObjectDefinition,
+				"enum spec contains duplicate value %q", m.Label)
+		}
+		seen[m.Label] = struct{}{}
+		rep, err := hex.DecodeString(m.PhysicalRep)
+		if err != nil {
+			return nil, pgerror.Wrapf(err, pgcode.InvalidParameterValue,
+				"invalid physical representation for enum value %q", m.Label)
+		}
+		if len(rep) == 0 || rep[len(rep)-1] == byte(minToken) {
+			return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+				"invalid physical representation %q for enum value %q", m.PhysicalRep, m.Label)
+		}
+		if i > 0 && bytes.Compare(physicalReps[i-1], rep) >= 0 {
+			return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+				"enum spec members are not sorted by physical representation at value %q", m.Label)
+		}
+		physicalReps[i] = rep
+	}
+	return physicalReps, nil
+}
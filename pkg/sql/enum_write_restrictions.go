@@ -0,0 +1,185 @@
+// Copyright 2024 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// enumWriteRestrictions enforces the restrictions set via ALTER TYPE ...
+// RESTRICT VALUES on the values that the current user can write to the enum
+// columns of a mutation. A nil *enumWriteRestrictions doesn't restrict
+// anything, which is the common case.
+type enumWriteRestrictions struct {
+	user username.SQLUsername
+	cols []restrictedEnumColumn
+}
+
+// restrictedEnumColumn is a column whose values are restricted for the user.
+type restrictedEnumColumn struct {
+	// ord is the ordinal of the column in the rows being written.
+	ord int
+	// typeName is the name of the enum type, used in errors.
+	typeName string
+	// allowed is the set of physical representations that the user can write.
+	allowed map[string]struct{}
+}
+
+// makeEnumWriteRestrictions returns the restrictions on the values that the
+// current user can write to the given columns, or nil if none of the columns
+// are restricted. The values of arrays of an enum are restricted like values
+// of the enum itself. A restriction applies to the role it is set for and to
+// all the members of that role. If several apply, only the values allowed by
+// all of them can be written.
+func (p *planner) makeEnumWriteRestrictions(
+	ctx context.Context, cols []catalog.Column,
+) (*enumWriteRestrictions, error) {
+	var r *enumWriteRestrictions
+	// The roles of the user are only looked up once a type with restrictions
+	// is found.
+	var memberOf map[username.SQLUsername]bool
+	var memberOfLoaded bool
+	for i, col := range cols {
+		typeDesc, _, err := p.enumColumnType(ctx, col)
+		if err != nil {
+			return nil, err
+		}
+		if typeDesc == nil {
+			continue
+		}
+		restrictions := typeDesc.TypeDesc().RoleValueRestrictions
+		if len(restrictions) == 0 {
+			continue
+		}
+		if !memberOfLoaded {
+			if memberOf, err = p.MemberOfWithAdminOption(ctx, p.User()); err != nil {
+				return nil, err
+			}
+			memberOfLoaded = true
+		}
+		var allowed map[string]struct{}
+		for _, restriction := range restrictions {
+			role := restriction.RoleProto.Decode()
+			if _, ok := memberOf[role]; !ok && role != p.User() {
+				continue
+			}
+			next := make(map[string]struct{}, len(restriction.AllowedValues))
+			for _, val := range restriction.AllowedValues {
+				if _, ok := allowed[string(val)]; allowed == nil || ok {
+					next[string(val)] = struct{}{}
+				}
+			}
+			allowed = next
+		}
+		if allowed == nil {
+			continue
+		}
+		// A member that is being moved to a new physical representation, e.g. by
+		// ALTER TYPE ... COMPACT, is written with the representation of its copy
+		// until the type schema change job removes it and updates the
+		// restrictions.
+		members := typeDesc.TypeDesc().EnumMembers
+		for j := range members {
+			if _, ok := allowed[string(members[j].PhysicalRepresentation)]; ok &&
+				typedesc.IsEnumMemberMerge(members, &members[j]) {
+				allowed[string(members[j].ReplacementPhysicalRepresentation)] = struct{}{}
+			}
+		}
+		if r == nil {
+			r = &enumWriteRestrictions{user: p.User()}
+		}
+		r.cols = append(r.cols, restrictedEnumColumn{
+			ord:      i,
+			typeName: typeDesc.GetName(),
+			allowed:  allowed,
+		})
+	}
+	return r, nil
+}
+
+// check returns an error if the given row, whose values are ordered like the
+// columns that the restrictions were made for, contains a value that the user
+// isn't allowed to write. NULLs are always allowed.
+func (r *enumWriteRestrictions) check(row tree.Datums) error {
+	if r == nil {
+		return nil
+	}
+	for i := range r.cols {
+		col := &r.cols[i]
+		if err := visitEnumValues(row[col.ord], func(d *tree.DEnum) error {
+			return r.checkValue(col, d)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *enumWriteRestrictions) checkValue(col *restrictedEnumColumn, d *tree.DEnum) error {
+	if _, ok := col.allowed[string(d.PhysicalRep)]; ok {
+		return nil
+	}
+	return errors.WithHint(
+		pgerror.Newf(pgcode.InsufficientPrivilege,
+			"role %s is not allowed to write value %q of type %s", r.user, d.LogicalRep, col.typeName),
+		"the values that roles can write are restricted with ALTER TYPE ... RESTRICT VALUES",
+	)
+}
+
+// enumColumnType returns the descriptor and the type of the enum that the
+// values of the given column are of, either because the column is of the enum
+// or of its array type. It returns nil for other columns.
+func (p *planner) enumColumnType(
+	ctx context.Context, col catalog.Column,
+) (catalog.TypeDescriptor, *types.T, error) {
+	typ := col.GetType()
+	if typ.Family() == types.ArrayFamily {
+		typ = typ.ArrayContents()
+	}
+	if typ.Family() != types.EnumFamily {
+		return nil, nil, nil
+	}
+	typeDesc, err := p.Descriptors().ByIDWithLeased(p.txn).WithoutNonPublic().Get().Type(
+		ctx, typedesc.GetUserDefinedTypeDescID(typ),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return typeDesc, typ, nil
+}
+
+// visitEnumValues calls f with the enum values in a datum written to a column
+// of an enum or of its array type: the datum itself, or each of the elements
+// of the array. NULLs are skipped.
+func visitEnumValues(d tree.Datum, f func(*tree.DEnum) error) error {
+	switch d := d.(type) {
+	case *tree.DEnum:
+		return f(d)
+	case *tree.DArray:
+		for _, elem := range d.Array {
+			if e, ok := elem.(*tree.DEnum); ok {
+				if err := f(e); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
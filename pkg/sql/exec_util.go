@@ -3725,6 +3725,10 @@ func (m *sessionDataMutator) SetCloseCursorsAtCommit(val bool) {
 	m.data.CloseCursorsAtCommit = val
 }
 
+func (m *sessionDataMutator) SetAddEnumValueMaxPendingJobs(val int64) {
+	m.data.AddEnumValueMaxPendingJobs = val
+}
+
 // Utility functions related to scrubbing sensitive information on SQL Stats.
 
 // quantizeCounts ensures that the Count field in the
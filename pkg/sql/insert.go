@@ -92,6 +92,14 @@ type insertRun struct {
 	// regionLocalInfo handles erroring out the INSERT when the
 	// enforce_home_region setting is on.
 	regionLocalInfo regionLocalInfoType
+
+	// enumRestrictions restricts the enum values that the user can insert, as
+	// set via ALTER TYPE ... RESTRICT VALUES.
+	enumRestrictions *enumWriteRestrictions
+
+	// typeConstraints checks the inserted values against the constraints of
+	// their types, added via ALTER TYPE ... ADD CONSTRAINT.
+	typeConstraints *typeConstraintChecker
 }
 
 // regionLocalInfoType contains common items needed for determining the home region
@@ -199,6 +207,12 @@ func (r *insertRun) processSourceRow(params runParams, rowVals tree.Datums) erro
 	if err := enforceLocalColumnConstraints(rowVals, r.insertCols); err != nil {
 		return err
 	}
+	if err := r.enumRestrictions.check(rowVals); err != nil {
+		return err
+	}
+	if err := r.typeConstraints.check(params.ctx, rowVals); err != nil {
+		return err
+	}
 
 	// Create a set of partial index IDs to not write to. Indexes should not be
 	// written to when they are partial indexes and the row does not satisfy the
@@ -267,6 +281,14 @@ func (n *insertNode) startExec(params runParams) error {
 
 	n.run.initRowContainer(params, n.columns)
 
+	var err error
+	if n.run.enumRestrictions, err = params.p.makeEnumWriteRestrictions(params.ctx, n.run.insertCols); err != nil {
+		return err
+	}
+	if n.run.typeConstraints, err = params.p.makeTypeConstraintChecker(params.ctx, n.run.insertCols); err != nil {
+		return err
+	}
+
 	return n.run.ti.init(params.ctx, params.p.txn, params.EvalContext(), &params.EvalContext().Settings.SV)
 }
 
@@ -393,6 +393,14 @@ func (n *insertFastPathNode) startExec(params runParams) error {
 
 	n.run.initRowContainer(params, n.columns)
 
+	var err error
+	if n.run.enumRestrictions, err = params.p.makeEnumWriteRestrictions(params.ctx, n.run.insertCols); err != nil {
+		return err
+	}
+	if n.run.typeConstraints, err = params.p.makeTypeConstraintChecker(params.ctx, n.run.insertCols); err != nil {
+		return err
+	}
+
 	n.run.numInputCols = len(n.input[0])
 	n.run.inputBuf = make(tree.Datums, len(n.input)*n.run.numInputCols)
 
@@ -2179,6 +2179,14 @@ func (ef *execFactory) ConstructExplain(
 			plan:    *wrappedPlan,
 		}, nil
 	}
+	if options.Mode == tree.ExplainPlan {
+		// EXPLAIN ALTER TYPE ... DROP VALUE reports what would block the drop
+		// rather than the plan, which is just the alter type node.
+		wrappedPlan := plan.(*explain.Plan).WrappedPlan.(*planComponents)
+		if n, ok := wrappedPlan.main.planNode.(*alterTypeNode); ok && n.setExplainDropValue() {
+			return n, nil
+		}
+	}
 	flags := explain.MakeFlags(options)
 	if ef.planner.execCfg.TestingKnobs.DeterministicExplain {
 		flags.Deflake = explain.DeflakeVolatile
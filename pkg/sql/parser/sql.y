@@ -784,9 +784,21 @@ func (u *sqlSymUnion) typeReference() tree.ResolvableTypeReference {
 func (u *sqlSymUnion) typeReferences() []tree.ResolvableTypeReference {
     return u.val.([]tree.ResolvableTypeReference)
 }
+func (u *sqlSymUnion) alterTypeAddValue() *tree.AlterTypeAddValue {
+    return u.val.(*tree.AlterTypeAddValue)
+}
+func (u *sqlSymUnion) alterTypeAddValues() []*tree.AlterTypeAddValue {
+    return u.val.([]*tree.AlterTypeAddValue)
+}
+func (u *sqlSymUnion) alterTypeCmd() tree.AlterTypeCmd {
+    return u.val.(tree.AlterTypeCmd)
+}
 func (u *sqlSymUnion) alterTypeAddValuePlacement() *tree.AlterTypeAddValuePlacement {
     return u.val.(*tree.AlterTypeAddValuePlacement)
 }
+func (u *sqlSymUnion) int64Ptr() *int64 {
+    return u.val.(*int64)
+}
 func (u *sqlSymUnion) scheduleState() tree.ScheduleState {
   return u.val.(tree.ScheduleState)
 }
@@ -918,7 +930,7 @@ func (u *sqlSymUnion) showFingerprintOptions() *tree.ShowFingerprintOptions {
 
 %token <str> CACHE CALL CALLED CANCEL CANCELQUERY CAPABILITIES CAPABILITY CASCADE CASE CAST CBRT CHANGEFEED CHAR
 %token <str> CHARACTER CHARACTERISTICS CHECK CHECK_FILES CLOSE
-%token <str> CLUSTER CLUSTERS COALESCE COLLATE COLLATION COLUMN COLUMNS COMMENT COMMENTS COMMIT
+%token <str> CLUSTER CLUSTERS CODE COALESCE COLLATE COLLATION COLUMN COLUMNS COMMENT COMMENTS COMMIT
 %token <str> COMMITTED COMPACT COMPLETE COMPLETIONS CONCAT CONCURRENTLY CONFIGURATION CONFIGURATIONS CONFIGURE
 %token <str> CONFLICT CONNECTION CONNECTIONS CONSTRAINT CONSTRAINTS CONTAINS CONTROLCHANGEFEED CONTROLJOB
 %token <str> CONVERSION CONVERT COPY COST COVERING CREATE CREATEDB CREATELOGIN CREATEROLE
@@ -927,7 +939,7 @@ func (u *sqlSymUnion) showFingerprintOptions() *tree.ShowFingerprintOptions {
 %token <str> CURRENT_USER CURSOR CYCLE
 
 %token <str> DATA DATABASE DATABASES DATE DAY DEBUG_IDS DEBUG_PAUSE_ON DEC DEBUG_DUMP_METADATA_SST DECIMAL DEFAULT DEFAULTS DEFINER
-%token <str> DEALLOCATE DECLARE DEFERRABLE DEFERRED DELETE DELIMITER DEPENDS DESC DESTINATION DETACHED DETAILS
+%token <str> DEALLOCATE DECLARE DEDUP DEFERRABLE DEFERRED DELETE DELIMITER DEPENDS DESC DESTINATION DETACHED DETAILS
 %token <str> DISCARD DISTINCT DO DOMAIN DOUBLE DROP
 
 %token <str> ELSE ENCODING ENCRYPTED ENCRYPTION_INFO_DIR ENCRYPTION_PASSPHRASE END ENUM ENUMS ESCAPE EXCEPT EXCLUDE EXCLUDING
@@ -973,7 +985,7 @@ func (u *sqlSymUnion) showFingerprintOptions() *tree.ShowFingerprintOptions {
 
 %token <str> NAN NAME NAMES NATURAL NEVER NEW_DB_NAME NEW_KMS NEXT NO NOCANCELQUERY NOCONTROLCHANGEFEED
 %token <str> NOCONTROLJOB NOCREATEDB NOCREATELOGIN NOCREATEROLE NOLOGIN NOMODIFYCLUSTERSETTING NOREPLICATION
-%token <str> NOSQLLOGIN NO_INDEX_JOIN NO_ZIGZAG_JOIN NO_FULL_SCAN NONE NONVOTERS NORMAL NOT
+%token <str> NOSQLLOGIN NO_INDEX_JOIN NO_ZIGZAG_JOIN NO_FULL_SCAN NONE NONVOTERS NORMAL NORMALIZE NOT
 %token <str> NOTHING NOTHING_AFTER_RETURNING
 %token <str> NOTNULL
 %token <str> NOVIEWACTIVITY NOVIEWACTIVITYREDACTED NOVIEWCLUSTERSETTING NOWAIT NULL NULLIF NULLS NUMERIC
@@ -984,13 +996,13 @@ func (u *sqlSymUnion) showFingerprintOptions() *tree.ShowFingerprintOptions {
 %token <str> PARALLEL PARENT PARTIAL PARTITION PARTITIONS PASSWORD PAUSE PAUSED PHYSICAL PLACEMENT PLACING
 %token <str> PLAN PLANS POINT POINTM POINTZ POINTZM POLYGON POLYGONM POLYGONZ POLYGONZM
 %token <str> POSITION PRECEDING PRECISION PREPARE PRESERVE PRIMARY PRIOR PRIORITY PRIVILEGES
-%token <str> PROCEDURAL PROCEDURE PROCEDURES PUBLIC PUBLICATION
+%token <str> PROCEDURAL PROCEDURE PROCEDURES PROMOTE PUBLIC PUBLICATION
 
 %token <str> QUERIES QUERY QUOTE
 
 %token <str> RANGE RANGES READ REAL REASON REASSIGN RECURSIVE RECURRING REDACT REF REFERENCES REFRESH
 %token <str> REGCLASS REGION REGIONAL REGIONS REGNAMESPACE REGPROC REGPROCEDURE REGROLE REGTYPE REINDEX
-%token <str> RELATIVE RELOCATE REMOVE_PATH REMOVE_REGIONS RENAME REPEATABLE REPLACE REPLICATION
+%token <str> RELATIVE RELOCATE REMOVE_PATH REMOVE_REGIONS RENAME REPEATABLE REPLACE REPLICATION REPRESENTATION
 %token <str> RELEASE RESET RESTART RESTORE RESTRICT RESTRICTED RESUME RETENTION RETURNING RETURN RETURNS RETRY REVISION_HISTORY
 %token <str> REVOKE RIGHT ROLE ROLES ROLLBACK ROLLUP ROUTINES ROW ROWS RSHIFT RULE RUNNING
 
@@ -999,7 +1011,7 @@ func (u *sqlSymUnion) showFingerprintOptions() *tree.ShowFingerprintOptions {
 %token <str> SERIALIZABLE SERVER SERVICE SESSION SESSIONS SESSION_USER SET SETOF SETS SETTING SETTINGS
 %token <str> SHARE SHARED SHOW SIMILAR SIMPLE SIZE SKIP SKIP_LOCALITIES_CHECK SKIP_MISSING_FOREIGN_KEYS
 %token <str> SKIP_MISSING_SEQUENCES SKIP_MISSING_SEQUENCE_OWNERS SKIP_MISSING_VIEWS SKIP_MISSING_UDFS SMALLINT SMALLSERIAL
-%token <str> SNAPSHOT SOME SPLIT SQL SQLLOGIN
+%token <str> SNAPSHOT SOME SPEC SPLIT SQL SQLLOGIN
 %token <str> STABLE START STATE STATISTICS STATUS STDIN STDOUT STOP STRAIGHT STREAM STRICT STRING STORAGE STORE STORED STORING SUBSTRING SUPER
 %token <str> SUPPORT SURVIVE SURVIVAL SYMMETRIC SYNTAX SYSTEM SQRT SUBSCRIPTION STATEMENTS
 
@@ -1580,7 +1592,14 @@ func (u *sqlSymUnion) showFingerprintOptions() *tree.ShowFingerprintOptions {
 
 %type <tree.ResolvableTypeReference> typename simple_typename cast_target
 %type <*types.T> const_typename
+%type <*tree.AlterTypeAddValue> alter_type_add_value_cmd
+%type <tree.AlterTypeCmd> alter_attribute_action
+%type <[]*tree.AlterTypeAddValue> alter_type_add_value_cmd_list
 %type <*tree.AlterTypeAddValuePlacement> opt_add_val_placement
+%type <tree.RoleSpecList> opt_add_val_usage_grantees
+%type <bool> opt_add_val_staged
+%type <*int64> opt_rename_val_expected_rows
+%type <bool> opt_rename_val_if_exists
 %type <bool> opt_timezone
 %type <*types.T> numeric opt_numeric_modifiers
 %type <*types.T> opt_float
@@ -2994,11 +3013,23 @@ opt_validate_behavior:
 // %Text: ALTER TYPE <typename> <command>
 //
 // Commands:
-//   ALTER TYPE ... ADD VALUE [IF NOT EXISTS] <value> [ { BEFORE | AFTER } <value> ]
-//   ALTER TYPE ... RENAME VALUE <oldname> TO <newname>
+//   ALTER TYPE ... ADD VALUE [IF NOT EXISTS] <value> [ { BEFORE | AFTER } <value> ] [ WITH (staged) ] [, ADD VALUE ...] [ GRANT USAGE TO <role> [, ...] ]
+//   ALTER TYPE ... PROMOTE VALUE <value>
+//   ALTER TYPE ... ALTER VALUE <value> SET CODE <code>
+//   ALTER TYPE ... RESTRICT VALUES FOR <role> TO { ( <value> [, ...] ) | DEFAULT }
+//   ALTER TYPE ... ADD CONSTRAINT <name> CHECK (<expr>)
+//   ALTER TYPE ... DROP CONSTRAINT [IF EXISTS] <name>
+//   ALTER TYPE ... DROP VALUE <value> [ REPLACE WITH <value> ]
+//   ALTER TYPE ... RENAME VALUE <oldname> TO <newname> [ IF EXISTS ] [ REFRESH ] [ WITH (expected_rows = <count>) ]
 //   ALTER TYPE ... RENAME TO <newname>
-//   ALTER TYPE ... SET SCHEMA <newschemaname>
+//   ALTER TYPE ... SET SCHEMA <newschemaname> [ CASCADE ]
+//   ALTER TYPE ... SET OID <oid>
 //   ALTER TYPE ... OWNER TO {<newowner> | CURRENT_USER | SESSION_USER }
+//   ALTER TYPE ... CHECK
+//   ALTER TYPE ... VALIDATE DATA
+//   ALTER TYPE ... DEDUP VALUES
+//   ALTER TYPE ... COMPACT
+//   ALTER TYPE ... NORMALIZE REPRESENTATION
 //   ALTER TYPE ... RENAME ATTRIBUTE <oldname> TO <newname> [ CASCADE | RESTRICT ]
 //   ALTER TYPE ... <attributeaction> [, ... ]
 //
@@ -3009,25 +3040,22 @@ opt_validate_behavior:
 //
 // %SeeAlso: WEBDOCS/alter-type.html
 alter_type_stmt:
-  ALTER TYPE type_name ADD VALUE SCONST opt_add_val_placement
+  ALTER TYPE type_name alter_type_add_value_cmd opt_add_val_usage_grantees
   {
+    cmd := $4.alterTypeAddValue()
+    cmd.UsageGrantees = $5.roleSpecList()
     $$.val = &tree.AlterType{
       Type: $3.unresolvedObjectName(),
-      Cmd: &tree.AlterTypeAddValue{
-        NewVal: tree.EnumValue($6),
-        IfNotExists: false,
-        Placement: $7.alterTypeAddValuePlacement(),
-      },
+      Cmd: cmd,
     }
   }
-| ALTER TYPE type_name ADD VALUE IF NOT EXISTS SCONST opt_add_val_placement
+| ALTER TYPE type_name alter_type_add_value_cmd ',' alter_type_add_value_cmd_list opt_add_val_usage_grantees
   {
     $$.val = &tree.AlterType{
       Type: $3.unresolvedObjectName(),
-      Cmd: &tree.AlterTypeAddValue{
-        NewVal: tree.EnumValue($9),
-        IfNotExists: true,
-        Placement: $10.alterTypeAddValuePlacement(),
+      Cmd: &tree.AlterTypeAddValues{
+        Values: append([]*tree.AlterTypeAddValue{$4.alterTypeAddValue()}, $6.alterTypeAddValues()...),
+        UsageGrantees: $7.roleSpecList(),
       },
     }
   }
@@ -3040,13 +3068,141 @@ alter_type_stmt:
      },
    }
  }
-| ALTER TYPE type_name RENAME VALUE SCONST TO SCONST
+| ALTER TYPE type_name DROP VALUE SCONST REPLACE WITH SCONST
+ {
+   replacement := tree.EnumValue($9)
+   $$.val = &tree.AlterType{
+     Type: $3.unresolvedObjectName(),
+     Cmd: &tree.AlterTypeDropValue{
+       Val: tree.EnumValue($6),
+       Replacement: &replacement,
+     },
+   }
+ }
+| ALTER TYPE type_name CHECK
+  {
+    $$.val = &tree.AlterType{
+      Type: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterTypeCheck{},
+    }
+  }
+| ALTER TYPE type_name VALIDATE DATA
+  {
+    $$.val = &tree.AlterType{
+      Type: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterTypeValidateData{},
+    }
+  }
+| ALTER TYPE type_name DEDUP VALUES
+  {
+    $$.val = &tree.AlterType{
+      Type: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterTypeDedupValues{},
+    }
+  }
+| ALTER TYPE type_name COMPACT
+  {
+    $$.val = &tree.AlterType{
+      Type: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterTypeCompact{},
+    }
+  }
+| ALTER TYPE type_name NORMALIZE REPRESENTATION
+  {
+    $$.val = &tree.AlterType{
+      Type: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterTypeNormalizeRepresentation{},
+    }
+  }
+| ALTER TYPE type_name PROMOTE VALUE SCONST
+  {
+    $$.val = &tree.AlterType{
+      Type: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterTypePromoteValue{
+        Val: tree.EnumValue($6),
+      },
+    }
+  }
+| ALTER TYPE type_name ALTER VALUE SCONST SET CODE signed_iconst64
+  {
+    $$.val = &tree.AlterType{
+      Type: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterTypeSetValueCode{
+        Val: tree.EnumValue($6),
+        Code: $9.int64(),
+      },
+    }
+  }
+| ALTER TYPE type_name RESTRICT VALUES FOR role_spec TO '(' enum_val_list ')'
+  {
+    $$.val = &tree.AlterType{
+      Type: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterTypeRestrictValues{
+        Role: $7.roleSpec(),
+        Values: $10.enumValueList(),
+      },
+    }
+  }
+| ALTER TYPE type_name RESTRICT VALUES FOR role_spec TO DEFAULT
+  {
+    $$.val = &tree.AlterType{
+      Type: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterTypeRestrictValues{
+        Role: $7.roleSpec(),
+      },
+    }
+  }
+| ALTER TYPE type_name ADD CONSTRAINT constraint_name CHECK '(' a_expr ')'
+  {
+    $$.val = &tree.AlterType{
+      Type: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterTypeAddConstraint{
+        Name: tree.Name($6),
+        Expr: $9.expr(),
+      },
+    }
+  }
+| ALTER TYPE type_name DROP CONSTRAINT constraint_name
+  {
+    $$.val = &tree.AlterType{
+      Type: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterTypeDropConstraint{
+        Name: tree.Name($6),
+      },
+    }
+  }
+| ALTER TYPE type_name DROP CONSTRAINT IF EXISTS constraint_name
+  {
+    $$.val = &tree.AlterType{
+      Type: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterTypeDropConstraint{
+        Name: tree.Name($8),
+        IfExists: true,
+      },
+    }
+  }
+| ALTER TYPE type_name RENAME VALUE SCONST TO SCONST opt_rename_val_if_exists opt_rename_val_expected_rows
   {
     $$.val = &tree.AlterType{
       Type: $3.unresolvedObjectName(),
       Cmd: &tree.AlterTypeRenameValue{
         OldVal: tree.EnumValue($6),
         NewVal: tree.EnumValue($8),
+        IfExists: $9.bool(),
+        ExpectedRows: $10.int64Ptr(),
+      },
+    }
+  }
+| ALTER TYPE type_name RENAME VALUE SCONST TO SCONST opt_rename_val_if_exists REFRESH opt_rename_val_expected_rows
+  {
+    $$.val = &tree.AlterType{
+      Type: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterTypeRenameValue{
+        OldVal: tree.EnumValue($6),
+        NewVal: tree.EnumValue($8),
+        IfExists: $9.bool(),
+        RefreshViews: true,
+        ExpectedRows: $11.int64Ptr(),
       },
     }
   }
@@ -3068,6 +3224,25 @@ alter_type_stmt:
       },
     }
   }
+| ALTER TYPE type_name SET SCHEMA schema_name CASCADE
+  {
+    $$.val = &tree.AlterType{
+      Type: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterTypeSetSchema{
+        Schema: tree.Name($6),
+        Cascade: true,
+      },
+    }
+  }
+| ALTER TYPE type_name SET OID iconst64
+  {
+    $$.val = &tree.AlterType{
+      Type: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterTypeSetOID{
+        OID: $6.int64(),
+      },
+    }
+  }
 | ALTER TYPE type_name OWNER TO role_spec
   {
     $$.val = &tree.AlterType{
@@ -3081,12 +3256,49 @@ alter_type_stmt:
   {
     return unimplementedWithIssueDetail(sqllex, 48701, "ALTER TYPE ATTRIBUTE")
   }
-| ALTER TYPE type_name alter_attribute_action_list
+| ALTER TYPE type_name alter_attribute_action
+  {
+    $$.val = &tree.AlterType{
+      Type: $3.unresolvedObjectName(),
+      Cmd: $4.alterTypeCmd(),
+    }
+  }
+| ALTER TYPE type_name alter_attribute_action ',' alter_attribute_action_list
   {
     return unimplementedWithIssueDetail(sqllex, 48701, "ALTER TYPE ATTRIBUTE")
   }
 | ALTER TYPE error // SHOW HELP: ALTER TYPE
 
+alter_type_add_value_cmd:
+  ADD VALUE SCONST opt_add_val_placement opt_add_val_staged
+  {
+    $$.val = &tree.AlterTypeAddValue{
+      NewVal: tree.EnumValue($3),
+      IfNotExists: false,
+      Placement: $4.alterTypeAddValuePlacement(),
+      Staged: $5.bool(),
+    }
+  }
+| ADD VALUE IF NOT EXISTS SCONST opt_add_val_placement opt_add_val_staged
+  {
+    $$.val = &tree.AlterTypeAddValue{
+      NewVal: tree.EnumValue($6),
+      IfNotExists: true,
+      Placement: $7.alterTypeAddValuePlacement(),
+      Staged: $8.bool(),
+    }
+  }
+
+alter_type_add_value_cmd_list:
+  alter_type_add_value_cmd
+  {
+    $$.val = []*tree.AlterTypeAddValue{$1.alterTypeAddValue()}
+  }
+| alter_type_add_value_cmd_list ',' alter_type_add_value_cmd
+  {
+    $$.val = append($1.alterTypeAddValues(), $3.alterTypeAddValue())
+  }
+
 opt_add_val_placement:
   BEFORE SCONST
   {
@@ -3107,6 +3319,59 @@ opt_add_val_placement:
     $$.val = (*tree.AlterTypeAddValuePlacement)(nil)
   }
 
+opt_add_val_staged:
+  WITH '(' name ')'
+  {
+    // STAGED is not a keyword, so it is parsed as a name.
+    if $3 != "staged" {
+      return setErr(sqllex, errors.Newf("unrecognized ADD VALUE option %q", $3))
+    }
+    $$.val = true
+  }
+| /* EMPTY */
+  {
+    $$.val = false
+  }
+
+opt_rename_val_if_exists:
+  IF EXISTS
+  {
+    $$.val = true
+  }
+| /* EMPTY */
+  {
+    $$.val = false
+  }
+
+opt_rename_val_expected_rows:
+  WITH '(' name '=' iconst64 ')'
+  {
+    // EXPECTED_ROWS is not a keyword, so it is parsed as a name.
+    if $3 != "expected_rows" {
+      return setErr(sqllex, errors.Newf("unrecognized RENAME VALUE option %q", $3))
+    }
+    n := $5.int64()
+    $$.val = &n
+  }
+| /* EMPTY */
+  {
+    $$.val = (*int64)(nil)
+  }
+
+opt_add_val_usage_grantees:
+  GRANT name TO role_spec_list
+  {
+    // USAGE is not a keyword, so it is parsed as a name.
+    if $2 != "usage" {
+      return setErr(sqllex, errors.New("only USAGE can be granted when adding an enum value"))
+    }
+    $$.val = $4.roleSpecList()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.RoleSpecList(nil)
+  }
+
 role_spec:
   IDENT
   {
@@ -3146,15 +3411,35 @@ role_spec_list:
   }
 
 alter_attribute_action_list:
-  alter_attribute_action
-| alter_attribute_action_list ',' alter_attribute_action
+  alter_attribute_action                                 {}
+| alter_attribute_action_list ',' alter_attribute_action {}
 
 alter_attribute_action:
-  ADD ATTRIBUTE column_name type_name opt_collate opt_drop_behavior
+  ADD ATTRIBUTE column_name typename opt_collate opt_drop_behavior
+  {
+    $$.val = &tree.AlterTypeAddAttribute{
+      Name: tree.Name($3),
+      Type: $4.typeReference(),
+      Collation: $5,
+      DropBehavior: $6.dropBehavior(),
+    }
+  }
 | DROP ATTRIBUTE column_name opt_drop_behavior
+  {
+    return unimplementedWithIssueDetail(sqllex, 48701, "ALTER TYPE ATTRIBUTE")
+  }
 | DROP ATTRIBUTE IF EXISTS column_name opt_drop_behavior
+  {
+    return unimplementedWithIssueDetail(sqllex, 48701, "ALTER TYPE ATTRIBUTE")
+  }
 | ALTER ATTRIBUTE column_name TYPE type_name opt_collate opt_drop_behavior
+  {
+    return unimplementedWithIssueDetail(sqllex, 48701, "ALTER TYPE ATTRIBUTE")
+  }
 | ALTER ATTRIBUTE column_name SET DATA TYPE type_name opt_collate opt_drop_behavior
+  {
+    return unimplementedWithIssueDetail(sqllex, 48701, "ALTER TYPE ATTRIBUTE")
+  }
 
 // %Help: REFRESH - recalculate a materialized view
 // %Category: Misc
@@ -11099,7 +11384,9 @@ opt_view_recursive:
 
 // %Help: CREATE TYPE - create a type
 // %Category: DDL
-// %Text: CREATE TYPE [IF NOT EXISTS] <type_name> AS ENUM (...)
+// %Text:
+// CREATE TYPE [IF NOT EXISTS] <type_name> AS ENUM (...)
+// CREATE TYPE [IF NOT EXISTS] <type_name> FROM SPEC <spec>
 create_type_stmt:
   // Enum types.
   CREATE TYPE type_name AS ENUM '(' opt_enum_val_list ')'
@@ -11119,6 +11406,23 @@ create_type_stmt:
       IfNotExists: true,
     }
   }
+| CREATE TYPE type_name FROM SPEC SCONST
+  {
+    $$.val = &tree.CreateType{
+      TypeName: $3.unresolvedObjectName(),
+      Variety: tree.Enum,
+      EnumSpec: $6,
+    }
+  }
+| CREATE TYPE IF NOT EXISTS type_name FROM SPEC SCONST
+  {
+    $$.val = &tree.CreateType{
+      TypeName: $6.unresolvedObjectName(),
+      Variety: tree.Enum,
+      EnumSpec: $9,
+      IfNotExists: true,
+    }
+  }
 | CREATE TYPE error // SHOW HELP: CREATE TYPE
   // Record/Composite types.
 | CREATE TYPE type_name AS '(' opt_composite_type_list ')'
@@ -16961,6 +17265,7 @@ unreserved_keyword:
 | CLOSE
 | CLUSTER
 | CLUSTERS
+| CODE
 | COLUMNS
 | COMMENT
 | COMMENTS
@@ -17000,6 +17305,7 @@ unreserved_keyword:
 | DEBUG_PAUSE_ON
 | DEBUG_DUMP_METADATA_SST
 | DECLARE
+| DEDUP
 | DELETE
 | DEFAULTS
 | DEFERRED
@@ -17152,6 +17458,7 @@ unreserved_keyword:
 | NEXT
 | NO
 | NORMAL
+| NORMALIZE
 | NOTHING
 | NO_INDEX_JOIN
 | NO_ZIGZAG_JOIN
@@ -17176,6 +17483,7 @@ unreserved_keyword:
 | INSENSITIVE
 | OF
 | OFF
+| OID
 | OIDS
 | OLD_KMS
 | OPERATOR
@@ -17213,6 +17521,7 @@ unreserved_keyword:
 | PRIVILEGES
 | PROCEDURE
 | PROCEDURES
+| PROMOTE
 | PUBLIC
 | PUBLICATION
 | QUERIES
@@ -17240,6 +17549,7 @@ unreserved_keyword:
 | REPEATABLE
 | REPLACE
 | REPLICATION
+| REPRESENTATION
 | RESET
 | RESTART
 | RESTORE
@@ -17299,6 +17609,7 @@ unreserved_keyword:
 | SKIP_MISSING_VIEWS
 | SKIP_MISSING_UDFS
 | SNAPSHOT
+| SPEC
 | SPLIT
 | SQL
 | SQLLOGIN
@@ -17449,6 +17760,7 @@ bare_label_keywords:
 | CLOSE
 | CLUSTER
 | CLUSTERS
+| CODE
 | COALESCE
 | COLLATION
 | COLUMN
@@ -17502,6 +17814,7 @@ bare_label_keywords:
 | DEC
 | DECIMAL
 | DECLARE
+| DEDUP
 | DEFAULT
 | DEFAULTS
 | DEFERRABLE
@@ -17706,6 +18019,7 @@ bare_label_keywords:
 | NONE
 | NONVOTERS
 | NORMAL
+| NORMALIZE
 | NOREPLICATION
 | NOSQLLOGIN
 | NOT
@@ -17724,6 +18038,7 @@ bare_label_keywords:
 | NUMERIC
 | OF
 | OFF
+| OID
 | OIDS
 | OLD_KMS
 | ONLY
@@ -17770,6 +18085,7 @@ bare_label_keywords:
 | PRIVILEGES
 | PROCEDURE
 | PROCEDURES
+| PROMOTE
 | PUBLIC
 | PUBLICATION
 | QUERIES
@@ -17799,6 +18115,7 @@ bare_label_keywords:
 | REPEATABLE
 | REPLACE
 | REPLICATION
+| REPRESENTATION
 | RESET
 | RESTART
 | RESTORE
@@ -17864,6 +18181,7 @@ bare_label_keywords:
 | SMALLINT
 | SNAPSHOT
 | SOME
+| SPEC
 | SPLIT
 | SQL
 | SQLLOGIN
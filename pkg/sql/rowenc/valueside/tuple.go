@@ -40,8 +40,15 @@ func encodeUntaggedTuple(
 
 // decodeTuple decodes a tuple from its value encoding. It is the
 // counterpart of encodeTuple().
+//
+// The encoded tuple may have a different number of elements than tupTyp,
+// since attributes can be added to composite types with ALTER TYPE ... ADD
+// ATTRIBUTE after values of the type were written, and since nodes that
+// haven't leased the new version of the type yet may read values written by
+// nodes that have. Missing elements are decoded as NULL, and extra elements
+// are skipped.
 func decodeTuple(a *tree.DatumAlloc, tupTyp *types.T, b []byte) (tree.Datum, []byte, error) {
-	b, _, _, err := encoding.DecodeNonsortingUvarint(b)
+	b, _, numElems, err := encoding.DecodeNonsortingUvarint(b)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -50,11 +57,22 @@ func decodeTuple(a *tree.DatumAlloc, tupTyp *types.T, b []byte) (tree.Datum, []b
 	result.D = a.NewDatums(len(tupTyp.TupleContents()))
 	var datum tree.Datum
 	for i := range tupTyp.TupleContents() {
+		if uint64(i) >= numElems {
+			result.D[i] = tree.DNull
+			continue
+		}
 		datum, b, err = Decode(a, tupTyp.TupleContents()[i], b)
 		if err != nil {
 			return nil, b, err
 		}
 		result.D[i] = datum
 	}
+	for i := uint64(len(tupTyp.TupleContents())); i < numElems; i++ {
+		_, n, err := encoding.PeekValueLength(b)
+		if err != nil {
+			return nil, b, err
+		}
+		b = b[n:]
+	}
 	return a.NewDTuple(result), b, nil
 }
@@ -136,6 +136,44 @@ func TestDecodeTupleValueWithType(t *testing.T) {
 	require.Equal(t, decoded, datum)
 }
 
+// This test ensures that a tuple value can be decoded with a tuple type that
+// has a different number of elements, since attributes can be added to
+// composite types after values of them were written.
+func TestDecodeTupleValueWithDifferentNumberOfElements(t *testing.T) {
+	oldType := types.MakeLabeledTuple([]*types.T{types.Int, types.String}, []string{"a", "b"})
+	newType := types.MakeLabeledTuple(
+		[]*types.T{types.Int, types.String, types.Bool}, []string{"a", "b", "c"},
+	)
+	// The value is followed by another one, which must be decoded correctly
+	// after the tuple.
+	encode := func(d tree.Datum) []byte {
+		buf, err := valueside.Encode(nil, valueside.NoColumnID, d, nil)
+		require.NoError(t, err)
+		buf, err = valueside.Encode(buf, valueside.NoColumnID, tree.NewDInt(tree.DInt(7)), nil)
+		require.NoError(t, err)
+		return buf
+	}
+	decode := func(typ *types.T, buf []byte) tree.Datum {
+		da := tree.DatumAlloc{}
+		decoded, rest, err := valueside.Decode(&da, typ, buf)
+		require.NoError(t, err)
+		next, _, err := valueside.Decode(&da, types.Int, rest)
+		require.NoError(t, err)
+		require.Equal(t, tree.NewDInt(tree.DInt(7)), next)
+		return decoded
+	}
+
+	// Missing elements are decoded as NULL.
+	decoded := decode(newType, encode(tree.NewDTuple(oldType, tree.NewDInt(tree.DInt(1)), tree.NewDString("foo"))))
+	require.Equal(t, tree.NewDTuple(newType, tree.NewDInt(tree.DInt(1)), tree.NewDString("foo"), tree.DNull), decoded)
+
+	// Extra elements are skipped.
+	decoded = decode(oldType, encode(tree.NewDTuple(
+		newType, tree.NewDInt(tree.DInt(1)), tree.NewDString("foo"), tree.DBoolTrue,
+	)))
+	require.Equal(t, tree.NewDTuple(oldType, tree.NewDInt(tree.DInt(1)), tree.NewDString("foo")), decoded)
+}
+
 func TestLegacy(t *testing.T) {
 	tests := []struct {
 		typ   *types.T
@@ -49,6 +49,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/randgen/randgencfg"
 	"github.com/cockroachdb/cockroach/pkg/sql/colexecerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/enum"
 	"github.com/cockroachdb/cockroach/pkg/sql/lex"
 	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
@@ -4724,6 +4725,67 @@ value if you rely on the HLC for accuracy.`,
 		},
 	),
 
+	"crdb_internal.export_type": makeBuiltin(
+		tree.FunctionProperties{Category: builtinconstants.CategoryEnum},
+		tree.Overload{
+			Types:      tree.ParamTypes{{Name: "typ", Typ: types.RegType}},
+			ReturnType: tree.FixedReturnType(types.Jsonb),
+			Fn: func(ctx context.Context, evalCtx *eval.Context, args tree.Datums) (tree.Datum, error) {
+				typ, err := resolveEnumTypeByOID(ctx, evalCtx, tree.MustBeDOid(args[0]))
+				if err != nil {
+					return nil, err
+				}
+				// Read-only members are in the middle of being added or removed,
+				// so they are not part of the exported definition.
+				data := typ.TypeMeta.EnumData
+				var logicalReps []string
+				var physicalReps [][]byte
+				for i := range data.LogicalRepresentations {
+					if data.IsMemberReadOnly[i] {
+						continue
+					}
+					logicalReps = append(logicalReps, data.LogicalRepresentations[i])
+					physicalReps = append(physicalReps, data.PhysicalRepresentations[i])
+				}
+				encoded, err := enum.MakeSpec(typ.Name(), logicalReps, physicalReps).Encode()
+				if err != nil {
+					return nil, err
+				}
+				return tree.ParseDJSON(encoded)
+			},
+			Info: "Returns a JSON spec describing the input enum type, including the physical " +
+				"representation of each value. The spec can be used with CREATE TYPE ... FROM SPEC " +
+				"to recreate the type with identical encodings.",
+			Volatility: volatility.Stable,
+		},
+	),
+
+	"crdb_internal.enum_code": makeBuiltin(
+		tree.FunctionProperties{Category: builtinconstants.CategoryEnum},
+		tree.Overload{
+			Types:      tree.ParamTypes{{Name: "val", Typ: types.AnyEnum}},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Fn: func(ctx context.Context, evalCtx *eval.Context, args tree.Datums) (tree.Datum, error) {
+				d := args[0].(*tree.DEnum)
+				codes := d.EnumTyp.TypeMeta.EnumData.Codes
+				if codes == nil {
+					return tree.DNull, nil
+				}
+				idx, err := d.EnumTyp.EnumGetIdxOfPhysical(d.PhysicalRep)
+				if err != nil {
+					return nil, err
+				}
+				if codes[idx] == nil {
+					return tree.DNull, nil
+				}
+				return tree.NewDInt(tree.DInt(*codes[idx])), nil
+			},
+			Info: "Returns the code of the input enum value set with ALTER TYPE ... ALTER VALUE ... " +
+				"SET CODE, or NULL if the value has no code.",
+			Volatility: volatility.Stable,
+		},
+	),
+
 	// Metadata functions.
 
 	// https://www.postgresql.org/docs/10/static/functions-info.html
@@ -11479,6 +11541,23 @@ func prettyStatement(p tree.PrettyCfg, stmt string) (string, error) {
 	return formattedStmt.String(), nil
 }
 
+// resolveEnumTypeByOID resolves the type referenced by the given regtype,
+// returning an error if it is not an enum.
+func resolveEnumTypeByOID(ctx context.Context, evalCtx *eval.Context, d *tree.DOid) (*types.T, error) {
+	typ, ok := types.OidToType[d.Oid]
+	if !ok {
+		var err error
+		typ, err = evalCtx.Planner.ResolveTypeByOID(ctx, d.Oid)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if typ.Family() != types.EnumFamily || typ.TypeMeta.EnumData == nil {
+		return nil, pgerror.Newf(pgcode.WrongObjectType, "%s is not an enum", typ.Name())
+	}
+	return typ, nil
+}
+
 func parseSpan(arg tree.Datum) (roachpb.Span, error) {
 	arr := tree.MustBeDArray(arg)
 	if arr.Len() != 2 {
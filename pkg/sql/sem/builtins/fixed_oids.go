@@ -2564,6 +2564,11 @@ var builtinOidsArray = []string{
 	2596: `array_agg(arg1: refcursor[]) -> refcursor[][]`,
 	2597: `array_agg(arg1: tuple[]) -> tuple[][]`,
 	2598: `setseed(seed: float) -> void`,
+	2599: `crdb_internal.export_type(typ: regtype) -> jsonb`,
+	2600: `crdb_internal.enum_storage_report(typ: regtype) -> tuple{int AS table_id, string AS table_name, string AS column_name, int AS estimated_row_count, int AS enum_bytes, int AS string_bytes, int AS estimated_bytes_saved}`,
+	2601: `crdb_internal.enum_values_used_by(tbl: regclass, typ: regtype) -> tuple{string AS value}`,
+	2602: `crdb_internal.enum_values_used_by(tbl: regclass, typ: regtype, max_rows: int) -> tuple{string AS value}`,
+	2603: `crdb_internal.enum_code(val: anyenum) -> int`,
 }
 
 var builtinOidsBySignature map[string]oid.Oid
@@ -14,6 +14,8 @@ import (
 	"bytes"
 	"context"
 	gojson "encoding/json"
+	"fmt"
+	"math"
 	"math/rand"
 	"sort"
 	"strconv"
@@ -550,6 +552,54 @@ The output can be used to recreate a database.'
 			volatility.Volatile,
 		),
 	),
+	"crdb_internal.enum_storage_report": makeBuiltin(
+		tree.FunctionProperties{Category: builtinconstants.CategoryEnum},
+		makeGeneratorOverload(
+			tree.ParamTypes{
+				{Name: "typ", Typ: types.RegType},
+			},
+			enumStorageReportGeneratorType,
+			makeEnumStorageReportGenerator,
+			`Returns a row for each table column of the input enum type in the current
+database, estimating the bytes used to store the column's values and the bytes
+they would use as a STRING column. The estimates assume that all values are
+used equally often, and multiply the average width of the physical and logical
+representations by the row count of the table's most recent statistics. The
+row count and estimates are NULL for tables without statistics. Columns of the
+enum's array type are not included.`,
+			volatility.Stable,
+		),
+	),
+	"crdb_internal.enum_values_used_by": makeBuiltin(
+		tree.FunctionProperties{Category: builtinconstants.CategoryEnum},
+		makeGeneratorOverload(
+			tree.ParamTypes{
+				{Name: "tbl", Typ: types.RegClass},
+				{Name: "typ", Typ: types.RegType},
+			},
+			enumValuesUsedByGeneratorType,
+			makeEnumValuesUsedByGenerator,
+			`Returns the distinct values of the input enum type that are stored in the
+columns of the input table of that type, in the order of the enum. This scans
+every such column of the table. Columns of the enum's array type are not
+included.`,
+			volatility.Volatile,
+		),
+		makeGeneratorOverload(
+			tree.ParamTypes{
+				{Name: "tbl", Typ: types.RegClass},
+				{Name: "typ", Typ: types.RegType},
+				{Name: "max_rows", Typ: types.Int},
+			},
+			enumValuesUsedByGeneratorType,
+			makeEnumValuesUsedByGenerator,
+			`Like crdb_internal.enum_values_used_by(tbl, typ), but only scans up to
+max_rows rows of each column, to bound the cost of the scan for large tables.
+The result is approximate: values that are only stored in rows beyond the
+limit are not returned.`,
+			volatility.Volatile,
+		),
+	),
 	"crdb_internal.decode_plan_gist": makeBuiltin(
 		tree.FunctionProperties{},
 		makeGeneratorOverload(
@@ -3526,3 +3576,273 @@ func makeSpanStatsGenerator(
 
 	return &spanStatsValueGenerator{p: evalCtx.Planner, spans: spans}, nil
 }
+
+var enumStorageReportGeneratorType = types.MakeLabeledTuple(
+	[]*types.T{types.Int, types.String, types.String, types.Int, types.Int, types.Int, types.Int},
+	[]string{
+		"table_id", "table_name", "column_name", "estimated_row_count",
+		"enum_bytes", "string_bytes", "estimated_bytes_saved",
+	},
+)
+
+// enumStorageReportGenerator supports the execution of
+// crdb_internal.enum_storage_report(typ).
+type enumStorageReportGenerator struct {
+	planner eval.Planner
+	typ     *types.T
+	rows    []tree.Datums
+	idx     int
+}
+
+func makeEnumStorageReportGenerator(
+	ctx context.Context, evalCtx *eval.Context, args tree.Datums,
+) (eval.ValueGenerator, error) {
+	typ, err := resolveEnumTypeByOID(ctx, evalCtx, tree.MustBeDOid(args[0]))
+	if err != nil {
+		return nil, err
+	}
+	return &enumStorageReportGenerator{planner: evalCtx.Planner, typ: typ}, nil
+}
+
+// ResolvedType implements the eval.ValueGenerator interface.
+func (g *enumStorageReportGenerator) ResolvedType() *types.T {
+	return enumStorageReportGeneratorType
+}
+
+// Start implements the eval.ValueGenerator interface.
+func (g *enumStorageReportGenerator) Start(ctx context.Context, _ *kv.Txn) error {
+	// Both representations are stored with the same encoding overhead, so only
+	// their lengths matter. Read-only members can't be written, so they don't
+	// affect the width of stored values.
+	var enumWidth, stringWidth float64
+	var n int
+	data := g.typ.TypeMeta.EnumData
+	for i := range data.LogicalRepresentations {
+		if data.IsMemberReadOnly[i] {
+			continue
+		}
+		enumWidth += float64(len(data.PhysicalRepresentations[i]))
+		stringWidth += float64(len(data.LogicalRepresentations[i]))
+		n++
+	}
+	if n > 0 {
+		enumWidth, stringWidth = enumWidth/float64(n), stringWidth/float64(n)
+	}
+
+	// Columns are listed with the privileges of the current user, so that only
+	// visible tables are reported.
+	it, err := g.planner.QueryIteratorEx(
+		ctx,
+		"crdb_internal.enum_storage_report",
+		sessiondata.NoSessionDataOverride,
+		`SELECT a.attrelid::INT8, c.relname::STRING, a.attname::STRING
+   FROM pg_catalog.pg_attribute AS a
+   JOIN pg_catalog.pg_class AS c ON c.oid = a.attrelid
+  WHERE a.atttypid = $1 AND NOT a.attisdropped AND c.relkind = 'r'
+  ORDER BY c.relname, a.attnum`,
+		tree.NewDOid(g.typ.Oid()),
+	)
+	if err != nil {
+		return err
+	}
+	var columns []tree.Datums
+	for {
+		ok, err := it.Next(ctx)
+		if err != nil {
+			_ = it.Close()
+			return err
+		}
+		if !ok {
+			break
+		}
+		columns = append(columns, append(tree.Datums(nil), it.Cur()...))
+	}
+	if err := it.Close(); err != nil {
+		return err
+	}
+
+	rowCounts := make(map[tree.DInt]tree.Datum)
+	for _, col := range columns {
+		tableID := tree.MustBeDInt(col[0])
+		rowCount, ok := rowCounts[tableID]
+		if !ok {
+			// Statistics are only readable by the node user.
+			row, err := g.planner.QueryRowEx(
+				ctx,
+				"crdb_internal.enum_storage_report-stats",
+				sessiondata.NodeUserSessionDataOverride,
+				`SELECT "rowCount" FROM system.table_statistics WHERE "tableID" = $1 ORDER BY "createdAt" DESC LIMIT 1`,
+				tableID,
+			)
+			if err != nil {
+				return err
+			}
+			rowCount = tree.DNull
+			if row != nil {
+				rowCount = row[0]
+			}
+			rowCounts[tableID] = rowCount
+		}
+
+		enumBytes, stringBytes, saved := tree.DNull, tree.DNull, tree.DNull
+		if rowCount != tree.DNull {
+			rows := float64(tree.MustBeDInt(rowCount))
+			e := int64(math.Round(rows * enumWidth))
+			s := int64(math.Round(rows * stringWidth))
+			enumBytes, stringBytes, saved = tree.NewDInt(tree.DInt(e)), tree.NewDInt(tree.DInt(s)), tree.NewDInt(tree.DInt(s-e))
+		}
+		g.rows = append(g.rows, tree.Datums{
+			col[0], col[1], col[2], rowCount, enumBytes, stringBytes, saved,
+		})
+	}
+	return nil
+}
+
+// Next implements the eval.ValueGenerator interface.
+func (g *enumStorageReportGenerator) Next(_ context.Context) (bool, error) {
+	if g.idx >= len(g.rows) {
+		return false, nil
+	}
+	g.idx++
+	return true, nil
+}
+
+// Values implements the eval.ValueGenerator interface.
+func (g *enumStorageReportGenerator) Values() (tree.Datums, error) {
+	return g.rows[g.idx-1], nil
+}
+
+// Close implements the eval.ValueGenerator interface.
+func (g *enumStorageReportGenerator) Close(_ context.Context) {}
+
+var enumValuesUsedByGeneratorType = types.MakeLabeledTuple(
+	[]*types.T{types.String},
+	[]string{"value"},
+)
+
+// enumValuesUsedByGenerator supports the execution of
+// crdb_internal.enum_values_used_by(tbl, typ[, max_rows]).
+type enumValuesUsedByGenerator struct {
+	planner eval.Planner
+	table   *tree.DOid
+	typ     *types.T
+	// maxRows is the number of rows of each column to scan, or 0 to scan all
+	// of them.
+	maxRows int64
+	rows    []tree.Datums
+	idx     int
+}
+
+func makeEnumValuesUsedByGenerator(
+	ctx context.Context, evalCtx *eval.Context, args tree.Datums,
+) (eval.ValueGenerator, error) {
+	typ, err := resolveEnumTypeByOID(ctx, evalCtx, tree.MustBeDOid(args[1]))
+	if err != nil {
+		return nil, err
+	}
+	g := &enumValuesUsedByGenerator{
+		planner: evalCtx.Planner,
+		table:   tree.MustBeDOid(args[0]),
+		typ:     typ,
+	}
+	if len(args) > 2 {
+		g.maxRows = int64(tree.MustBeDInt(args[2]))
+		if g.maxRows <= 0 {
+			return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+				"max_rows must be positive, got %d", g.maxRows)
+		}
+	}
+	return g, nil
+}
+
+// ResolvedType implements the eval.ValueGenerator interface.
+func (g *enumValuesUsedByGenerator) ResolvedType() *types.T {
+	return enumValuesUsedByGeneratorType
+}
+
+// Start implements the eval.ValueGenerator interface.
+func (g *enumValuesUsedByGenerator) Start(ctx context.Context, _ *kv.Txn) error {
+	// Both the columns and their values are read with the privileges of the
+	// current user, so scanning the table requires SELECT on it.
+	it, err := g.planner.QueryIteratorEx(
+		ctx,
+		"crdb_internal.enum_values_used_by",
+		sessiondata.NoSessionDataOverride,
+		`SELECT a.attname::STRING
+   FROM pg_catalog.pg_attribute AS a
+   JOIN pg_catalog.pg_class AS c ON c.oid = a.attrelid
+  WHERE a.attrelid = $1 AND a.atttypid = $2 AND NOT a.attisdropped AND c.relkind = 'r'
+  ORDER BY a.attnum`,
+		g.table, tree.NewDOid(g.typ.Oid()),
+	)
+	if err != nil {
+		return err
+	}
+	var scans []string
+	for {
+		ok, err := it.Next(ctx)
+		if err != nil {
+			_ = it.Close()
+			return err
+		}
+		if !ok {
+			break
+		}
+		col := string(tree.MustBeDString(it.Cur()[0]))
+		scan := fmt.Sprintf("SELECT t.%[1]s AS v FROM [%[2]d AS t] WHERE t.%[1]s IS NOT NULL",
+			tree.NameString(col), g.table.Oid)
+		if g.maxRows > 0 {
+			scan += fmt.Sprintf(" LIMIT %d", g.maxRows)
+		}
+		scans = append(scans, "("+scan+")")
+	}
+	if err := it.Close(); err != nil {
+		return err
+	}
+	if len(scans) == 0 {
+		return pgerror.Newf(pgcode.UndefinedColumn,
+			"relation %s has no columns of type %s", g.table, g.typ.Name())
+	}
+
+	// Sorting by the enum values themselves orders them by their physical
+	// representation, which is the order of the enum.
+	it, err = g.planner.QueryIteratorEx(
+		ctx,
+		"crdb_internal.enum_values_used_by-scan",
+		sessiondata.NoSessionDataOverride,
+		fmt.Sprintf("SELECT v::STRING FROM (SELECT DISTINCT v FROM (%s)) ORDER BY v",
+			strings.Join(scans, " UNION ALL ")),
+	)
+	if err != nil {
+		return err
+	}
+	for {
+		ok, err := it.Next(ctx)
+		if err != nil {
+			_ = it.Close()
+			return err
+		}
+		if !ok {
+			break
+		}
+		g.rows = append(g.rows, append(tree.Datums(nil), it.Cur()...))
+	}
+	return it.Close()
+}
+
+// Next implements the eval.ValueGenerator interface.
+func (g *enumValuesUsedByGenerator) Next(_ context.Context) (bool, error) {
+	if g.idx >= len(g.rows) {
+		return false, nil
+	}
+	g.idx++
+	return true, nil
+}
+
+// Values implements the eval.ValueGenerator interface.
+func (g *enumValuesUsedByGenerator) Values() (tree.Datums, error) {
+	return g.rows[g.idx-1], nil
+}
+
+// Close implements the eval.ValueGenerator interface.
+func (g *enumValuesUsedByGenerator) Close(_ context.Context) {}
@@ -10,6 +10,8 @@
 
 package tree
 
+import "github.com/cockroachdb/cockroach/pkg/sql/lex"
+
 // AlterType represents an ALTER TYPE statement.
 type AlterType struct {
 	Type *UnresolvedObjectName
@@ -31,25 +33,57 @@ type AlterTypeCmd interface {
 	TelemetryName() string
 }
 
-func (*AlterTypeAddValue) alterTypeCmd()    {}
-func (*AlterTypeRenameValue) alterTypeCmd() {}
-func (*AlterTypeRename) alterTypeCmd()      {}
-func (*AlterTypeSetSchema) alterTypeCmd()   {}
-func (*AlterTypeOwner) alterTypeCmd()       {}
-func (*AlterTypeDropValue) alterTypeCmd()   {}
+func (*AlterTypeAddValue) alterTypeCmd()                {}
+func (*AlterTypeAddValues) alterTypeCmd()               {}
+func (*AlterTypeRenameValue) alterTypeCmd()             {}
+func (*AlterTypeRename) alterTypeCmd()                  {}
+func (*AlterTypeSetSchema) alterTypeCmd()               {}
+func (*AlterTypeOwner) alterTypeCmd()                   {}
+func (*AlterTypeDropValue) alterTypeCmd()               {}
+func (*AlterTypeCheck) alterTypeCmd()                   {}
+func (*AlterTypeValidateData) alterTypeCmd()            {}
+func (*AlterTypeDedupValues) alterTypeCmd()             {}
+func (*AlterTypeCompact) alterTypeCmd()                 {}
+func (*AlterTypeNormalizeRepresentation) alterTypeCmd() {}
+func (*AlterTypePromoteValue) alterTypeCmd()            {}
+func (*AlterTypeSetValueCode) alterTypeCmd()            {}
+func (*AlterTypeRestrictValues) alterTypeCmd()          {}
+func (*AlterTypeAddConstraint) alterTypeCmd()           {}
+func (*AlterTypeDropConstraint) alterTypeCmd()          {}
+func (*AlterTypeSetOID) alterTypeCmd()                  {}
+func (*AlterTypeAddAttribute) alterTypeCmd()            {}
 
 var _ AlterTypeCmd = &AlterTypeAddValue{}
+var _ AlterTypeCmd = &AlterTypeAddValues{}
 var _ AlterTypeCmd = &AlterTypeRenameValue{}
 var _ AlterTypeCmd = &AlterTypeRename{}
 var _ AlterTypeCmd = &AlterTypeSetSchema{}
 var _ AlterTypeCmd = &AlterTypeOwner{}
 var _ AlterTypeCmd = &AlterTypeDropValue{}
+var _ AlterTypeCmd = &AlterTypeCheck{}
+var _ AlterTypeCmd = &AlterTypeValidateData{}
+var _ AlterTypeCmd = &AlterTypeDedupValues{}
+var _ AlterTypeCmd = &AlterTypeCompact{}
+var _ AlterTypeCmd = &AlterTypeNormalizeRepresentation{}
+var _ AlterTypeCmd = &AlterTypePromoteValue{}
+var _ AlterTypeCmd = &AlterTypeSetValueCode{}
+var _ AlterTypeCmd = &AlterTypeRestrictValues{}
+var _ AlterTypeCmd = &AlterTypeAddConstraint{}
+var _ AlterTypeCmd = &AlterTypeDropConstraint{}
+var _ AlterTypeCmd = &AlterTypeSetOID{}
+var _ AlterTypeCmd = &AlterTypeAddAttribute{}
 
 // AlterTypeAddValue represents an ALTER TYPE ADD VALUE command.
 type AlterTypeAddValue struct {
 	NewVal      EnumValue
 	IfNotExists bool
 	Placement   *AlterTypeAddValuePlacement
+	// Staged, if set, leaves the new value read-only until it is promoted with
+	// ALTER TYPE ... PROMOTE VALUE.
+	Staged bool
+	// UsageGrantees, if set, are the roles that are granted the USAGE privilege
+	// on the type along with adding the value.
+	UsageGrantees RoleSpecList
 }
 
 // Format implements the NodeFormatter interface.
@@ -67,6 +101,13 @@ func (node *AlterTypeAddValue) Format(ctx *FmtCtx) {
 		}
 		ctx.FormatNode(&node.Placement.ExistingVal)
 	}
+	if node.Staged {
+		ctx.WriteString(" WITH (staged)")
+	}
+	if len(node.UsageGrantees) > 0 {
+		ctx.WriteString(" GRANT USAGE TO ")
+		ctx.FormatNode(&node.UsageGrantees)
+	}
 }
 
 // TelemetryName implements the AlterTypeCmd interface.
@@ -74,6 +115,35 @@ func (node *AlterTypeAddValue) TelemetryName() string {
 	return "add_value"
 }
 
+// AlterTypeAddValues represents an ALTER TYPE command that adds several values
+// at once: ALTER TYPE ... ADD VALUE ..., ADD VALUE ....
+type AlterTypeAddValues struct {
+	// Values are the values to add, in order. Their UsageGrantees are unset.
+	Values []*AlterTypeAddValue
+	// UsageGrantees, if set, are the roles that are granted the USAGE privilege
+	// on the type along with adding the values.
+	UsageGrantees RoleSpecList
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeAddValues) Format(ctx *FmtCtx) {
+	for i, v := range node.Values {
+		if i > 0 {
+			ctx.WriteString(",")
+		}
+		ctx.FormatNode(v)
+	}
+	if len(node.UsageGrantees) > 0 {
+		ctx.WriteString(" GRANT USAGE TO ")
+		ctx.FormatNode(&node.UsageGrantees)
+	}
+}
+
+// TelemetryName implements the AlterTypeCmd interface.
+func (node *AlterTypeAddValues) TelemetryName() string {
+	return "add_values"
+}
+
 // AlterTypeAddValuePlacement represents the placement clause for an ALTER
 // TYPE ADD VALUE command ([BEFORE | AFTER] value).
 type AlterTypeAddValuePlacement struct {
@@ -85,6 +155,14 @@ type AlterTypeAddValuePlacement struct {
 type AlterTypeRenameValue struct {
 	OldVal EnumValue
 	NewVal EnumValue
+	// IfExists, if set, makes the rename a no-op if OldVal doesn't exist.
+	IfExists bool
+	// RefreshViews, if set, refreshes the materialized views that depend on the
+	// type once the rename is complete.
+	RefreshViews bool
+	// ExpectedRows, if set, is the number of rows that must use OldVal for the
+	// rename to succeed.
+	ExpectedRows *int64
 }
 
 // Format implements the NodeFormatter interface.
@@ -93,6 +171,15 @@ func (node *AlterTypeRenameValue) Format(ctx *FmtCtx) {
 	ctx.FormatNode(&node.OldVal)
 	ctx.WriteString(" TO ")
 	ctx.FormatNode(&node.NewVal)
+	if node.IfExists {
+		ctx.WriteString(" IF EXISTS")
+	}
+	if node.RefreshViews {
+		ctx.WriteString(" REFRESH")
+	}
+	if node.ExpectedRows != nil {
+		ctx.Printf(" WITH (expected_rows = %d)", *node.ExpectedRows)
+	}
 }
 
 // TelemetryName implements the AlterTypeCmd interface.
@@ -103,19 +190,204 @@ func (node *AlterTypeRenameValue) TelemetryName() string {
 // AlterTypeDropValue represents an ALTER TYPE DROP VALUE command.
 type AlterTypeDropValue struct {
 	Val EnumValue
+	// Replacement, if set, is the value that rows using Val are rewritten to
+	// before Val is dropped.
+	Replacement *EnumValue
 }
 
 // Format implements the NodeFormatter interface.
 func (node *AlterTypeDropValue) Format(ctx *FmtCtx) {
 	ctx.WriteString(" DROP VALUE ")
 	ctx.FormatNode(&node.Val)
+	if node.Replacement != nil {
+		ctx.WriteString(" REPLACE WITH ")
+		ctx.FormatNode(node.Replacement)
+	}
 }
 
 // TelemetryName implements the AlterTypeCmd interface.
 func (node *AlterTypeDropValue) TelemetryName() string {
+	if node.Replacement != nil {
+		return "drop_value_replace"
+	}
 	return "drop_value"
 }
 
+// AlterTypeCheck represents an ALTER TYPE CHECK command, which reports
+// potential problems with the type without modifying it.
+type AlterTypeCheck struct{}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeCheck) Format(ctx *FmtCtx) {
+	ctx.WriteString(" CHECK")
+}
+
+// TelemetryName implements the AlterTypeCmd interface.
+func (node *AlterTypeCheck) TelemetryName() string {
+	return "check"
+}
+
+// AlterTypeValidateData represents an ALTER TYPE VALIDATE DATA command, which
+// reports stored values that don't belong to any member of the type.
+type AlterTypeValidateData struct{}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeValidateData) Format(ctx *FmtCtx) {
+	ctx.WriteString(" VALIDATE DATA")
+}
+
+// TelemetryName implements the AlterTypeCmd interface.
+func (node *AlterTypeValidateData) TelemetryName() string {
+	return "validate_data"
+}
+
+// AlterTypeDedupValues represents an ALTER TYPE DEDUP VALUES command, which
+// merges enum values that share a label.
+type AlterTypeDedupValues struct{}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeDedupValues) Format(ctx *FmtCtx) {
+	ctx.WriteString(" DEDUP VALUES")
+}
+
+// TelemetryName implements the AlterTypeCmd interface.
+func (node *AlterTypeDedupValues) TelemetryName() string {
+	return "dedup_values"
+}
+
+// AlterTypeCompact represents an ALTER TYPE COMPACT command, which shortens
+// the physical representations of enum values.
+type AlterTypeCompact struct{}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeCompact) Format(ctx *FmtCtx) {
+	ctx.WriteString(" COMPACT")
+}
+
+// TelemetryName implements the AlterTypeCmd interface.
+func (node *AlterTypeCompact) TelemetryName() string {
+	return "compact"
+}
+
+// AlterTypeNormalizeRepresentation represents an ALTER TYPE NORMALIZE
+// REPRESENTATION command, which rewrites the physical representations of
+// enum values to dense integers in logical order.
+type AlterTypeNormalizeRepresentation struct{}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeNormalizeRepresentation) Format(ctx *FmtCtx) {
+	ctx.WriteString(" NORMALIZE REPRESENTATION")
+}
+
+// TelemetryName implements the AlterTypeCmd interface.
+func (node *AlterTypeNormalizeRepresentation) TelemetryName() string {
+	return "normalize_representation"
+}
+
+// AlterTypePromoteValue represents an ALTER TYPE PROMOTE VALUE command, which
+// makes a value added with ALTER TYPE ... ADD VALUE ... WITH (staged) usable.
+type AlterTypePromoteValue struct {
+	Val EnumValue
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypePromoteValue) Format(ctx *FmtCtx) {
+	ctx.WriteString(" PROMOTE VALUE ")
+	ctx.FormatNode(&node.Val)
+}
+
+// TelemetryName implements the AlterTypeCmd interface.
+func (node *AlterTypePromoteValue) TelemetryName() string {
+	return "promote_value"
+}
+
+// AlterTypeSetValueCode represents an ALTER TYPE ALTER VALUE SET CODE command,
+// which sets the integer code used to map a value to external systems.
+type AlterTypeSetValueCode struct {
+	Val  EnumValue
+	Code int64
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetValueCode) Format(ctx *FmtCtx) {
+	ctx.WriteString(" ALTER VALUE ")
+	ctx.FormatNode(&node.Val)
+	ctx.Printf(" SET CODE %d", node.Code)
+}
+
+// TelemetryName implements the AlterTypeCmd interface.
+func (node *AlterTypeSetValueCode) TelemetryName() string {
+	return "set_value_code"
+}
+
+// AlterTypeRestrictValues represents an ALTER TYPE RESTRICT VALUES command,
+// which limits the values that a role can write. A nil Values removes the
+// restriction.
+type AlterTypeRestrictValues struct {
+	Role   RoleSpec
+	Values EnumValueList
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeRestrictValues) Format(ctx *FmtCtx) {
+	ctx.WriteString(" RESTRICT VALUES FOR ")
+	ctx.FormatNode(&node.Role)
+	if node.Values == nil {
+		ctx.WriteString(" TO DEFAULT")
+		return
+	}
+	ctx.WriteString(" TO (")
+	ctx.FormatNode(&node.Values)
+	ctx.WriteString(")")
+}
+
+// TelemetryName implements the AlterTypeCmd interface.
+func (node *AlterTypeRestrictValues) TelemetryName() string {
+	return "restrict_values"
+}
+
+// AlterTypeAddConstraint represents an ALTER TYPE ADD CONSTRAINT command,
+// which adds a CHECK constraint that all the values of the type that are
+// written must satisfy. The value is referred to as VALUE in Expr.
+type AlterTypeAddConstraint struct {
+	Name Name
+	Expr Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeAddConstraint) Format(ctx *FmtCtx) {
+	ctx.WriteString(" ADD CONSTRAINT ")
+	ctx.FormatNode(&node.Name)
+	ctx.WriteString(" CHECK (")
+	ctx.FormatNode(node.Expr)
+	ctx.WriteString(")")
+}
+
+// TelemetryName implements the AlterTypeCmd interface.
+func (node *AlterTypeAddConstraint) TelemetryName() string {
+	return "add_constraint"
+}
+
+// AlterTypeDropConstraint represents an ALTER TYPE DROP CONSTRAINT command.
+type AlterTypeDropConstraint struct {
+	Name     Name
+	IfExists bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeDropConstraint) Format(ctx *FmtCtx) {
+	ctx.WriteString(" DROP CONSTRAINT ")
+	if node.IfExists {
+		ctx.WriteString("IF EXISTS ")
+	}
+	ctx.FormatNode(&node.Name)
+}
+
+// TelemetryName implements the AlterTypeCmd interface.
+func (node *AlterTypeDropConstraint) TelemetryName() string {
+	return "drop_constraint"
+}
+
 // AlterTypeRename represents an ALTER TYPE RENAME command.
 type AlterTypeRename struct {
 	NewName Name
@@ -135,12 +407,18 @@ func (node *AlterTypeRename) TelemetryName() string {
 // AlterTypeSetSchema represents an ALTER TYPE SET SCHEMA command.
 type AlterTypeSetSchema struct {
 	Schema Name
+	// Cascade, if set, reports the objects that depend on the type once they
+	// refer to it by its new name.
+	Cascade bool
 }
 
 // Format implements the NodeFormatter interface.
 func (node *AlterTypeSetSchema) Format(ctx *FmtCtx) {
 	ctx.WriteString(" SET SCHEMA ")
 	ctx.FormatNode(&node.Schema)
+	if node.Cascade {
+		ctx.WriteString(" CASCADE")
+	}
 }
 
 // TelemetryName implements the AlterTypeCmd interface.
@@ -148,6 +426,53 @@ func (node *AlterTypeSetSchema) TelemetryName() string {
 	return "set_schema"
 }
 
+// AlterTypeSetOID represents an ALTER TYPE SET OID command, which assigns a
+// specific OID to the type.
+type AlterTypeSetOID struct {
+	OID int64
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeSetOID) Format(ctx *FmtCtx) {
+	ctx.Printf(" SET OID %d", node.OID)
+}
+
+// TelemetryName implements the AlterTypeCmd interface.
+func (node *AlterTypeSetOID) TelemetryName() string {
+	return "set_oid"
+}
+
+// AlterTypeAddAttribute represents an ALTER TYPE ADD ATTRIBUTE command, which
+// appends an attribute to a composite type.
+type AlterTypeAddAttribute struct {
+	Name      Name
+	Type      ResolvableTypeReference
+	Collation string
+	// DropBehavior has no effect, since there are no typed tables for it to
+	// apply to.
+	DropBehavior DropBehavior
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeAddAttribute) Format(ctx *FmtCtx) {
+	ctx.WriteString(" ADD ATTRIBUTE ")
+	ctx.FormatNode(&node.Name)
+	ctx.WriteByte(' ')
+	ctx.FormatTypeReference(node.Type)
+	if len(node.Collation) > 0 {
+		ctx.WriteString(" COLLATE ")
+		lex.EncodeLocaleName(&ctx.Buffer, node.Collation)
+	}
+	if node.DropBehavior != DropDefault {
+		ctx.Printf(" %s", node.DropBehavior)
+	}
+}
+
+// TelemetryName implements the AlterTypeCmd interface.
+func (node *AlterTypeAddAttribute) TelemetryName() string {
+	return "add_attribute"
+}
+
 // AlterTypeOwner represents an ALTER TYPE OWNER TO command.
 type AlterTypeOwner struct {
 	Owner RoleSpec
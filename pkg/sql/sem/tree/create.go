@@ -371,6 +371,10 @@ type CreateType struct {
 	Variety  CreateTypeVariety
 	// EnumLabels is set when this represents a CREATE TYPE ... AS ENUM statement.
 	EnumLabels EnumValueList
+	// EnumSpec is set when this represents a CREATE TYPE ... FROM SPEC
+	// statement. It holds the JSON encoded spec produced by
+	// crdb_internal.export_type.
+	EnumSpec string
 	// CompositeTypeList is set when this repesnets a CREATE TYPE ... AS ( )
 	// statement.
 	CompositeTypeList []CompositeTypeElem
@@ -390,6 +394,15 @@ func (node *CreateType) Format(ctx *FmtCtx) {
 	ctx.WriteString(" ")
 	switch node.Variety {
 	case Enum:
+		if node.EnumSpec != "" {
+			ctx.WriteString("FROM SPEC ")
+			if ctx.flags.HasFlags(FmtHideConstants) {
+				ctx.WriteString("'_'")
+			} else {
+				lexbase.EncodeSQLStringWithFlags(&ctx.Buffer, node.EnumSpec, ctx.flags.EncodeFlags())
+			}
+			break
+		}
 		ctx.WriteString("AS ENUM (")
 		ctx.FormatNode(&node.EnumLabels)
 		ctx.WriteString(")")
@@ -565,10 +565,23 @@ func (*AlterTenantService) StatementType() StatementType { return TypeDCL }
 func (*AlterTenantService) StatementTag() string { return "ALTER VIRTUAL CLUSTER SERVICE" }
 
 // StatementReturnType implements the Statement interface.
-func (*AlterType) StatementReturnType() StatementReturnType { return DDL }
+func (n *AlterType) StatementReturnType() StatementReturnType {
+	switch n.Cmd.(type) {
+	case *AlterTypeCheck, *AlterTypeValidateData:
+		return Rows
+	}
+	return DDL
+}
 
 // StatementType implements the Statement interface.
-func (*AlterType) StatementType() StatementType { return TypeDDL }
+func (n *AlterType) StatementType() StatementType {
+	// ALTER TYPE ... CHECK and VALIDATE DATA only read the type and its usages.
+	switch n.Cmd.(type) {
+	case *AlterTypeCheck, *AlterTypeValidateData:
+		return TypeDML
+	}
+	return TypeDDL
+}
 
 // StatementTag implements the Statement interface.
 func (*AlterType) StatementTag() string { return "ALTER TYPE" }
@@ -30,13 +30,17 @@ const (
 	EnumDrop
 	// EnumInTable tracks when an enum type is used in a table.
 	EnumInTable
+	// EnumAddValueSkipped represents an ALTER TYPE ... ADD VALUE IF NOT EXISTS
+	// command that was a no-op because the value already existed.
+	EnumAddValueSkipped
 )
 
 var enumTelemetryMap = map[EnumTelemetryType]string{
-	EnumCreate:  "create_enum",
-	EnumAlter:   "alter_enum",
-	EnumDrop:    "drop_enum",
-	EnumInTable: "enum_used_in_table",
+	EnumCreate:          "create_enum",
+	EnumAlter:           "alter_enum",
+	EnumDrop:            "drop_enum",
+	EnumInTable:         "enum_used_in_table",
+	EnumAddValueSkipped: "alter_enum_add_value_skipped",
 }
 
 func (e EnumTelemetryType) String() string {
@@ -93,6 +93,18 @@ type optTableUpserter struct {
 	// index of the resultRowBuffer where the i-th column of the table is
 	// to be returned.
 	tabColIdxToRetIdx []int
+
+	// insertEnumRestrictions and updateEnumRestrictions restrict the enum
+	// values that the user can write when inserting and updating rows
+	// respectively, as set via ALTER TYPE ... RESTRICT VALUES.
+	insertEnumRestrictions *enumWriteRestrictions
+	updateEnumRestrictions *enumWriteRestrictions
+
+	// insertTypeConstraints and updateTypeConstraints check the values written
+	// when inserting and updating rows respectively against the constraints of
+	// their types, added via ALTER TYPE ... ADD CONSTRAINT.
+	insertTypeConstraints *typeConstraintChecker
+	updateTypeConstraints *typeConstraintChecker
 }
 
 var _ tableWriter = &optTableUpserter{}
@@ -214,6 +226,13 @@ func (tu *optTableUpserter) insertNonConflictingRow(
 	pm row.PartialIndexUpdateHelper,
 	overwrite, traceKV bool,
 ) error {
+	if err := tu.insertEnumRestrictions.check(insertRow); err != nil {
+		return err
+	}
+	if err := tu.insertTypeConstraints.check(ctx, insertRow); err != nil {
+		return err
+	}
+
 	// Perform the insert proper.
 	if err := tu.ri.InsertRow(ctx, &tu.putter, insertRow, pm, overwrite, traceKV); err != nil {
 		return err
@@ -273,6 +292,12 @@ func (tu *optTableUpserter) updateConflictingRow(
 	if err := enforceLocalColumnConstraints(updateValues, tu.updateCols); err != nil {
 		return err
 	}
+	if err := tu.updateEnumRestrictions.check(updateValues); err != nil {
+		return err
+	}
+	if err := tu.updateTypeConstraints.check(ctx, updateValues); err != nil {
+		return err
+	}
 
 	// Queue the update in KV. This also returns an "update row"
 	// containing the updated values for every column in the
@@ -23,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
@@ -37,6 +38,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/regions"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/catid"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/util/intsets"
@@ -45,6 +47,19 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/logtags"
+	"github.com/lib/pq/oid"
+)
+
+// respacePackedEnumValues controls whether adding an enum value without room
+// left between the physical representations of its neighbours triggers moving
+// the representations of all the values of the enum towards evenly spaced ones.
+var respacePackedEnumValues = settings.RegisterBoolSetting(
+	settings.ApplicationLevel,
+	"sql.enum.respace_packed_values.enabled",
+	"if enabled, adding an enum value between two values whose physical representations "+
+		"are adjacent queues a schema change that re-spaces the representations of all values, "+
+		"rewriting the rows that use them",
+	true,
 )
 
 // findTransitioningMembers returns a list of all physical representations that
@@ -98,6 +113,31 @@ func findTransitioningMembers(desc *typedesc.Mutable) ([][]byte, bool) {
 	return transitioningMembers, beingDropped
 }
 
+// findValidatingConstraints returns the names of the constraints of the type
+// that were added in the current txn and are yet to be validated, by diffing
+// the mutated type descriptor against the one read from the cluster.
+func findValidatingConstraints(desc *typedesc.Mutable) []string {
+	var names []string
+	for _, ck := range desc.CheckConstraints {
+		if ck.Validity != descpb.ConstraintValidity_Validating {
+			continue
+		}
+		found := false
+		if !desc.IsNew() {
+			for _, clusterCk := range desc.ClusterVersion.CheckConstraints {
+				if clusterCk.Name == ck.Name && clusterCk.Validity == descpb.ConstraintValidity_Validating {
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			names = append(names, ck.Name)
+		}
+	}
+	return names
+}
+
 // writeTypeSchemaChange should be called on a mutated type descriptor to ensure that
 // the descriptor gets written to a batch, as well as ensuring that a job is
 // created to perform the schema change on the type.
@@ -107,11 +147,17 @@ func (p *planner) writeTypeSchemaChange(
 	// Check if there is a cached specification for this type, otherwise create one.
 	record, recordExists := p.extendedEvalCtx.jobs.uniqueToCreate[typeDesc.ID]
 	transitioningMembers, beingDropped := findTransitioningMembers(typeDesc)
+	validatingConstraints := findValidatingConstraints(typeDesc)
 	if recordExists {
 		// Update it.
+		oldDetails := record.Details.(jobspb.TypeSchemaChangeDetails)
 		newDetails := jobspb.TypeSchemaChangeDetails{
-			TypeID:               typeDesc.ID,
-			TransitioningMembers: transitioningMembers,
+			TypeID:                   typeDesc.ID,
+			TransitioningMembers:     transitioningMembers,
+			RefreshViewIDs:           oldDetails.RefreshViewIDs,
+			ValidatingConstraints:    validatingConstraints,
+			EstimatedRewriteRows:     oldDetails.EstimatedRewriteRows,
+			EstimatedRewriteDuration: oldDetails.EstimatedRewriteDuration,
 		}
 		record.Details = newDetails
 		record.AppendDescription(jobDesc)
@@ -136,8 +182,9 @@ func (p *planner) writeTypeSchemaChange(
 			Username:      p.User(),
 			DescriptorIDs: descpb.IDs{typeDesc.ID},
 			Details: jobspb.TypeSchemaChangeDetails{
-				TypeID:               typeDesc.ID,
-				TransitioningMembers: transitioningMembers,
+				TypeID:                typeDesc.ID,
+				TransitioningMembers:  transitioningMembers,
+				ValidatingConstraints: validatingConstraints,
 			},
 			Progress: jobspb.TypeSchemaChangeProgress{},
 			// Type change jobs in general are not cancelable, unless they include
@@ -151,6 +198,26 @@ func (p *planner) writeTypeSchemaChange(
 	return p.writeTypeDesc(ctx, typeDesc)
 }
 
+// refreshViewsAfterTypeSchemaChange makes the type schema change job queued
+// for the type by writeTypeSchemaChange refresh the given materialized views
+// once the type change is complete.
+func (p *planner) refreshViewsAfterTypeSchemaChange(typeID descpb.ID, viewIDs []descpb.ID) error {
+	record, ok := p.extendedEvalCtx.jobs.uniqueToCreate[typeID]
+	if !ok {
+		return errors.AssertionFailedf("no type schema change job queued for type %d", typeID)
+	}
+	details := record.Details.(jobspb.TypeSchemaChangeDetails)
+	existing := catalog.MakeDescriptorIDSet(details.RefreshViewIDs...)
+	for _, id := range viewIDs {
+		if !existing.Contains(id) {
+			details.RefreshViewIDs = append(details.RefreshViewIDs, id)
+			existing.Add(id)
+		}
+	}
+	record.Details = details
+	return nil
+}
+
 func (p *planner) writeTypeDesc(ctx context.Context, typeDesc *typedesc.Mutable) error {
 	// Write the type out to a batch.
 	b := p.txn.NewBatch()
@@ -170,7 +237,19 @@ type typeSchemaChanger struct {
 	// for a typeSchemaChanger. This is used to group transitions together and
 	// ensure proper rollback semantics on job failure.
 	transitioningMembers [][]byte
-	execCfg              *ExecutorConfig
+	// refreshViewIDs are the materialized views to refresh once the type change
+	// is complete.
+	refreshViewIDs []descpb.ID
+	// respaceEnumValues is set for the jobs queued by queueEnumRespacing, which
+	// queue the next round of moving the values when they complete.
+	respaceEnumValues bool
+	// validatingConstraints are the names of the constraints of the type that
+	// the job validates, and drops if that fails.
+	validatingConstraints []string
+	execCfg               *ExecutorConfig
+	// job is the type schema change job, used to report progress. It may be
+	// nil when the schema changer is not running as part of a job's Resume.
+	job *jobs.Job
 }
 
 // TypeSchemaChangerTestingKnobs contains testing knobs for the typeSchemaChanger.
@@ -189,6 +268,9 @@ type TypeSchemaChangerTestingKnobs struct {
 	// runs after enum promotion and before multi-region updates (such as
 	// repartitioning tables, applying zone configs etc.)
 	RunBeforeMultiRegionUpdates func() error
+	// RunBeforeAddEnumValue runs in ALTER TYPE ... ADD VALUE with the ID of the
+	// type, before it checks whether the value already exists.
+	RunBeforeAddEnumValue func(ctx context.Context, typeID descpb.ID) error
 }
 
 // ModuleTestingKnobs implements the ModuleTestingKnobs interface.
@@ -276,6 +358,12 @@ func (t *typeSchemaChanger) exec(ctx context.Context) error {
 			}
 		}
 
+		// All nodes are able to decode the copies of compacted members now, so
+		// make them writable before any rows are rewritten to them.
+		if err := t.promoteEnumCompactionCopies(ctx); err != nil {
+			return err
+		}
+
 		// In the case where we're dropping elements from a multi-region enum,
 		// we first re-partition all REGIONAL BY ROW tables. This is to handle
 		// the dependency which exist between the partitioning and the enum.
@@ -347,52 +435,66 @@ func (t *typeSchemaChanger) exec(ctx context.Context) error {
 		// First, we check if any of the enum values that are being removed are in
 		// use and fail. This is done in a separate txn to the one that mutates the
 		// descriptor, as this validation can take arbitrarily long.
-		validateDrops := func(ctx context.Context, txn descs.Txn) error {
-			typeDesc, err := txn.Descriptors().MutableByID(txn.KV()).Type(ctx, t.typeID)
-			if err != nil {
-				return err
-			}
-			var toDrop []descpb.TypeDescriptor_EnumMember
-			for _, member := range typeDesc.EnumMembers {
-				if t.isTransitioningInCurrentJob(&member) && enumMemberIsRemoving(&member) {
-					if typeDesc.Kind == descpb.TypeDescriptor_MULTIREGION_ENUM {
-						multiRegionPreDropIsNecessary = true
-					}
-					toDrop = append(toDrop, member)
-				}
-			}
-
-			// If we're dropping a multi-region enum value, we are also going to
-			// repartition all of the regional by row tables during this job. We
-			// don't want to disallow this operation and return an error because
-			// those tables are indeed partitioned by this value. To deal with this
-			// fact, we ask the databaseRegionChangeFinalizer to plan out what
-			// repartitioning it is going to do and inject those synthesized
-			// descriptors into out collection for the purpose of checking whether
-			// it is safe to remove this enum value. We'll not actually write the
-			// changes in this transaction because we don't want this long-running
-			// transaction to be a writing transaction; it would have a heck of
-			// a lot of data to refresh. We instead defer the repartitioning until
-			// after this checking confirms the safety of the change.
-			if multiRegionPreDropIsNecessary {
-				repartitioned, err := prepareRepartitionedRegionalByRowTables(ctx, txn)
+		makeValidateDrops := func(checkRowUsages bool) func(ctx context.Context, txn descs.Txn) error {
+			return func(ctx context.Context, txn descs.Txn) error {
+				typeDesc, err := txn.Descriptors().MutableByID(txn.KV()).Type(ctx, t.typeID)
 				if err != nil {
 					return err
 				}
-				synthetic := make([]catalog.Descriptor, len(repartitioned))
-				for i, d := range repartitioned {
-					synthetic[i] = d
+				var toDrop []descpb.TypeDescriptor_EnumMember
+				for _, member := range typeDesc.EnumMembers {
+					if t.isTransitioningInCurrentJob(&member) && enumMemberIsRemoving(&member) {
+						if typeDesc.Kind == descpb.TypeDescriptor_MULTIREGION_ENUM {
+							multiRegionPreDropIsNecessary = true
+						}
+						toDrop = append(toDrop, member)
+					}
 				}
-				txn.Descriptors().SetSyntheticDescriptors(synthetic)
-			}
-			for _, member := range toDrop {
-				if err := t.canRemoveEnumValue(ctx, typeDesc, txn, &member, txn.Descriptors()); err != nil {
-					return err
+
+				// If we're dropping a multi-region enum value, we are also going to
+				// repartition all of the regional by row tables during this job. We
+				// don't want to disallow this operation and return an error because
+				// those tables are indeed partitioned by this value. To deal with this
+				// fact, we ask the databaseRegionChangeFinalizer to plan out what
+				// repartitioning it is going to do and inject those synthesized
+				// descriptors into out collection for the purpose of checking whether
+				// it is safe to remove this enum value. We'll not actually write the
+				// changes in this transaction because we don't want this long-running
+				// transaction to be a writing transaction; it would have a heck of
+				// a lot of data to refresh. We instead defer the repartitioning until
+				// after this checking confirms the safety of the change.
+				if multiRegionPreDropIsNecessary {
+					repartitioned, err := prepareRepartitionedRegionalByRowTables(ctx, txn)
+					if err != nil {
+						return err
+					}
+					synthetic := make([]catalog.Descriptor, len(repartitioned))
+					for i, d := range repartitioned {
+						synthetic[i] = d
+					}
+					txn.Descriptors().SetSyntheticDescriptors(synthetic)
+				}
+				for _, member := range toDrop {
+					if err := t.canRemoveEnumValue(
+						ctx, typeDesc, txn, &member, txn.Descriptors(), checkRowUsages,
+					); err != nil {
+						return err
+					}
 				}
+				return nil
 			}
-			return nil
 		}
-		if err := t.execCfg.InternalDB.DescsTxn(ctx, validateDrops); err != nil {
+		// Before validating the drops, rewrite any rows using members which are
+		// being dropped with a replacement, so that the validation below
+		// confirms that no usages remain. Usages other than rows are checked
+		// before any rows are rewritten, so that rows aren't rewritten for a drop
+		// that can't succeed.
+		if err := t.rewriteReplacedEnumValues(ctx, func(ctx context.Context) error {
+			return t.execCfg.InternalDB.DescsTxn(ctx, makeValidateDrops(false /* checkRowUsages */))
+		}); err != nil {
+			return err
+		}
+		if err := t.execCfg.InternalDB.DescsTxn(ctx, makeValidateDrops(true /* checkRowUsages */)); err != nil {
 			return err
 		}
 		if multiRegionPreDropIsNecessary {
@@ -410,17 +512,34 @@ func (t *typeSchemaChanger) exec(ctx context.Context) error {
 				return err
 			}
 			// First, deal with all members that need to be promoted to writable.
+			// Staged members stay read-only until ALTER TYPE ... PROMOTE VALUE.
+			// A value that was added without room left between its neighbours
+			// makes the values of the enum get re-spaced. The copies of moved
+			// values don't, since they are only placed where there is room.
+			respace := t.respaceEnumValues
 			for i := range typeDesc.EnumMembers {
 				member := &typeDesc.EnumMembers[i]
-				if t.isTransitioningInCurrentJob(member) && enumMemberIsAdding(member) {
+				if t.isTransitioningInCurrentJob(member) && enumMemberIsAdding(member) && !member.Staged {
+					if typeDesc.Kind == descpb.TypeDescriptor_ENUM &&
+						!typedesc.IsEnumMemberCompactionCopy(typeDesc.EnumMembers, member) &&
+						typedesc.IsEnumMemberPacked(typeDesc.EnumMembers, i) {
+						respace = true
+					}
 					member.Capability = descpb.TypeDescriptor_EnumMember_ALL
 					member.Direction = descpb.TypeDescriptor_EnumMember_NONE
 				}
 			}
 			// Next, deal with all the members that need to be removed from the slice.
-			applyFilterOnEnumMembers(typeDesc, func(member *descpb.TypeDescriptor_EnumMember) bool {
+			shouldRemove := func(member *descpb.TypeDescriptor_EnumMember) bool {
 				return t.isTransitioningInCurrentJob(member) && enumMemberIsRemoving(member)
-			})
+			}
+			removeEnumMembersFromRoleValueRestrictions(typeDesc, shouldRemove)
+			applyFilterOnEnumMembers(typeDesc, shouldRemove)
+			if respace {
+				if err := t.queueEnumRespacing(ctx, txn, typeDesc); err != nil {
+					return err
+				}
+			}
 
 			// We need to initialize the finalizer before we write the type descriptor.
 			// Otherwise, we run into a chicken and egg problem:
@@ -491,6 +610,22 @@ func (t *typeSchemaChanger) exec(ctx context.Context) error {
 		}
 	}
 
+	// Validate the stored values against the constraints that were added, now
+	// that all nodes enforce them on writes.
+	if len(t.validatingConstraints) != 0 && !typeDesc.Dropped() {
+		if err := t.validateTypeConstraints(ctx); err != nil {
+			return err
+		}
+	}
+
+	// Refresh any materialized views that were requested to be refreshed, now
+	// that all leases are on the new version of the type.
+	if len(t.refreshViewIDs) != 0 && !typeDesc.Dropped() {
+		if err := t.refreshMaterializedViews(ctx); err != nil {
+			return err
+		}
+	}
+
 	// If the type is being dropped, remove the descriptor here only
 	// if the declarative schema changer is not in use.
 	if typeDesc.Dropped() && typeDesc.GetDeclarativeSchemaChangerState() == nil {
@@ -507,6 +642,51 @@ func (t *typeSchemaChanger) exec(ctx context.Context) error {
 	return nil
 }
 
+// refreshMaterializedViews refreshes the materialized views in refreshViewIDs,
+// so that rows they store that depend on the old version of the type, such as
+// the string form of a renamed enum value, are recomputed. Views that have
+// since been dropped are skipped.
+func (t *typeSchemaChanger) refreshMaterializedViews(ctx context.Context) error {
+	for _, id := range t.refreshViewIDs {
+		var name tree.ObjectName
+		if err := DescsTxn(ctx, t.execCfg, func(ctx context.Context, txn isql.Txn, col *descs.Collection) error {
+			name = nil
+			desc, err := col.ByID(txn.KV()).Get().Table(ctx, id)
+			if err != nil {
+				if errors.Is(err, catalog.ErrDescriptorNotFound) {
+					return nil
+				}
+				return err
+			}
+			if desc.Dropped() || !desc.MaterializedView() {
+				return nil
+			}
+			name, err = descs.GetObjectName(ctx, txn.KV(), col, desc)
+			return err
+		}); err != nil {
+			return err
+		}
+		if name == nil {
+			log.Infof(ctx, "materialized view %d was dropped, skipping refresh", id)
+			continue
+		}
+		_, err := t.execCfg.InternalDB.Executor().ExecEx(
+			ctx, "refresh-materialized-view", nil /* txn */, sessiondata.NodeUserSessionDataOverride,
+			fmt.Sprintf("REFRESH MATERIALIZED VIEW %s", tree.AsString(name)),
+		)
+		// If the job is retried, a refresh queued by a previous attempt may still
+		// be running, which refreshes the view just as well.
+		if pgerror.GetPGCode(err) == pgcode.ObjectNotInPrerequisiteState {
+			log.Infof(ctx, "materialized view %d is already being refreshed: %v", id, err)
+			err = nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "could not refresh materialized view %s", tree.AsString(name))
+		}
+	}
+	return nil
+}
+
 // isTransitioningInCurrentJob returns true if the given member is either being
 // added or removed in the current job.
 func (t *typeSchemaChanger) isTransitioningInCurrentJob(
@@ -520,6 +700,52 @@ func (t *typeSchemaChanger) isTransitioningInCurrentJob(
 	return false
 }
 
+// queueEnumRespacing moves the values of the enum towards evenly spaced
+// physical representations with typeDesc.RespaceEnumValues, and queues a type
+// schema change job to rewrite the rows using them, like ALTER TYPE ...
+// COMPACT does. That job queues the next round once it completes. Nothing is
+// done if the setting is disabled, or if other values are still transitioning,
+// in which case the values are re-spaced once another value is added.
+func (t *typeSchemaChanger) queueEnumRespacing(
+	ctx context.Context, txn descs.Txn, typeDesc *typedesc.Mutable,
+) error {
+	if !respacePackedEnumValues.Get(&t.execCfg.Settings.SV) {
+		return nil
+	}
+	if err := checkEnumMembersSettled(typeDesc, "respacing"); err != nil {
+		log.Infof(ctx, "not respacing the values of type %d: %v", t.typeID, err)
+		return nil
+	}
+	respaced, remaining := typeDesc.RespaceEnumValues()
+	if len(respaced) == 0 {
+		return nil
+	}
+	var transitioningMembers [][]byte
+	for i := range typeDesc.EnumMembers {
+		if member := &typeDesc.EnumMembers[i]; member.Capability != descpb.TypeDescriptor_EnumMember_ALL {
+			transitioningMembers = append(transitioningMembers, member.PhysicalRepresentation)
+		}
+	}
+	record := jobs.Record{
+		JobID:         t.execCfg.JobRegistry.MakeJobID(),
+		Description:   fmt.Sprintf("respacing the values of type %s", typeDesc.Name),
+		Username:      username.NodeUserName(),
+		DescriptorIDs: descpb.IDs{typeDesc.ID},
+		Details: jobspb.TypeSchemaChangeDetails{
+			TypeID:               typeDesc.ID,
+			TransitioningMembers: transitioningMembers,
+			RespaceEnumValues:    true,
+		},
+		Progress: jobspb.TypeSchemaChangeProgress{},
+	}
+	if _, err := t.execCfg.JobRegistry.CreateAdoptableJobWithTxn(ctx, record, record.JobID, txn); err != nil {
+		return err
+	}
+	log.Infof(ctx, "queued job %d to respace %d values of type %d (%d remaining)",
+		record.JobID, len(respaced), t.typeID, remaining)
+	return nil
+}
+
 // applyFilterOnEnumMembers modifies the supplied typeDesc by removing all enum
 // members as dictated by shouldRemove.
 func applyFilterOnEnumMembers(
@@ -538,6 +764,52 @@ func applyFilterOnEnumMembers(
 	typeDesc.EnumMembers = typeDesc.EnumMembers[:idx]
 }
 
+// removeEnumMembersFromRoleValueRestrictions updates the restrictions on the
+// values that roles can write for the members of typeDesc that are about to be
+// removed, as dictated by shouldRemove. A member that is merged into a member
+// with the same logical representation, such as a member moved by ALTER TYPE
+// ... COMPACT, is replaced with that member. Other members are removed from
+// the restrictions, so that a member added later with the same physical
+// representation isn't allowed along with them.
+func removeEnumMembersFromRoleValueRestrictions(
+	typeDesc *typedesc.Mutable, shouldRemove func(member *descpb.TypeDescriptor_EnumMember) bool,
+) {
+	replacements := make(map[string][]byte)
+	for i := range typeDesc.EnumMembers {
+		member := &typeDesc.EnumMembers[i]
+		if !shouldRemove(member) {
+			continue
+		}
+		var replacement []byte
+		if typedesc.IsEnumMemberMerge(typeDesc.EnumMembers, member) {
+			replacement = member.ReplacementPhysicalRepresentation
+		}
+		replacements[string(member.PhysicalRepresentation)] = replacement
+	}
+	if len(replacements) == 0 {
+		return
+	}
+	for i := range typeDesc.RoleValueRestrictions {
+		restriction := &typeDesc.RoleValueRestrictions[i]
+		allowed := make([][]byte, 0, len(restriction.AllowedValues))
+		seen := make(map[string]struct{}, len(restriction.AllowedValues))
+		for _, val := range restriction.AllowedValues {
+			if replacement, ok := replacements[string(val)]; ok {
+				if replacement == nil {
+					continue
+				}
+				val = replacement
+			}
+			if _, ok := seen[string(val)]; ok {
+				continue
+			}
+			seen[string(val)] = struct{}{}
+			allowed = append(allowed, val)
+		}
+		restriction.AllowedValues = allowed
+	}
+}
+
 // cleanupEnumValues performs cleanup if any of the enum value transitions
 // fails. In particular:
 // 1. If an enum value was being added as part of this txn, we remove it
@@ -576,15 +848,29 @@ func (t *typeSchemaChanger) cleanupEnumValues(ctx context.Context) error {
 		for i := range typeDesc.EnumMembers {
 			member := &typeDesc.EnumMembers[i]
 			if t.isTransitioningInCurrentJob(member) && enumMemberIsRemoving(member) {
+				// Promoting a duplicate that was being merged would make the
+				// descriptor invalid again, and some of its rows may already have
+				// been rewritten. Leave it read-only so that the merge can be
+				// resumed by running ALTER TYPE ... DEDUP VALUES again. This
+				// also applies to members being compacted, unless their copy is
+				// still being added, in which case no rows have been rewritten
+				// and the copy is removed below.
+				if typedesc.IsEnumMemberMerge(typeDesc.EnumMembers, member) &&
+					!enumMemberReplacementIsAdding(typeDesc, member) {
+					continue
+				}
 				member.Capability = descpb.TypeDescriptor_EnumMember_ALL
 				member.Direction = descpb.TypeDescriptor_EnumMember_NONE
+				member.ReplacementPhysicalRepresentation = nil
 			}
 		}
 		// Now deal with all members that we initially hoped to add but now need
 		// to be removed from the descriptor.
-		applyFilterOnEnumMembers(typeDesc, func(member *descpb.TypeDescriptor_EnumMember) bool {
+		shouldRemove := func(member *descpb.TypeDescriptor_EnumMember) bool {
 			return t.isTransitioningInCurrentJob(member) && enumMemberIsAdding(member)
-		})
+		}
+		removeEnumMembersFromRoleValueRestrictions(typeDesc, shouldRemove)
+		applyFilterOnEnumMembers(typeDesc, shouldRemove)
 
 		if err := txn.Descriptors().WriteDesc(ctx, true /* kvTrace */, typeDesc, txn.KV()); err != nil {
 			return err
@@ -601,6 +887,205 @@ func (t *typeSchemaChanger) cleanupEnumValues(ctx context.Context) error {
 	return t.execCfg.InternalDB.DescsTxn(ctx, cleanup)
 }
 
+// promoteEnumCompactionCopies makes the copies added by ALTER TYPE ... COMPACT
+// and ALTER TYPE ... NORMALIZE REPRESENTATION for the members they move
+// writable. Rows using the moved members are rewritten to the copies, and the
+// moved members themselves are read-only, so this allows the values to be
+// written while the rows are being rewritten. It must only be called once all nodes are able to decode the
+// copies.
+func (t *typeSchemaChanger) promoteEnumCompactionCopies(ctx context.Context) error {
+	return t.execCfg.InternalDB.DescsTxn(ctx, func(ctx context.Context, txn descs.Txn) error {
+		typeDesc, err := txn.Descriptors().MutableByID(txn.KV()).Type(ctx, t.typeID)
+		if err != nil {
+			return err
+		}
+		promoted := false
+		for i := range typeDesc.EnumMembers {
+			member := &typeDesc.EnumMembers[i]
+			if t.isTransitioningInCurrentJob(member) &&
+				typedesc.IsEnumMemberCompactionCopy(typeDesc.EnumMembers, member) {
+				member.Capability = descpb.TypeDescriptor_EnumMember_ALL
+				member.Direction = descpb.TypeDescriptor_EnumMember_NONE
+				promoted = true
+			}
+		}
+		if !promoted {
+			return nil
+		}
+		const kvTrace = true
+		b := txn.KV().NewBatch()
+		if err := txn.Descriptors().WriteDescToBatch(ctx, kvTrace, typeDesc, b); err != nil {
+			return err
+		}
+		// Bump the version of the array type too, so that it picks up the
+		// change to the enum.
+		arrayTypeDesc, err := txn.Descriptors().MutableByID(txn.KV()).Type(ctx, typeDesc.ArrayTypeID)
+		if err != nil {
+			return err
+		}
+		if err := txn.Descriptors().WriteDescToBatch(ctx, kvTrace, arrayTypeDesc, b); err != nil {
+			return err
+		}
+		return txn.KV().Run(ctx, b)
+	})
+}
+
+// enumMemberReplacementIsAdding returns whether the member that the given
+// member is being replaced with is still being added.
+func enumMemberReplacementIsAdding(
+	typeDesc *typedesc.Mutable, member *descpb.TypeDescriptor_EnumMember,
+) bool {
+	for i := range typeDesc.EnumMembers {
+		if bytes.Equal(typeDesc.EnumMembers[i].PhysicalRepresentation, member.ReplacementPhysicalRepresentation) {
+			return enumMemberIsAdding(&typeDesc.EnumMembers[i])
+		}
+	}
+	return false
+}
+
+// enumValueRewriteBatchSize is the maximum number of rows rewritten per
+// transaction when replacing a dropped enum value.
+const enumValueRewriteBatchSize = 10000
+
+// rewriteReplacedEnumValues rewrites all rows that use an enum member being
+// dropped with ALTER TYPE ... DROP VALUE ... REPLACE WITH to use the
+// replacement member instead. Members being dropped are read-only, so no new
+// usages of them can be written while the rewrite is in progress. If there is
+// anything to rewrite, validate is called before any rows are rewritten. Rows
+// are rewritten in batches, and the job's progress is updated after each
+// column.
+func (t *typeSchemaChanger) rewriteReplacedEnumValues(
+	ctx context.Context, validate func(context.Context) error,
+) error {
+	type rewriteTarget struct {
+		tableID descpb.ID
+		colName tree.Name
+		isArray bool
+	}
+	var members []descpb.TypeDescriptor_EnumMember
+	var targets []rewriteTarget
+	var override sessiondata.InternalExecutorOverride
+	var typeOID oid.Oid
+	if err := t.execCfg.InternalDB.DescsTxn(ctx, func(ctx context.Context, txn descs.Txn) error {
+		members, targets = nil, nil
+		typeDesc, err := txn.Descriptors().MutableByID(txn.KV()).Type(ctx, t.typeID)
+		if err != nil {
+			return err
+		}
+		for _, member := range typeDesc.EnumMembers {
+			if t.isTransitioningInCurrentJob(&member) && enumMemberIsRemoving(&member) &&
+				member.ReplacementPhysicalRepresentation != nil {
+				members = append(members, member)
+			}
+		}
+		if len(members) == 0 {
+			return nil
+		}
+		dbDesc, err := txn.Descriptors().ByID(txn.KV()).WithoutNonPublic().Get().Database(ctx, typeDesc.ParentID)
+		if err != nil {
+			return err
+		}
+		override = sessiondata.InternalExecutorOverride{
+			User:     username.NodeUserName(),
+			Database: dbDesc.GetName(),
+		}
+		typeOID = catid.TypeIDToOID(typeDesc.ID)
+
+		collectTargets := func(referencingIDs []descpb.ID, typeID descpb.ID, isArray bool) error {
+			for _, id := range referencingIDs {
+				desc, err := txn.Descriptors().ByID(txn.KV()).WithoutNonPublic().Get().Table(ctx, id)
+				if err != nil {
+					return err
+				}
+				if desc.IsView() {
+					continue
+				}
+				for _, col := range desc.PublicColumns() {
+					// Computed columns can't be written to directly. Any remaining
+					// usages in them are reported when the drop is validated.
+					if col.IsComputed() || !col.GetType().UserDefined() {
+						continue
+					}
+					if typedesc.GetUserDefinedTypeDescID(col.GetType()) == typeID {
+						targets = append(targets, rewriteTarget{
+							tableID: id, colName: col.ColName(), isArray: isArray,
+						})
+					}
+				}
+			}
+			return nil
+		}
+		if err := collectTargets(typeDesc.ReferencingDescriptorIDs, typeDesc.ID, false /* isArray */); err != nil {
+			return err
+		}
+		arrayTypeDesc, err := txn.Descriptors().ByID(txn.KV()).WithoutNonPublic().Get().Type(ctx, typeDesc.ArrayTypeID)
+		if err != nil {
+			return err
+		}
+		arrayReferencingIDs := make([]descpb.ID, arrayTypeDesc.NumReferencingDescriptors())
+		for i := range arrayReferencingIDs {
+			arrayReferencingIDs[i] = arrayTypeDesc.GetReferencingDescriptorID(i)
+		}
+		return collectTargets(arrayReferencingIDs, arrayTypeDesc.GetID(), true /* isArray */)
+	}); err != nil {
+		return err
+	}
+	if len(members) == 0 {
+		return nil
+	}
+	if err := validate(ctx); err != nil {
+		return err
+	}
+
+	for i, target := range targets {
+		for j := range members {
+			member := &members[j]
+			oldRep, err := convertToSQLStringRepresentation(member.PhysicalRepresentation)
+			if err != nil {
+				return err
+			}
+			newRep, err := convertToSQLStringRepresentation(member.ReplacementPhysicalRepresentation)
+			if err != nil {
+				return err
+			}
+			col := target.colName.String()
+			var stmt string
+			if target.isArray {
+				stmt = fmt.Sprintf(
+					"UPDATE [%d AS t] SET %s = array_replace(%s, %s::@%d, %s::@%d) WHERE %s::@%d = ANY (%s) LIMIT %d",
+					target.tableID, col, col, oldRep, typeOID, newRep, typeOID, oldRep, typeOID, col,
+					enumValueRewriteBatchSize,
+				)
+			} else {
+				stmt = fmt.Sprintf(
+					"UPDATE [%d AS t] SET %s = %s WHERE %s = %s LIMIT %d",
+					target.tableID, col, newRep, col, oldRep, enumValueRewriteBatchSize,
+				)
+			}
+			for {
+				var rowsAffected int
+				if err := t.execCfg.InternalDB.Txn(ctx, func(ctx context.Context, txn isql.Txn) (err error) {
+					rowsAffected, err = txn.ExecEx(ctx, "rewrite-enum-value", txn.KV(), override, stmt)
+					return err
+				}); err != nil {
+					return errors.Wrapf(err, "could not rewrite enum value %q", member.LogicalRepresentation)
+				}
+				if rowsAffected < enumValueRewriteBatchSize {
+					break
+				}
+			}
+		}
+		if t.job != nil {
+			if err := t.job.NoTxn().FractionProgressed(
+				ctx, jobs.FractionUpdater(float32(i+1)/float32(len(targets))),
+			); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // convertToSQLStringRepresentation takes an array of bytes (the physical
 // representation of an enum) and converts it into a string that can be used
 // in a SQL predicate.
@@ -756,207 +1241,244 @@ func (t *typeSchemaChanger) canRemoveEnumValue(
 	txn isql.Txn,
 	member *descpb.TypeDescriptor_EnumMember,
 	descsCol *descs.Collection,
+	checkRowUsages bool,
 ) error {
+	// Views and expressions refer to enum values by their logical
+	// representation, which a member that is being merged shares with the
+	// member it is merged into. They keep working once the member is removed,
+	// so only rows and partitioning, which use the physical representation,
+	// need to be checked for it.
+	isMerge := typedesc.IsEnumMemberMerge(typeDesc.EnumMembers, member)
 	for _, ID := range typeDesc.ReferencingDescriptorIDs {
 		desc, err := descsCol.ByID(txn.KV()).WithoutNonPublic().Get().Table(ctx, ID)
 		if err != nil {
 			return errors.Wrapf(err,
 				"could not validate enum value removal for %q", member.LogicalRepresentation)
 		}
-		if desc.IsView() {
-			foundUsage, err := findUsagesOfEnumValueInViewQuery(desc.GetViewQuery(), member, typeDesc.ID)
-			if err != nil {
-				return err
-			}
-			if foundUsage {
-				return pgerror.Newf(pgcode.DependentObjectsStillExist,
-					"could not remove enum value %q as it is being used in view %q",
-					member.LogicalRepresentation, desc.GetName())
-			}
+		if err := t.canRemoveEnumValueFromTable(
+			ctx, typeDesc, desc, txn, member, descsCol, checkRowUsages, isMerge,
+		); err != nil {
+			return err
 		}
+	}
 
-		var query strings.Builder
-		colSelectors := tabledesc.ColumnsSelectors(desc.PublicColumns())
-		columns := tree.AsStringWithFlags(&colSelectors, tree.FmtSerializable)
-		query.WriteString(fmt.Sprintf("SELECT %s FROM [%d as t] WHERE", columns, ID))
-		firstClause := true
-		validationQueryConstructed := false
-
-		// Note that we examine all indexes as opposed to non-drop indexes so we
-		// do not remove a partitioning value which is in use on an index which
-		// is in the process of being dropped but gets re-added due to a failure
-		// in that schema change.
-		for _, idx := range desc.AllIndexes() {
-			if pred := idx.GetPredicate(); pred != "" {
-				foundUsage, err := findUsagesOfEnumValue(pred, member, typeDesc.ID)
-				if err != nil {
-					return err
-				}
-				if foundUsage {
-					return pgerror.Newf(pgcode.DependentObjectsStillExist,
-						"could not remove enum value %q as it is being used in a predicate of index %s",
-						member.LogicalRepresentation, &tree.TableIndexName{
-							Table: tree.MakeUnqualifiedTableName(tree.Name(desc.GetName())),
-							Index: tree.UnrestrictedName(idx.GetName()),
-						})
-				}
-			}
-			keyColumns := make([]catalog.Column, 0, idx.NumKeyColumns())
-			for i := 0; i < idx.NumKeyColumns(); i++ {
-				col, err := catalog.MustFindColumnByID(desc, idx.GetKeyColumnID(i))
-				if err != nil {
-					return errors.WithAssertionFailure(err)
-				}
-				keyColumns = append(keyColumns, col)
-			}
-			foundUsage, err := findUsagesOfEnumValueInPartitioning(
-				idx.GetPartitioning(), t.execCfg.Codec, keyColumns, desc, idx, member, nil, typeDesc,
-			)
+	if !checkRowUsages {
+		return nil
+	}
+
+	// Do validation for the array type now.
+	arrayTypeDesc, err := descsCol.ByIDWithLeased(txn.KV()).WithoutNonPublic().Get().Type(ctx, typeDesc.ArrayTypeID)
+	if err != nil {
+		return err
+	}
+
+	return t.canRemoveEnumValueFromArrayUsages(ctx, arrayTypeDesc, member, txn, descsCol)
+}
+
+// canRemoveEnumValueFromTable returns an error if the enum value is in use by
+// the given table or view, which refers to the type, and therefore can't be
+// removed. isMerge indicates that the member is being merged into another
+// member with the same logical representation.
+func (t *typeSchemaChanger) canRemoveEnumValueFromTable(
+	ctx context.Context,
+	typeDesc *typedesc.Mutable,
+	desc catalog.TableDescriptor,
+	txn isql.Txn,
+	member *descpb.TypeDescriptor_EnumMember,
+	descsCol *descs.Collection,
+	checkRowUsages bool,
+	isMerge bool,
+) error {
+	id := desc.GetID()
+	if desc.IsView() && !isMerge {
+		foundUsage, err := findUsagesOfEnumValueInViewQuery(desc.GetViewQuery(), member, typeDesc.ID)
+		if err != nil {
+			return err
+		}
+		if foundUsage {
+			return pgerror.Newf(pgcode.DependentObjectsStillExist,
+				"could not remove enum value %q as it is being used in view %q",
+				member.LogicalRepresentation, desc.GetName())
+		}
+	}
+
+	var query strings.Builder
+	colSelectors := tabledesc.ColumnsSelectors(desc.PublicColumns())
+	columns := tree.AsStringWithFlags(&colSelectors, tree.FmtSerializable)
+	query.WriteString(fmt.Sprintf("SELECT %s FROM [%d as t] WHERE", columns, id))
+	firstClause := true
+	validationQueryConstructed := false
+
+	// Note that we examine all indexes as opposed to non-drop indexes so we
+	// do not remove a partitioning value which is in use on an index which
+	// is in the process of being dropped but gets re-added due to a failure
+	// in that schema change.
+	for _, idx := range desc.AllIndexes() {
+		if pred := idx.GetPredicate(); pred != "" && !isMerge {
+			foundUsage, err := findUsagesOfEnumValue(pred, member, typeDesc.ID)
 			if err != nil {
 				return err
 			}
 			if foundUsage {
 				return pgerror.Newf(pgcode.DependentObjectsStillExist,
-					"could not remove enum value %q as it is being used in the partitioning of index %s",
+					"could not remove enum value %q as it is being used in a predicate of index %s",
 					member.LogicalRepresentation, &tree.TableIndexName{
 						Table: tree.MakeUnqualifiedTableName(tree.Name(desc.GetName())),
 						Index: tree.UnrestrictedName(idx.GetName()),
 					})
 			}
 		}
+		keyColumns := make([]catalog.Column, 0, idx.NumKeyColumns())
+		for i := 0; i < idx.NumKeyColumns(); i++ {
+			col, err := catalog.MustFindColumnByID(desc, idx.GetKeyColumnID(i))
+			if err != nil {
+				return errors.WithAssertionFailure(err)
+			}
+			keyColumns = append(keyColumns, col)
+		}
+		foundUsage, err := findUsagesOfEnumValueInPartitioning(
+			idx.GetPartitioning(), t.execCfg.Codec, keyColumns, desc, idx, member, nil, typeDesc,
+		)
+		if err != nil {
+			return err
+		}
+		if foundUsage {
+			return pgerror.Newf(pgcode.DependentObjectsStillExist,
+				"could not remove enum value %q as it is being used in the partitioning of index %s",
+				member.LogicalRepresentation, &tree.TableIndexName{
+					Table: tree.MakeUnqualifiedTableName(tree.Name(desc.GetName())),
+					Index: tree.UnrestrictedName(idx.GetName()),
+				})
+		}
+	}
+
+	// Examine all check constraints.
+	for _, chk := range desc.CheckConstraints() {
+		if isMerge {
+			break
+		}
+		foundUsage, err := findUsagesOfEnumValue(chk.GetExpr(), member, typeDesc.ID)
+		if err != nil {
+			return err
+		}
+		if foundUsage {
+			return pgerror.Newf(pgcode.DependentObjectsStillExist,
+				"could not remove enum value %q as it is being used in a check constraint of %q",
+				member.LogicalRepresentation, desc.GetName())
+		}
+	}
 
-		// Examine all check constraints.
-		for _, chk := range desc.CheckConstraints() {
-			foundUsage, err := findUsagesOfEnumValue(chk.GetExpr(), member, typeDesc.ID)
+	for _, col := range desc.PublicColumns() {
+		// If this column has a default expression, check if it uses the enum member being dropped.
+		if col.HasDefault() && !isMerge {
+			foundUsage, err := findUsagesOfEnumValue(col.GetDefaultExpr(), member, typeDesc.ID)
 			if err != nil {
 				return err
 			}
 			if foundUsage {
 				return pgerror.Newf(pgcode.DependentObjectsStillExist,
-					"could not remove enum value %q as it is being used in a check constraint of %q",
+					"could not remove enum value %q as it is being used in a default expresion of %q",
 					member.LogicalRepresentation, desc.GetName())
 			}
 		}
 
-		for _, col := range desc.PublicColumns() {
-			// If this column has a default expression, check if it uses the enum member being dropped.
-			if col.HasDefault() {
-				foundUsage, err := findUsagesOfEnumValue(col.GetDefaultExpr(), member, typeDesc.ID)
-				if err != nil {
-					return err
-				}
-				if foundUsage {
-					return pgerror.Newf(pgcode.DependentObjectsStillExist,
-						"could not remove enum value %q as it is being used in a default expresion of %q",
-						member.LogicalRepresentation, desc.GetName())
-				}
-			}
-
-			// If this column is computed, check if it uses the enum member being dropped.
-			if col.IsComputed() {
-				foundUsage, err := findUsagesOfEnumValue(col.GetComputeExpr(), member, typeDesc.ID)
-				if err != nil {
-					return err
-				}
-				if foundUsage {
-					return pgerror.Newf(pgcode.DependentObjectsStillExist,
-						"could not remove enum value %q as it is being used in a computed column of %q",
-						member.LogicalRepresentation, desc.GetName())
-				}
-			}
-
-			// If this column has an ON UPDATE expression, check if it uses the enum
-			// member being dropped.
-			if col.HasOnUpdate() {
-				foundUsage, err := findUsagesOfEnumValue(col.GetOnUpdateExpr(), member, typeDesc.ID)
-				if err != nil {
-					return err
-				}
-				if foundUsage {
-					return pgerror.Newf(pgcode.DependentObjectsStillExist,
-						"could not remove enum value %q as it is being used in an ON UPDATE expression"+
-							" of %q",
-						member.LogicalRepresentation, desc.GetName())
-				}
-			}
-
-			if col.GetType().UserDefined() {
-				tid := typedesc.GetUserDefinedTypeDescID(col.GetType())
-				if typeDesc.ID == tid {
-					if !firstClause {
-						query.WriteString(" OR")
-					}
-					sqlPhysRep, err := convertToSQLStringRepresentation(member.PhysicalRepresentation)
-					if err != nil {
-						return err
-					}
-					colName := col.ColName()
-					query.WriteString(fmt.Sprintf(
-						" t.%s = %s",
-						colName.String(),
-						sqlPhysRep,
-					))
-					firstClause = false
-					validationQueryConstructed = true
-				}
-			}
-		}
-		query.WriteString(" LIMIT 1")
-
-		// NB: A type descriptor reference does not imply at-least one column in the
-		// table is of the type whose value is being removed. The notable exception
-		// being REGIONAL BY TABLE multi-region tables. In this case, no valid query
-		// is constructed and there's nothing to execute. Instead, their validation
-		// is handled as a special case below.
-		if validationQueryConstructed {
-			// We need to override the internal executor's current database (which would
-			// be unset by default) when executing the query constructed above. This is
-			// because the enum value may be used in a view expression, which is
-			// name resolved in the context of the type's database.
-			dbDesc, err := descsCol.ByID(txn.KV()).WithoutNonPublic().Get().Database(ctx, typeDesc.ParentID)
-			const validationErr = "could not validate removal of enum value %q"
-			if err != nil {
-				return errors.Wrapf(err, validationErr, member.LogicalRepresentation)
-			}
-			override := sessiondata.InternalExecutorOverride{
-				User:     username.NodeUserName(),
-				Database: dbDesc.GetName(),
-			}
-			rows, err := txn.QueryRowEx(ctx, "count-value-usage", txn.KV(), override, query.String())
+		// If this column is computed, check if it uses the enum member being dropped.
+		if col.IsComputed() && !isMerge {
+			foundUsage, err := findUsagesOfEnumValue(col.GetComputeExpr(), member, typeDesc.ID)
 			if err != nil {
-				return errors.Wrapf(err, validationErr, member.LogicalRepresentation)
+				return err
 			}
-			// Check if the above query returned a result. If it did, then the
-			// enum value is being used by some place.
-			if len(rows) > 0 {
+			if foundUsage {
 				return pgerror.Newf(pgcode.DependentObjectsStillExist,
-					"could not remove enum value %q as it is being used by %q in row: %s",
-					member.LogicalRepresentation, desc.GetName(), labeledRowValues(desc.PublicColumns(), rows))
+					"could not remove enum value %q as it is being used in a computed column of %q",
+					member.LogicalRepresentation, desc.GetName())
 			}
 		}
 
-		// If the type descriptor is a multi-region enum and the table descriptor
-		// belongs to a regional (by table) table, we disallow dropping the region
-		// if it is being used as the homed region for that table.
-		if typeDesc.Kind == descpb.TypeDescriptor_MULTIREGION_ENUM && desc.IsLocalityRegionalByTable() {
-			homedRegion, err := desc.GetRegionalByTableRegion()
+		// If this column has an ON UPDATE expression, check if it uses the enum
+		// member being dropped.
+		if col.HasOnUpdate() && !isMerge {
+			foundUsage, err := findUsagesOfEnumValue(col.GetOnUpdateExpr(), member, typeDesc.ID)
 			if err != nil {
 				return err
 			}
-			if catpb.RegionName(member.LogicalRepresentation) == homedRegion {
-				return errors.Newf("could not remove enum value %q as it is the home region for table %q",
+			if foundUsage {
+				return pgerror.Newf(pgcode.DependentObjectsStillExist,
+					"could not remove enum value %q as it is being used in an ON UPDATE expression"+
+						" of %q",
 					member.LogicalRepresentation, desc.GetName())
 			}
 		}
-	}
 
-	// Do validation for the array type now.
-	arrayTypeDesc, err := descsCol.ByIDWithLeased(txn.KV()).WithoutNonPublic().Get().Type(ctx, typeDesc.ArrayTypeID)
-	if err != nil {
-		return err
+		if col.GetType().UserDefined() {
+			tid := typedesc.GetUserDefinedTypeDescID(col.GetType())
+			if typeDesc.ID == tid {
+				if !firstClause {
+					query.WriteString(" OR")
+				}
+				sqlPhysRep, err := convertToSQLStringRepresentation(member.PhysicalRepresentation)
+				if err != nil {
+					return err
+				}
+				colName := col.ColName()
+				query.WriteString(fmt.Sprintf(
+					" t.%s = %s",
+					colName.String(),
+					sqlPhysRep,
+				))
+				firstClause = false
+				validationQueryConstructed = true
+			}
+		}
+	}
+	query.WriteString(" LIMIT 1")
+
+	// NB: A type descriptor reference does not imply at-least one column in the
+	// table is of the type whose value is being removed. The notable exception
+	// being REGIONAL BY TABLE multi-region tables. In this case, no valid query
+	// is constructed and there's nothing to execute. Instead, their validation
+	// is handled as a special case below.
+	if validationQueryConstructed && checkRowUsages {
+		// We need to override the internal executor's current database (which would
+		// be unset by default) when executing the query constructed above. This is
+		// because the enum value may be used in a view expression, which is
+		// name resolved in the context of the type's database.
+		dbDesc, err := descsCol.ByID(txn.KV()).WithoutNonPublic().Get().Database(ctx, typeDesc.ParentID)
+		const validationErr = "could not validate removal of enum value %q"
+		if err != nil {
+			return errors.Wrapf(err, validationErr, member.LogicalRepresentation)
+		}
+		override := sessiondata.InternalExecutorOverride{
+			User:     username.NodeUserName(),
+			Database: dbDesc.GetName(),
+		}
+		rows, err := txn.QueryRowEx(ctx, "count-value-usage", txn.KV(), override, query.String())
+		if err != nil {
+			return errors.Wrapf(err, validationErr, member.LogicalRepresentation)
+		}
+		// Check if the above query returned a result. If it did, then the
+		// enum value is being used by some place.
+		if len(rows) > 0 {
+			return pgerror.Newf(pgcode.DependentObjectsStillExist,
+				"could not remove enum value %q as it is being used by %q in row: %s",
+				member.LogicalRepresentation, desc.GetName(), labeledRowValues(desc.PublicColumns(), rows))
+		}
 	}
 
-	return t.canRemoveEnumValueFromArrayUsages(ctx, arrayTypeDesc, member, txn, descsCol)
+	// If the type descriptor is a multi-region enum and the table descriptor
+	// belongs to a regional (by table) table, we disallow dropping the region
+	// if it is being used as the homed region for that table.
+	if typeDesc.Kind == descpb.TypeDescriptor_MULTIREGION_ENUM && desc.IsLocalityRegionalByTable() {
+		homedRegion, err := desc.GetRegionalByTableRegion()
+		if err != nil {
+			return err
+		}
+		if catpb.RegionName(member.LogicalRepresentation) == homedRegion {
+			return errors.Newf("could not remove enum value %q as it is the home region for table %q",
+				member.LogicalRepresentation, desc.GetName())
+		}
+	}
+	return nil
 }
 
 // findUsagesOfEnumValueInPartitioning is a recursive function to explore all of
@@ -1261,9 +1783,13 @@ func (t *typeChangeResumer) Resume(ctx context.Context, execCtx interface{}) err
 		}
 	}
 	tc := &typeSchemaChanger{
-		typeID:               t.job.Details().(jobspb.TypeSchemaChangeDetails).TypeID,
-		transitioningMembers: t.job.Details().(jobspb.TypeSchemaChangeDetails).TransitioningMembers,
-		execCfg:              p.ExecCfg(),
+		typeID:                t.job.Details().(jobspb.TypeSchemaChangeDetails).TypeID,
+		transitioningMembers:  t.job.Details().(jobspb.TypeSchemaChangeDetails).TransitioningMembers,
+		refreshViewIDs:        t.job.Details().(jobspb.TypeSchemaChangeDetails).RefreshViewIDs,
+		respaceEnumValues:     t.job.Details().(jobspb.TypeSchemaChangeDetails).RespaceEnumValues,
+		validatingConstraints: t.job.Details().(jobspb.TypeSchemaChangeDetails).ValidatingConstraints,
+		execCfg:               p.ExecCfg(),
+		job:                   t.job,
 	}
 	return tc.execWithRetry(ctx)
 }
@@ -1274,15 +1800,19 @@ func (t *typeChangeResumer) OnFailOrCancel(
 ) error {
 	// If the job failed, just try again to clean up any draining names.
 	tc := &typeSchemaChanger{
-		typeID:               t.job.Details().(jobspb.TypeSchemaChangeDetails).TypeID,
-		transitioningMembers: t.job.Details().(jobspb.TypeSchemaChangeDetails).TransitioningMembers,
-		execCfg:              execCtx.(JobExecContext).ExecCfg(),
+		typeID:                t.job.Details().(jobspb.TypeSchemaChangeDetails).TypeID,
+		transitioningMembers:  t.job.Details().(jobspb.TypeSchemaChangeDetails).TransitioningMembers,
+		validatingConstraints: t.job.Details().(jobspb.TypeSchemaChangeDetails).ValidatingConstraints,
+		execCfg:               execCtx.(JobExecContext).ExecCfg(),
 	}
 
 	if rollbackErr := func() error {
 		if err := tc.cleanupEnumValues(ctx); err != nil {
 			return err
 		}
+		if err := tc.cleanupTypeConstraints(ctx); err != nil {
+			return err
+		}
 
 		if fn := tc.execCfg.TypeSchemaChangerTestingKnobs.RunAfterOnFailOrCancel; fn != nil {
 			return fn()
@@ -12,18 +12,25 @@ package sql_test
 
 import (
 	"context"
+	gosql "database/sql"
+	"encoding/hex"
 	"fmt"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/enum"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/errors"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 )
 
@@ -270,12 +277,13 @@ COMMIT`,
 
 // Simulates the following scenario:
 // - We have an enum, which starts out with values 'a' and 'b'.
-// - We drop 'a' and add 'c' in job 1, which is slow, and finishes after job 2.
-// - We drop 'b' and add 'd' in job 2, which is fast, and finishes before job 1.
+// - We add 'c' in job 1, which is slow, and finishes after job 2.
+// - We add 'd' in job 2, which is fast, and finishes before job 1.
 // - job 2 fails due to an error, triggering a rollback.
 // This test ensures that roll back is isolated to just the enum labels the
-// job was responsible for acting upon. This is to say that 'a' should be
-// unaffected by job 2 failing and should be successfully removed.
+// job was responsible for acting upon. This is to say that 'c' should be
+// unaffected by job 2 failing and should be successfully added. Only values
+// can be added concurrently, see TestAlterTypeRejectsConcurrentSchemaChange.
 func TestEnumMemberTransitionIsolation(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -302,8 +310,8 @@ func TestEnumMemberTransitionIsolation(t *testing.T) {
 	}
 	s, sqlDB, _ := serverutils.StartServer(t, params)
 
-	droppingAFinished := make(chan struct{})
-	droppingBFinished := make(chan struct{})
+	addingCFinished := make(chan struct{})
+	addingDFinished := make(chan struct{})
 
 	ctx := context.Background()
 	defer s.Stopper().Stop(ctx)
@@ -316,15 +324,15 @@ CREATE TYPE ab AS ENUM ('a', 'b')`,
 	}
 
 	go func() {
-		_, err := sqlDB.Exec(`BEGIN; ALTER TYPE ab DROP VALUE 'a'; ALTER TYPE ab ADD VALUE 'c'; COMMIT`)
+		_, err := sqlDB.Exec(`ALTER TYPE ab ADD VALUE 'c'`)
 		if err != nil {
 			t.Error(err)
 		}
-		close(droppingAFinished)
+		close(addingCFinished)
 	}()
 
 	go func() {
-		// Only try dropping 'b' once the previous function (dropping 'a') is
+		// Only try adding 'd' once the previous function (adding 'c') is
 		// blocking.
 		for {
 			mu.Lock()
@@ -334,48 +342,125 @@ CREATE TYPE ab AS ENUM ('a', 'b')`,
 			}
 			mu.Unlock()
 		}
-		_, err := sqlDB.Exec(`BEGIN; ALTER TYPE ab DROP VALUE 'b'; ALTER TYPE ab ADD VALUE 'd'; COMMIT`)
+		_, err := sqlDB.Exec(`ALTER TYPE ab ADD VALUE 'd'`)
 		if err == nil {
 			t.Error("expected error, found nil")
 		}
 		if !testutils.IsError(err, "boom") {
 			t.Errorf("expected boom, found %v", err)
 		}
-		// Unblock the job to drop 'a'.
+		// Unblock the job to add 'c'.
 		close(unblocker)
-		close(droppingBFinished)
+		close(addingDFinished)
 	}()
 
 	// Ensure both the functions above have finished running before proceeding to
 	// check the effects.
-	<-droppingAFinished
-	<-droppingBFinished
+	<-addingCFinished
+	<-addingDFinished
 
-	// 'b' should not have been dropped, as this job was forced to roll back.
-	if _, err := sqlDB.Exec(`SELECT 'b'::ab `); err != nil {
-		t.Fatal(err)
-	}
 	// 'd' should not have been added, as the job was forced to roll back.
 	_, err := sqlDB.Exec(`SELECT 'd'::ab`)
 	if err == nil {
 		t.Fatal("expected error, found nil")
 	}
 	if !testutils.IsError(err, `invalid input value for enum ab: "d"`) {
-		t.Fatalf(`expected invalid input value for enum ab: "a", found %v`, err)
+		t.Fatalf(`expected invalid input value for enum ab: "d", found %v`, err)
 	}
 
-	// 'a' was dropped in independent to and in a separate job to 'b', so we expect
+	// 'c' was added independently of and in a separate job to 'd', so we expect
 	// the effects to be isolated.
-	_, err = sqlDB.Exec(`SELECT 'a'::ab`)
-	if err == nil {
-		t.Fatal("expected error, found nil")
+	for _, val := range []string{"a", "b", "c"} {
+		if _, err := sqlDB.Exec(fmt.Sprintf(`SELECT '%s'::ab`, val)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestAlterTypeRejectsConcurrentSchemaChange ensures that ALTER TYPE fails
+// up front while a schema change that another transaction started on the type
+// is still in progress, except for adding values while other values are being
+// added.
+func TestAlterTypeRejectsConcurrentSchemaChange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	params, _ := createTestServerParams()
+	// Protects blockNext.
+	var mu syncutil.Mutex
+	var blockNext chan struct{}
+	blocked := make(chan struct{})
+	params.Knobs.SQLTypeSchemaChanger = &sql.TypeSchemaChangerTestingKnobs{
+		RunBeforeExec: func() error {
+			mu.Lock()
+			unblock := blockNext
+			blockNext = nil
+			mu.Unlock()
+			if unblock != nil {
+				blocked <- struct{}{}
+				<-unblock
+			}
+			return nil
+		},
 	}
-	if !testutils.IsError(err, `invalid input value for enum ab: "a"`) {
-		t.Fatalf(`expected invalid input value for enum ab: "a", found %v`, err)
+	s, sqlDB, _ := serverutils.StartServer(t, params)
+	ctx := context.Background()
+	defer s.Stopper().Stop(ctx)
+
+	_, err := sqlDB.Exec(`CREATE TYPE ab AS ENUM ('a', 'b')`)
+	require.NoError(t, err)
+
+	// runBlocked runs the statement, whose job blocks until the returned
+	// function is called, which waits for the statement to finish.
+	runBlocked := func(stmt string) (finish func()) {
+		unblock := make(chan struct{})
+		mu.Lock()
+		blockNext = unblock
+		mu.Unlock()
+		errCh := make(chan error, 1)
+		go func() {
+			_, err := sqlDB.Exec(stmt)
+			errCh <- err
+		}()
+		<-blocked
+		return func() {
+			close(unblock)
+			require.NoError(t, <-errCh)
+		}
 	}
-	if _, err := sqlDB.Exec(`SELECT 'c'::ab`); err != nil {
-		t.Fatal(err)
+	requireRejected := func(stmt string, detail string) {
+		_, err := sqlDB.Exec(stmt)
+		var pqErr *pq.Error
+		require.True(t, errors.As(err, &pqErr), "%s: %v", stmt, err)
+		require.Equal(t, pgcode.ObjectNotInPrerequisiteState.String(), string(pqErr.Code), "%s: %v", stmt, err)
+		require.Equal(t, `type "ab" has a schema change in progress, try again later`, pqErr.Message)
+		require.Equal(t, detail, pqErr.Detail)
 	}
+
+	finish := runBlocked(`ALTER TYPE ab DROP VALUE 'a'`)
+	for _, stmt := range []string{
+		`ALTER TYPE ab DROP VALUE 'b'`,
+		`ALTER TYPE ab ADD VALUE 'c'`,
+		`ALTER TYPE ab RENAME VALUE 'b' TO 'c'`,
+		`ALTER TYPE ab RENAME TO ab2`,
+	} {
+		requireRejected(stmt, `enum value "a" is being dropped`)
+	}
+	// Reading the type is still allowed.
+	_, err = sqlDB.Exec(`ALTER TYPE ab CHECK`)
+	require.NoError(t, err)
+	finish()
+
+	finish = runBlocked(`ALTER TYPE ab ADD VALUE 'c'`)
+	requireRejected(`ALTER TYPE ab DROP VALUE 'b'`, `enum value "c" is being added`)
+	// Values can be added while other values are being added.
+	_, err = sqlDB.Exec(`ALTER TYPE ab ADD VALUE 'd'`)
+	require.NoError(t, err)
+	finish()
+
+	var values string
+	require.NoError(t, sqlDB.QueryRow(`SELECT enum_range(NULL::ab)::STRING`).Scan(&values))
+	require.Equal(t, "{b,c,d}", values)
 }
 
 // TestTypeChangeJobCancelSemantics ensures that type change jobs that involve
@@ -503,3 +588,235 @@ WHERE
 		})
 	}
 }
+
+// TestAddEnumValueIfNotExistsConcurrentAdd ensures that ALTER TYPE ... ADD
+// VALUE IF NOT EXISTS is a no-op rather than an error or a duplicate member if
+// the value is added concurrently, after the statement resolved the type but
+// before it checks whether the value exists.
+func TestAddEnumValueIfNotExistsConcurrentAdd(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	var sqlDB *gosql.DB
+	// Protects added and calls.
+	var mu syncutil.Mutex
+	added := false
+	calls := 0
+	params, _ := createTestServerParams()
+	params.Knobs.SQLTypeSchemaChanger = &sql.TypeSchemaChangerTestingKnobs{
+		RunBeforeAddEnumValue: func(ctx context.Context, _ descpb.ID) error {
+			mu.Lock()
+			calls++
+			first := !added
+			added = true
+			mu.Unlock()
+			// Add the value from another connection the first time around. The
+			// concurrent statement runs this knob too.
+			if !first {
+				return nil
+			}
+			_, err := sqlDB.Exec(`ALTER TYPE d.t ADD VALUE 'b'`)
+			return err
+		},
+	}
+	// Decrease the adopt loop interval so that the jobs finish quickly.
+	params.Knobs.JobsTestingKnobs = jobs.NewTestingKnobsWithShortIntervals()
+
+	var s serverutils.TestServerInterface
+	s, sqlDB, _ = serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+
+	_, err := sqlDB.Exec(`
+CREATE DATABASE d;
+CREATE TYPE d.t AS ENUM('a');
+`)
+	require.NoError(t, err)
+
+	// The statement itself is the first to run the knob, which adds the value
+	// concurrently. It is then retried and finds the value.
+	_, err = sqlDB.Exec(`ALTER TYPE d.t ADD VALUE IF NOT EXISTS 'b'`)
+	require.NoError(t, err)
+
+	mu.Lock()
+	require.GreaterOrEqual(t, calls, 3)
+	mu.Unlock()
+
+	var values string
+	require.NoError(t, sqlDB.QueryRow(`SELECT enum_range(NULL::d.t)::STRING`).Scan(&values))
+	require.Equal(t, "{a,b}", values)
+
+	// Without IF NOT EXISTS, the value is a duplicate.
+	_, err = sqlDB.Exec(`ALTER TYPE d.t ADD VALUE 'b'`)
+	require.True(t, testutils.IsError(err, `enum value "b" already exists`), "%v", err)
+}
+
+// TestAddEnumValueConcurrentAddRetriesTransparently ensures that an implicit
+// transaction running ALTER TYPE ... ADD VALUE that conflicts with a
+// concurrent ADD VALUE of a different value on the same type is retried
+// transparently, and that both values end up in the type.
+func TestAddEnumValueConcurrentAddRetriesTransparently(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	var sqlDB *gosql.DB
+	// Protects added and calls.
+	var mu syncutil.Mutex
+	added := false
+	calls := 0
+	params, _ := createTestServerParams()
+	params.Knobs.SQLTypeSchemaChanger = &sql.TypeSchemaChangerTestingKnobs{
+		RunBeforeAddEnumValue: func(ctx context.Context, _ descpb.ID) error {
+			mu.Lock()
+			calls++
+			first := !added
+			added = true
+			mu.Unlock()
+			// Add another value from another connection the first time around,
+			// after the statement has read the descriptor of the type. The
+			// concurrent statement runs this knob too.
+			if !first {
+				return nil
+			}
+			_, err := sqlDB.Exec(`ALTER TYPE d.t ADD VALUE 'c'`)
+			return err
+		},
+	}
+	// Decrease the adopt loop interval so that the jobs finish quickly.
+	params.Knobs.JobsTestingKnobs = jobs.NewTestingKnobsWithShortIntervals()
+
+	var s serverutils.TestServerInterface
+	s, sqlDB, _ = serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+
+	_, err := sqlDB.Exec(`
+CREATE DATABASE d;
+CREATE TYPE d.t AS ENUM('a');
+`)
+	require.NoError(t, err)
+
+	// The write of the descriptor conflicts with the concurrent add, which
+	// restarts the implicit transaction. The retry resolves the type again and
+	// adds the value next to the concurrently added one.
+	_, err = sqlDB.Exec(`ALTER TYPE d.t ADD VALUE 'b' BEFORE 'a'`)
+	require.NoError(t, err)
+
+	mu.Lock()
+	require.GreaterOrEqual(t, calls, 3)
+	mu.Unlock()
+
+	var values string
+	require.NoError(t, sqlDB.QueryRow(`SELECT enum_range(NULL::d.t)::STRING`).Scan(&values))
+	require.Equal(t, "{b,a,c}", values)
+}
+
+// TestDropEnumValueInUseListsDescriptors ensures that the error for dropping
+// an enum value that is in use lists all the tables that use it.
+func TestDropEnumValueInUseListsDescriptors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	params, _ := createTestServerParams()
+	s, sqlDB, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+
+	_, err := sqlDB.Exec(`
+CREATE TYPE t AS ENUM ('a', 'b');
+CREATE TABLE uses_row (k INT PRIMARY KEY, v t);
+INSERT INTO uses_row VALUES (1, 'a');
+CREATE TABLE uses_default (k INT PRIMARY KEY, v t DEFAULT 'a');
+CREATE TABLE unused (k INT PRIMARY KEY, v t);
+`)
+	require.NoError(t, err)
+
+	_, err = sqlDB.Exec(`ALTER TYPE t DROP VALUE 'a'`)
+	var pqErr *pq.Error
+	require.True(t, errors.As(err, &pqErr), "%v", err)
+	require.Equal(t, pgcode.DependentObjectsStillExist.String(), string(pqErr.Code))
+	require.Equal(t, `enum value "a" is used by "uses_row", "uses_default"`, pqErr.Detail)
+
+	// Values that aren't used can be dropped.
+	_, err = sqlDB.Exec(`ALTER TYPE t DROP VALUE 'b'`)
+	require.NoError(t, err)
+}
+
+// TestAddEnumValueRespacesPackedValues ensures that adding an enum value
+// without room left between the physical representations of its neighbours
+// re-spaces the representations of all the values in follow-up jobs.
+func TestAddEnumValueRespacesPackedValues(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	params, _ := createTestServerParams()
+	// Decrease the adopt loop interval so that the follow-up jobs are adopted
+	// quickly.
+	params.Knobs.JobsTestingKnobs = jobs.NewTestingKnobsWithShortIntervals()
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	getReps := func() (labels, reps []string) {
+		rows := sqlDB.Query(t, `
+SELECT m->>'label', m->>'physical_rep'
+  FROM jsonb_array_elements(crdb_internal.export_type('t'::regtype)::JSONB->'members') AS m`)
+		defer rows.Close()
+		for rows.Next() {
+			var label, rep string
+			require.NoError(t, rows.Scan(&label, &rep))
+			labels = append(labels, label)
+			reps = append(reps, rep)
+		}
+		require.NoError(t, rows.Err())
+		return labels, reps
+	}
+
+	// The values are represented as 0x40 and 0x80, so each value added right
+	// before 'z' halves the room that is left, and the representation of the
+	// sixth one is the byte right before 0x80.
+	sqlDB.Exec(t, `CREATE TYPE t AS ENUM ('a', 'z')`)
+	sqlDB.Exec(t, `CREATE TABLE tbl (k INT PRIMARY KEY, v t)`)
+	sqlDB.Exec(t, `INSERT INTO tbl VALUES (1, 'a'), (2, 'z')`)
+	expected := []string{"a"}
+	for i := 1; i <= 6; i++ {
+		label := fmt.Sprintf("v%d", i)
+		sqlDB.Exec(t, fmt.Sprintf(`ALTER TYPE t ADD VALUE '%s' BEFORE 'z'`, label))
+		sqlDB.Exec(t, fmt.Sprintf(`INSERT INTO tbl VALUES (%d, '%s')`, i+2, label))
+		expected = append(expected, label)
+	}
+	_, reps := getReps()
+	require.Equal(t, "7f", reps[6])
+
+	// There is no room left between 0x7f and 0x80, so the next value gets a
+	// longer representation, and the values are re-spaced.
+	sqlDB.Exec(t, `ALTER TYPE t ADD VALUE 'v7' BEFORE 'z'`)
+	sqlDB.Exec(t, `INSERT INTO tbl VALUES (9, 'v7')`)
+	expected = append(expected, "v7", "z")
+
+	var expectedReps []string
+	for _, rep := range enum.GenerateNEvenlySpacedBytes(len(expected)) {
+		expectedReps = append(expectedReps, hex.EncodeToString(rep))
+	}
+	testutils.SucceedsSoon(t, func() error {
+		labels, reps := getReps()
+		if len(labels) != len(expected) {
+			return errors.Newf("values are still being moved: %v", labels)
+		}
+		require.Equal(t, expected, labels)
+		if fmt.Sprint(reps) != fmt.Sprint(expectedReps) {
+			return errors.Newf("expected representations %v, found %v", expectedReps, reps)
+		}
+		return nil
+	})
+	sqlDB.CheckQueryResultsRetry(t,
+		`SELECT count(*) FROM [SHOW JOBS] WHERE job_type = 'TYPEDESC SCHEMA CHANGE' AND status != 'succeeded'`,
+		[][]string{{"0"}},
+	)
+
+	// The values keep their order, and the rows still use them.
+	sqlDB.CheckQueryResults(t, `SELECT v FROM tbl ORDER BY v`,
+		[][]string{{"a"}, {"v1"}, {"v2"}, {"v3"}, {"v4"}, {"v5"}, {"v6"}, {"v7"}, {"z"}})
+	sqlDB.CheckQueryResults(t, `SELECT k FROM tbl WHERE v = 'v6'`, [][]string{{"8"}})
+}
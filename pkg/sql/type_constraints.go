@@ -0,0 +1,350 @@
+// Copyright 2024 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
+
+// typeConstraintValueName is the name that the expression of a constraint of
+// a type, added via ALTER TYPE ... ADD CONSTRAINT, refers to the value by.
+const typeConstraintValueName = tree.Name("value")
+
+// typeConstraintChecker enforces the constraints of the types of the columns
+// of a mutation on the values written to them. A nil *typeConstraintChecker
+// doesn't check anything, which is the common case.
+type typeConstraintChecker struct {
+	evalCtx *eval.Context
+	cols    []constrainedColumn
+	// value is the container that the constraints are evaluated against.
+	value typeConstraintValue
+}
+
+// constrainedColumn is a column whose type has constraints.
+type constrainedColumn struct {
+	// ord is the ordinal of the column in the rows being written.
+	ord int
+	// typeName is the name of the type, used in errors.
+	typeName    string
+	constraints []typeConstraint
+}
+
+// typeConstraint is a type-checked constraint of a type.
+type typeConstraint struct {
+	name string
+	expr tree.TypedExpr
+}
+
+// typeConstraintValue is the IndexedVarContainer that VALUE is bound to in the
+// expressions of the constraints of a type.
+type typeConstraintValue struct {
+	typ *types.T
+	val tree.Datum
+}
+
+var _ eval.IndexedVarContainer = &typeConstraintValue{}
+
+// IndexedVarEval implements the eval.IndexedVarContainer interface.
+func (v *typeConstraintValue) IndexedVarEval(
+	_ context.Context, _ int, _ tree.ExprEvaluator,
+) (tree.Datum, error) {
+	return v.val, nil
+}
+
+// IndexedVarResolvedType implements the tree.IndexedVarContainer interface.
+func (v *typeConstraintValue) IndexedVarResolvedType(int) *types.T {
+	return v.typ
+}
+
+// makeTypeConstraintChecker returns a checker for the constraints of the types
+// of the given columns, or nil if none of the types have constraints. The
+// values of arrays of a type are checked like values of the type itself.
+func (p *planner) makeTypeConstraintChecker(
+	ctx context.Context, cols []catalog.Column,
+) (*typeConstraintChecker, error) {
+	var c *typeConstraintChecker
+	for i, col := range cols {
+		typeDesc, typ, err := p.enumColumnType(ctx, col)
+		if err != nil {
+			return nil, err
+		}
+		if typeDesc == nil {
+			continue
+		}
+		// Constraints that are being validated are enforced too, so that no
+		// values violating them are written while the job validates them.
+		checks := typeDesc.TypeDesc().CheckConstraints
+		if len(checks) == 0 {
+			continue
+		}
+		if c == nil {
+			c = &typeConstraintChecker{evalCtx: p.EvalContext()}
+		}
+		constrained := constrainedColumn{ord: i, typeName: typeDesc.GetName()}
+		for _, ck := range checks {
+			expr, err := p.typeCheckTypeConstraint(ctx, typ, ck.Expr, &c.value)
+			if err != nil {
+				return nil, err
+			}
+			constrained.constraints = append(constrained.constraints, typeConstraint{name: ck.Name, expr: expr})
+		}
+		c.cols = append(c.cols, constrained)
+	}
+	return c, nil
+}
+
+// typeCheckTypeConstraint parses and type checks the serialized expression of
+// a constraint of the given type, with VALUE bound to the given container.
+func (p *planner) typeCheckTypeConstraint(
+	ctx context.Context, typ *types.T, exprStr string, value *typeConstraintValue,
+) (tree.TypedExpr, error) {
+	expr, err := parser.ParseExpr(exprStr)
+	if err != nil {
+		return nil, err
+	}
+	expr, err = tree.SimpleVisit(expr, func(e tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
+		v, ok := e.(tree.VarName)
+		if !ok {
+			return true, e, nil
+		}
+		if v, err = v.NormalizeVarName(); err != nil {
+			return false, nil, err
+		}
+		if c, ok := v.(*tree.ColumnItem); ok && c.ColumnName == typeConstraintValueName {
+			return false, tree.NewTypedOrdinalReference(0, typ), nil
+		}
+		return true, e, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	value.typ = typ
+	iVarHelper := tree.MakeIndexedVarHelper(value, 1 /* numVars */)
+	return p.analyzeExpr(ctx, expr, iVarHelper, types.Bool, true /* requireType */, "type constraint")
+}
+
+// validateTypeConstraints validates the values that are stored in columns of
+// the enum and of its array type against the constraints of the type that the
+// job is responsible for, and marks them as validated. It must only be called
+// once all nodes enforce the constraints on writes, so that no values that
+// violate them can be written after they have been read.
+func (t *typeSchemaChanger) validateTypeConstraints(ctx context.Context) error {
+	validate := func(ctx context.Context, txn descs.Txn) error {
+		typeDesc, err := txn.Descriptors().ByIDWithLeased(txn.KV()).WithoutNonPublic().Get().Type(ctx, t.typeID)
+		if err != nil {
+			return err
+		}
+		for _, ck := range typeDesc.TypeDesc().CheckConstraints {
+			if !t.isValidatingInCurrentJob(&ck) {
+				continue
+			}
+			if err := t.validateTypeConstraintData(ctx, txn, typeDesc, &ck); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	// The validation is done in a separate txn to the one that marks the
+	// constraints as validated, as it can take arbitrarily long.
+	if err := t.execCfg.InternalDB.DescsTxn(ctx, validate); err != nil {
+		return err
+	}
+	return t.execCfg.InternalDB.DescsTxn(ctx, func(ctx context.Context, txn descs.Txn) error {
+		typeDesc, err := txn.Descriptors().MutableByID(txn.KV()).Type(ctx, t.typeID)
+		if err != nil {
+			return err
+		}
+		var changed bool
+		for i := range typeDesc.CheckConstraints {
+			ck := &typeDesc.CheckConstraints[i]
+			if t.isValidatingInCurrentJob(ck) {
+				ck.Validity = descpb.ConstraintValidity_Validated
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+		return txn.Descriptors().WriteDesc(ctx, true /* kvTrace */, typeDesc, txn.KV())
+	})
+}
+
+// isValidatingInCurrentJob returns whether the given constraint is being
+// validated by the current job.
+func (t *typeSchemaChanger) isValidatingInCurrentJob(
+	ck *descpb.TypeDescriptor_CheckConstraint,
+) bool {
+	if ck.Validity != descpb.ConstraintValidity_Validating {
+		return false
+	}
+	for _, name := range t.validatingConstraints {
+		if name == ck.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateTypeConstraintData returns an error if a value that is stored in a
+// column of the enum or of its array type violates the given constraint.
+func (t *typeSchemaChanger) validateTypeConstraintData(
+	ctx context.Context,
+	txn descs.Txn,
+	typeDesc catalog.TypeDescriptor,
+	ck *descpb.TypeDescriptor_CheckConstraint,
+) error {
+	arrayTypeDesc, err := txn.Descriptors().ByIDWithLeased(txn.KV()).WithoutNonPublic().Get().Type(
+		ctx, typeDesc.GetArrayTypeID(),
+	)
+	if err != nil {
+		return err
+	}
+	// A table may use both the type and its array type.
+	ids := catalog.MakeDescriptorIDSet(typeDesc.TypeDesc().ReferencingDescriptorIDs...)
+	for i := 0; i < arrayTypeDesc.NumReferencingDescriptors(); i++ {
+		ids.Add(arrayTypeDesc.GetReferencingDescriptorID(i))
+	}
+	for _, id := range ids.Ordered() {
+		tableDesc, err := txn.Descriptors().ByIDWithLeased(txn.KV()).WithoutNonPublic().Get().Table(ctx, id)
+		if err != nil {
+			return err
+		}
+		if tableDesc.IsView() {
+			continue
+		}
+		for _, col := range tableDesc.PublicColumns() {
+			if !col.GetType().UserDefined() {
+				continue
+			}
+			colName := col.ColName()
+			var query string
+			switch typedesc.GetUserDefinedTypeDescID(col.GetType()) {
+			case typeDesc.GetID():
+				query = fmt.Sprintf(
+					"SELECT value::STRING FROM (SELECT t.%s AS value FROM [%d AS t]) AS v WHERE NOT (%s) LIMIT 1",
+					colName.String(), id, ck.Expr,
+				)
+			case arrayTypeDesc.GetID():
+				query = fmt.Sprintf(
+					"SELECT value::STRING FROM [%d AS t], unnest(t.%s) AS u(value) WHERE NOT (%s) LIMIT 1",
+					id, colName.String(), ck.Expr,
+				)
+			default:
+				continue
+			}
+			row, err := txn.QueryRowEx(
+				ctx, "validate-type-constraint", txn.KV(), sessiondata.NodeUserSessionDataOverride, query,
+			)
+			if err != nil {
+				return err
+			}
+			if row == nil {
+				continue
+			}
+			dbDesc, err := txn.Descriptors().ByIDWithLeased(txn.KV()).WithoutNonPublic().Get().Database(
+				ctx, tableDesc.GetParentID(),
+			)
+			if err != nil {
+				return err
+			}
+			scDesc, err := txn.Descriptors().ByIDWithLeased(txn.KV()).WithoutNonPublic().Get().Schema(
+				ctx, tableDesc.GetParentSchemaID(),
+			)
+			if err != nil {
+				return err
+			}
+			tn := tree.MakeTableNameWithSchema(
+				tree.Name(dbDesc.GetName()), tree.Name(scDesc.GetName()), tree.Name(tableDesc.GetName()),
+			)
+			return pgerror.Newf(pgcode.CheckViolation,
+				"validation of constraint %q of type %q failed: column %q of table %s has value %q",
+				ck.Name, typeDesc.GetName(), col.GetName(), tn.FQString(), tree.MustBeDString(row[0]))
+		}
+	}
+	return nil
+}
+
+// cleanupTypeConstraints drops the constraints that the job was validating if
+// the job fails.
+func (t *typeSchemaChanger) cleanupTypeConstraints(ctx context.Context) error {
+	if len(t.validatingConstraints) == 0 {
+		return nil
+	}
+	return t.execCfg.InternalDB.DescsTxn(ctx, func(ctx context.Context, txn descs.Txn) error {
+		typeDesc, err := txn.Descriptors().MutableByID(txn.KV()).Type(ctx, t.typeID)
+		if err != nil {
+			return err
+		}
+		checks := typeDesc.CheckConstraints[:0]
+		for _, ck := range typeDesc.CheckConstraints {
+			if !t.isValidatingInCurrentJob(&ck) {
+				checks = append(checks, ck)
+			}
+		}
+		if len(checks) == len(typeDesc.CheckConstraints) {
+			return nil
+		}
+		typeDesc.CheckConstraints = checks
+		return txn.Descriptors().WriteDesc(ctx, true /* kvTrace */, typeDesc, txn.KV())
+	})
+}
+
+// check returns an error if the given row, whose values are ordered like the
+// columns that the checker was made for, contains a value that violates a
+// constraint of its type. Like for CHECK constraints of tables, a constraint
+// that evaluates to NULL isn't violated.
+func (c *typeConstraintChecker) check(ctx context.Context, row tree.Datums) error {
+	if c == nil {
+		return nil
+	}
+	for i := range c.cols {
+		col := &c.cols[i]
+		if err := visitEnumValues(row[col.ord], func(d *tree.DEnum) error {
+			return c.checkValue(ctx, col, d)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *typeConstraintChecker) checkValue(
+	ctx context.Context, col *constrainedColumn, d *tree.DEnum,
+) error {
+	c.value.val = d
+	c.evalCtx.PushIVarContainer(&c.value)
+	defer c.evalCtx.PopIVarContainer()
+	for i := range col.constraints {
+		ck := &col.constraints[i]
+		res, err := eval.Expr(ctx, c.evalCtx, ck.expr)
+		if err != nil {
+			return err
+		}
+		if res != tree.DNull && !bool(tree.MustBeDBool(res)) {
+			return pgerror.Newf(pgcode.CheckViolation,
+				"value %q of type %s violates check constraint %q", d.LogicalRep, col.typeName, ck.name)
+		}
+	}
+	return nil
+}
@@ -236,6 +236,9 @@ type EnumMetadata struct {
 	// IsMemberReadOnly holds whether the enum member at index i is
 	// read only or not.
 	IsMemberReadOnly []bool
+	// Codes holds the code of the enum member at index i, or nil if it has
+	// none. Codes may be nil if no member has a code.
+	Codes []*int64
 	// TODO (rohany): For small enums, having a map would be slower
 	//  than just an array. Investigate at what point the tradeoff
 	//  should occur, if at all.
@@ -2733,18 +2736,25 @@ var EnumValueNotYetPublicError = errors.New("enum value is not yet public")
 func (t *T) EnumGetIdxOfLogical(logical string) (int, error) {
 	t.ensureHydratedEnum()
 	reps := t.TypeMeta.EnumData.LogicalRepresentations
+	readOnly := false
 	for i := range reps {
 		if reps[i] == logical {
 			// If this enum member is read only, we cannot construct it from the
 			// logical representation. This is to ensure that it will not be
 			// written until all nodes in the cluster are able to decode the
-			// physical representation.
+			// physical representation. A member that is being merged into
+			// another member with the same logical representation is read only
+			// too, so keep looking for a writable one.
 			if t.TypeMeta.EnumData.IsMemberReadOnly[i] {
-				return 0, errors.WithMessagef(EnumValueNotYetPublicError, "cannot use enum value %q", logical)
+				readOnly = true
+				continue
 			}
 			return i, nil
 		}
 	}
+	if readOnly {
+		return 0, errors.WithMessagef(EnumValueNotYetPublicError, "cannot use enum value %q", logical)
+	}
 	return 0, pgerror.Newf(
 		pgcode.InvalidTextRepresentation, "invalid input value for enum %s: %q", t, logical)
 }
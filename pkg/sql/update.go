@@ -123,6 +123,15 @@ type updateRun struct {
 	// regionLocalInfo handles erroring out the UPDATE when the
 	// enforce_home_region setting is on.
 	regionLocalInfo regionLocalInfoType
+
+	// enumRestrictions restricts the enum values that the user can write to
+	// the updated columns, as set via ALTER TYPE ... RESTRICT VALUES.
+	enumRestrictions *enumWriteRestrictions
+
+	// typeConstraints checks the values written to the updated columns
+	// against the constraints of their types, added via ALTER TYPE ... ADD
+	// CONSTRAINT.
+	typeConstraints *typeConstraintChecker
 }
 
 func (u *updateNode) startExec(params runParams) error {
@@ -135,6 +144,17 @@ func (u *updateNode) startExec(params runParams) error {
 			colinfo.ColTypeInfoFromResCols(u.columns),
 		)
 	}
+	var err error
+	if u.run.enumRestrictions, err = params.p.makeEnumWriteRestrictions(
+		params.ctx, u.run.tu.ru.UpdateCols,
+	); err != nil {
+		return err
+	}
+	if u.run.typeConstraints, err = params.p.makeTypeConstraintChecker(
+		params.ctx, u.run.tu.ru.UpdateCols,
+	); err != nil {
+		return err
+	}
 	return u.run.tu.init(params.ctx, params.p.txn, params.EvalContext(), &params.EvalContext().Settings.SV)
 }
 
@@ -286,6 +306,12 @@ func (u *updateNode) processSourceRow(params runParams, sourceVals tree.Datums)
 	if err := enforceLocalColumnConstraints(u.run.updateValues, u.run.tu.ru.UpdateCols); err != nil {
 		return err
 	}
+	if err := u.run.enumRestrictions.check(u.run.updateValues); err != nil {
+		return err
+	}
+	if err := u.run.typeConstraints.check(params.ctx, u.run.updateValues); err != nil {
+		return err
+	}
 
 	// Run the CHECK constraints, if any. CheckHelper will either evaluate the
 	// constraints itself, or else inspect boolean columns from the input that
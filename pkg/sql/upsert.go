@@ -59,6 +59,28 @@ func (n *upsertNode) startExec(params runParams) error {
 	// cache traceKV during execution, to avoid re-evaluating it for every row.
 	n.run.traceKV = params.p.ExtendedEvalContext().Tracing.KVTracingEnabled()
 
+	var err error
+	if n.run.tw.insertEnumRestrictions, err = params.p.makeEnumWriteRestrictions(
+		params.ctx, n.run.insertCols,
+	); err != nil {
+		return err
+	}
+	if n.run.tw.updateEnumRestrictions, err = params.p.makeEnumWriteRestrictions(
+		params.ctx, n.run.tw.updateCols,
+	); err != nil {
+		return err
+	}
+	if n.run.tw.insertTypeConstraints, err = params.p.makeTypeConstraintChecker(
+		params.ctx, n.run.insertCols,
+	); err != nil {
+		return err
+	}
+	if n.run.tw.updateTypeConstraints, err = params.p.makeTypeConstraintChecker(
+		params.ctx, n.run.tw.updateCols,
+	); err != nil {
+		return err
+	}
+
 	return n.run.tw.init(params.ctx, params.p.txn, params.EvalContext(), &params.EvalContext().Settings.SV)
 }
 